@@ -1,23 +1,43 @@
 package command
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/apache/arrow/go/v16/parquet"
 	"github.com/planetlabs/gpq/internal/storage"
 )
 
 var CLI struct {
+	Quiet   bool          `help:"Suppress warnings and other diagnostic output on stderr." xor:"verbosity"`
+	Verbose bool          `help:"Print extra diagnostic output on stderr." xor:"verbosity"`
+	Timeout time.Duration `help:"Stop the command with an error if it runs longer than this (e.g. 30s or 5m).  There is no timeout by default."`
+
 	Convert  ConvertCmd  `cmd:"" help:"Convert data from one format to another."`
 	Validate ValidateCmd `cmd:"" help:"Validate a GeoParquet file."`
 	Describe DescribeCmd `cmd:"" help:"Describe a GeoParquet file."`
+	Get      GetCmd      `cmd:"" help:"Print a single feature from a GeoParquet file."`
 	Version  VersionCmd  `cmd:"" help:"Print the version of this program."`
 }
 
+var commandContext = context.Background()
+
+// SetContext sets the context used by all commands.  Reading input and
+// scanning data stop with an error when the context is done.
+func SetContext(ctx context.Context) {
+	commandContext = ctx
+}
+
 type CommandError struct {
 	err error
 }
@@ -34,18 +54,129 @@ func (e *CommandError) Unwrap() error {
 	return e.err
 }
 
+// readerFromInput opens a file or URL for reading.  An empty input (or "-")
+// is read from stdin.
 func readerFromInput(input string) (storage.ReaderAtSeeker, error) {
-	if input == "" {
-		data, err := io.ReadAll(os.Stdin)
-		if err != nil {
-			return nil, fmt.Errorf("trouble reading from stdin: %w", err)
-		}
-		return bytes.NewReader(data), nil
+	if input == "" || input == stdinInput {
+		return readerFromStdin()
 	}
 
 	if u, err := url.Parse(input); err == nil && u.Scheme != "" {
-		return storage.NewReader(context.Background(), input)
+		return storage.NewReader(commandContext, input)
 	}
 
 	return os.Open(input)
 }
+
+// streamFromInput opens a file or URL for sequential reading.  An empty input
+// (or "-") is read from stdin directly, since it does not need to be copied to
+// a seekable temporary file.  Stdin is not closed with the returned reader.
+func streamFromInput(input string) (io.Reader, error) {
+	if input == "" || input == stdinInput {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return readerFromInput(input)
+}
+
+// stdinInput is the input name used to read from stdin explicitly.
+const stdinInput = "-"
+
+// readerFromStdin returns a seekable reader for stdin.  Parquet readers need to
+// seek to the footer, so stdin that is not a regular file (e.g. a pipe) is
+// copied to a temporary file instead of being read into memory.  The temporary
+// file is removed when the reader is closed.
+func readerFromStdin() (storage.ReaderAtSeeker, error) {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("trouble reading from stdin: %w", err)
+	}
+	if info.Mode().IsRegular() {
+		return io.NewSectionReader(os.Stdin, 0, info.Size()), nil
+	}
+
+	temp, err := os.CreateTemp("", "gpq-stdin-*")
+	if err != nil {
+		return nil, fmt.Errorf("trouble creating a temporary file for stdin: %w", err)
+	}
+	spilled := &tempFileReader{File: temp}
+	if _, err := io.Copy(temp, os.Stdin); err != nil {
+		_ = spilled.Close()
+		return nil, fmt.Errorf("trouble reading from stdin: %w", err)
+	}
+	if _, err := temp.Seek(0, io.SeekStart); err != nil {
+		_ = spilled.Close()
+		return nil, fmt.Errorf("trouble reading from stdin: %w", err)
+	}
+	logger.Debug("copied stdin to %s", temp.Name())
+	return spilled, nil
+}
+
+// tempFileReader is a temporary file that is removed when it is closed.
+type tempFileReader struct {
+	*os.File
+}
+
+func (r *tempFileReader) Close() error {
+	closeErr := r.File.Close()
+	if err := os.Remove(r.File.Name()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if errors.Is(closeErr, os.ErrClosed) {
+		return nil
+	}
+	return closeErr
+}
+
+func isDirectory(input string) bool {
+	if input == "" {
+		return false
+	}
+	info, err := os.Stat(input)
+	return err == nil && info.IsDir()
+}
+
+// readersFromDirectory opens the Parquet part files in a directory (sorted by
+// name) so they can be read as a single dataset.  Hidden files and names
+// starting with an underscore (e.g. _SUCCESS or _metadata) are ignored.  The
+// returned function closes all of the files.
+func readersFromDirectory(dir string) ([]parquet.ReaderAtSeeker, func(), error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+			continue
+		}
+		ext := filepath.Ext(name)
+		if !slices.Contains(parquetSuffixes, ext) && !slices.Contains(geoParquetSuffixes, ext) {
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, nil, fmt.Errorf("no Parquet files found in %q", dir)
+	}
+	sort.Strings(names)
+
+	files := []*os.File{}
+	closeFiles := func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}
+	readers := []parquet.ReaderAtSeeker{}
+	for _, name := range names {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			closeFiles()
+			return nil, nil, fmt.Errorf("trouble opening %q: %w", name, err)
+		}
+		files = append(files, f)
+		readers = append(readers, f)
+	}
+	return readers, closeFiles, nil
+}
@@ -15,28 +15,75 @@
 package command
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"net/url"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
 
+	"github.com/apache/arrow/go/v16/parquet"
+	"github.com/apache/arrow/go/v16/parquet/file"
+	"github.com/planetlabs/gpq/internal/geo"
 	"github.com/planetlabs/gpq/internal/geojson"
 	"github.com/planetlabs/gpq/internal/geoparquet"
+	"github.com/planetlabs/gpq/internal/pqutil"
 )
 
 type ConvertCmd struct {
-	Input              string `arg:"" optional:"" name:"input" help:"Input file path or URL.  If not provided, input is read from stdin."`
-	From               string `help:"Input file format.  Possible values: ${enum}." enum:"auto, geojson, geoparquet, parquet" default:"auto"`
-	Output             string `arg:"" optional:"" name:"output" help:"Output file.  If not provided, output is written to stdout." type:"path"`
-	To                 string `help:"Output file format.  Possible values: ${enum}." enum:"auto, geojson, geoparquet" default:"auto"`
-	Min                int    `help:"Minimum number of features to consider when building a schema." default:"10"`
-	Max                int    `help:"Maximum number of features to consider when building a schema." default:"100"`
-	InputPrimaryColumn string `help:"Primary geometry column name when reading Parquet withtout metadata." default:"geometry"`
-	Compression        string `help:"Parquet compression to use.  Possible values: ${enum}." enum:"uncompressed, snappy, gzip, brotli, zstd" default:"zstd"`
-	RowGroupLength     int    `help:"Maximum number of rows per group when writing Parquet."`
+	Input              string            `arg:"" optional:"" name:"input" help:"Input file path or URL (or a directory of GeoParquet part files).  If not provided, input is read from stdin."`
+	From               string            `help:"Input file format.  Possible values: ${enum}." enum:"auto, geojson, geoparquet, parquet, arrow" default:"auto"`
+	Output             string            `arg:"" optional:"" name:"output" help:"Output file.  If not provided, output is written to stdout." type:"path"`
+	To                 string            `help:"Output file format.  Possible values: ${enum}." enum:"auto, geojson, geoparquet, parquet" default:"auto"`
+	Overwrite          bool              `help:"Replace the output file (and metadata sidecar) if it already exists.  By default, existing files are not overwritten."`
+	Min                int               `help:"Minimum number of features to consider when building a schema." default:"10"`
+	Max                int               `help:"Maximum number of features to consider when building a schema." default:"100"`
+	SampleBytes        int               `help:"Maximum number of bytes of GeoJSON to read when building a schema.  Sampling stops when either the --min or this limit is reached."`
+	InputPrimaryColumn string            `help:"Primary geometry column name when reading Parquet withtout metadata.  Use a dotted path (e.g. feature.geom) for a column inside a struct." default:"geometry"`
+	Compression        string            `help:"Parquet compression to use.  Possible values: ${enum}." enum:"uncompressed, snappy, gzip, brotli, zstd" default:"zstd"`
+	CompressCol        map[string]string `name:"compress-col" help:"Compression to use for a column when writing Parquet, instead of the --compression value (e.g. --compress-col geometry=zstd --compress-col name=gzip)." mapsep:","`
+	RowGroupLength     int               `help:"Maximum number of rows per group when writing Parquet."`
+	MaxRowGroupBytes   int               `help:"Target size in bytes for row groups when converting GeoJSON without a --row-group-length (the length is estimated from the features used to build the schema)."`
+	CollectionBbox     bool              `help:"Include a top-level bbox for the feature collection when writing GeoJSON."`
+	Flatten            bool              `help:"Write struct columns as properties with dotted names (e.g. address.city) when writing GeoJSON."`
+	Pretty             bool              `help:"Write indented JSON when writing GeoJSON (the default is compact)."`
+	IdColumn           string            `help:"Name of a string or number column to write as the feature id (instead of a property) when writing GeoJSON."`
+	WKTProperty        string            `help:"Name of a property to add with the primary geometry as a WKT string when writing GeoJSON." name:"geometry-as-wkt-property"`
+	RFC7946            bool              `help:"Rewind polygon rings to follow the right-hand rule from RFC 7946 (exterior rings counterclockwise) when writing GeoJSON.  By default, geometries are written as they are stored." name:"rfc7946"`
+	FlattenCollections bool              `help:"Write a feature for each member of a GeometryCollection (with the same properties) when converting GeoJSON to GeoParquet." name:"flatten-geometry-collection"`
+	JSONProperties     bool              `help:"Store object properties as JSON-encoded string columns when converting GeoJSON (instead of struct columns)."`
+	KeepCollectionName bool              `help:"Store the name of a GeoJSON FeatureCollection in the file metadata when converting GeoJSON.  The name is written back when converting to GeoJSON."`
+	IdFromIndex        bool              `name:"geojson-feature-id-from-index" help:"Write the zero-based index of each feature to an integer id column when converting GeoJSON (use --id-column id to write it back as the feature id)."`
+	ForeignMembers     bool              `help:"Store the foreign members of GeoJSON features (members other than type, id, geometry, properties, and bbox) in a foreign_members JSON column.  These are written back when converting to GeoJSON.  Reading them is slower."`
+	Rename             map[string]string `help:"Comma-separated list of old=new column names to rename when converting Parquet or GeoParquet to GeoParquet (e.g. pop_est=population)." mapsep:","`
+	Edges              string            `help:"Edges to declare for the geometry columns when writing GeoParquet.  Possible values: ${enum}.  By default, the value from the input is kept." enum:"keep, planar, spherical" default:"keep"`
+	GeoParquetVersion  string            `help:"GeoParquet version to declare in the metadata when writing GeoParquet (1.0.0-beta.1, 1.0.0, or 1.1.0).  By default, the version from the input is kept."`
+	Dictionary         bool              `help:"Use dictionary encoding when writing Parquet (use --dictionary=false or --no-dictionary to turn it off)." default:"true" negatable:""`
+	DataPageSize       int               `help:"Target size in bytes for data pages when writing Parquet."`
+	PartitionBy        float64           `help:"Write one row group per cell of a grid with this cell size (in coordinate units) when converting GeoJSON to GeoParquet.  Features are assigned to the cell containing the center of their bounds."`
+	GeometryFirst      bool              `help:"Write the geometry as the first column when converting GeoJSON (by default, columns are sorted by name).  Parquet input keeps its column order."`
+	Force2D            bool              `name:"force-2d" help:"Allow GeoJSON input with a mix of 2D and 3D coordinates by dropping the Z values (mixed dimensions are an error by default)."`
+	GeometryTypes      []string          `help:"Comma-separated list of geometry types to declare in the primary column metadata when writing GeoParquet (instead of the types found in the data)."`
+	MetadataSidecar    string            `help:"Also write the geo metadata, schema, and row counts of the GeoParquet output to this JSON file." type:"path"`
+	GeometryPrecision  *int              `help:"Round coordinates to this number of decimal places (0 to 10, where 0 rounds to integers) when converting GeoJSON to GeoParquet or GeoParquet to GeoJSON.  Bounds are computed from the rounded coordinates."`
+	BboxPrecision      *int              `help:"Round the bbox values in the geo metadata to this number of decimal places (0 to 10) when writing GeoParquet.  Bounds are rounded outward so they still contain all geometries.  By default, full precision is used."`
+	AddCentroid        string            `help:"Add a geometry column with this name containing the centroid of the primary geometry when converting Parquet or GeoParquet to GeoParquet (e.g. --add-centroid=centroid)."`
+	DropGeometry       bool              `help:"Remove the primary geometry column and omit the geo metadata when converting Parquet or GeoParquet.  The output is plain Parquet (attributes only), not GeoParquet."`
+	NoMetadata         bool              `help:"Write plain Parquet without the geo metadata when converting GeoJSON.  The geometry is still written as WKB, but the output is not valid GeoParquet."`
+	DetectGeometry     bool              `help:"Use the only column that looks like WKB or WKT geometries as the primary column when converting Parquet without geo metadata.  It is an error if no column or more than one column looks like geometries."`
+	RecomputeMetadata  bool              `help:"Recompute the bbox and geometry types in the geo metadata from the WKB geometries when converting Parquet or GeoParquet to GeoParquet.  Column values are written as they are."`
+	ReadAhead          bool              `help:"Read the next row group while writing the current one when converting Parquet or GeoParquet to GeoParquet.  This can be faster for large files but uses more memory."`
+	CopyColumnChunks   bool              `help:"Copy the column data from the input without decoding it when converting Parquet or GeoParquet to GeoParquet with only metadata changes (e.g. --geometry-types, --edges, or --geoparquet-version).  The input compression is kept (--compression is ignored).  Conversions that change the data are written as usual."`
+	Split              int               `help:"Write GeoParquet files with at most this many features each to the output directory (part-00001.parquet, part-00002.parquet, etc.) when converting GeoJSON.  Each file has its own bbox and geometry types in the metadata."`
 }
 
+// edgesKeep is the --edges value that keeps the edges from the input.
+const edgesKeep = "keep"
+
 type FormatType string
 
 const (
@@ -44,6 +91,7 @@ const (
 	GeoParquetType FormatType = "geoparquet"
 	ParquetType    FormatType = "parquet"
 	GeoJSONType    FormatType = "geojson"
+	ArrowType      FormatType = "arrow"
 	UnknownType    FormatType = "unknown"
 )
 
@@ -52,6 +100,7 @@ var validTypes = map[FormatType]bool{
 	GeoParquetType: true,
 	ParquetType:    true,
 	GeoJSONType:    true,
+	ArrowType:      true,
 }
 
 func parseFormatType(format string) FormatType {
@@ -81,6 +130,12 @@ var geoJsonSuffixes = []string{
 	".geojsonl",
 }
 
+var arrowSuffixes = []string{
+	".arrow",
+	".feather",
+	".ipc",
+}
+
 func getFormatType(resource string) FormatType {
 	if u, err := url.Parse(resource); err == nil {
 		resource = u.Path
@@ -96,6 +151,9 @@ func getFormatType(resource string) FormatType {
 	if slices.Contains(geoJsonSuffixes, ext) {
 		return GeoJSONType
 	}
+	if slices.Contains(arrowSuffixes, ext) {
+		return ArrowType
+	}
 
 	return UnknownType
 }
@@ -108,9 +166,66 @@ func hasStdin() bool {
 	return stats.Size() > 0
 }
 
+// checkNoClobber returns an error if an output file already exists.  Stdout
+// (an empty path) is always allowed.
+func checkNoClobber(outputSource string) error {
+	if outputSource == "" {
+		return nil
+	}
+	if _, err := os.Stat(outputSource); err == nil {
+		return NewCommandError("%q already exists, use --overwrite to replace it", outputSource)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return NewCommandError("trouble checking for an existing output file %q: %w", outputSource, err)
+	}
+	return nil
+}
+
+// createOutput creates an output file.  Unless overwrite is true, it is an
+// error if the file already exists.
+func createOutput(outputSource string, overwrite bool) (*os.File, error) {
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flag |= os.O_EXCL
+	}
+	output, createErr := os.OpenFile(outputSource, flag, 0o666)
+	if createErr != nil {
+		if errors.Is(createErr, fs.ErrExist) {
+			return nil, NewCommandError("%q already exists, use --overwrite to replace it", outputSource)
+		}
+		return nil, NewCommandError("failed to open %q for writing: %w", outputSource, createErr)
+	}
+	return output, nil
+}
+
+// writerFromOutput creates the output file or returns stdout if no output is
+// provided.  The returned function closes the output file and removes it if
+// the conversion failed, so that a partial file does not block a retry.
+func writerFromOutput(outputSource string, overwrite bool) (*os.File, func(failed bool), error) {
+	if outputSource == "" {
+		return os.Stdout, func(bool) {}, nil
+	}
+	output, err := createOutput(outputSource, overwrite)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeOutput := func(failed bool) {
+		_ = output.Close()
+		if failed {
+			_ = os.Remove(outputSource)
+		}
+	}
+	return output, closeOutput, nil
+}
+
 func (c *ConvertCmd) Run() error {
 	inputSource := c.Input
 	outputSource := c.Output
+	if c.Edges == edgesKeep {
+		c.Edges = ""
+	}
+	if inputSource == stdinInput {
+		inputSource = ""
+	}
 
 	if outputSource == "" && hasStdin() {
 		outputSource = inputSource
@@ -122,13 +237,24 @@ func (c *ConvertCmd) Run() error {
 		if outputSource == "" {
 			return NewCommandError("when writing to stdout, the --to option must be provided to determine the output format")
 		}
-		outputFormat = getFormatType(outputSource)
+		if c.Split != 0 {
+			// split output is a directory of GeoParquet files
+			outputFormat = GeoParquetType
+		} else {
+			outputFormat = getFormatType(outputSource)
+		}
 	}
 	if outputFormat == UnknownType {
 		return NewCommandError("could not determine output format for %s", outputSource)
 	}
 
 	inputFormat := parseFormatType(c.From)
+	if isDirectory(inputSource) {
+		if inputFormat != AutoType && inputFormat != GeoParquetType && inputFormat != ParquetType {
+			return NewCommandError("a directory can only be read as a GeoParquet dataset")
+		}
+		inputFormat = GeoParquetType
+	}
 	if inputFormat == AutoType {
 		if inputSource == "" {
 			return NewCommandError("when reading from stdin, the --from option must be provided to determine the input format")
@@ -139,54 +265,620 @@ func (c *ConvertCmd) Run() error {
 		return NewCommandError("could not determine input format for %s", inputSource)
 	}
 
-	input, inputErr := readerFromInput(inputSource)
+	if outputFormat == ArrowType {
+		return NewCommandError("writing Arrow IPC output is not supported")
+	}
+
+	if len(c.Rename) > 0 && (outputFormat == GeoJSONType || (inputFormat != ParquetType && inputFormat != GeoParquetType)) {
+		return NewCommandError("the --rename option is only supported when converting Parquet or GeoParquet to GeoParquet")
+	}
+
+	if err := geoparquet.ValidateGeometryTypes(c.GeometryTypes); err != nil {
+		return NewCommandError("invalid --geometry-types: %w", err)
+	}
+
+	if err := geoparquet.ValidateVersion(c.GeoParquetVersion); err != nil {
+		return NewCommandError("invalid --geoparquet-version: %w", err)
+	}
+
+	if _, err := pqutil.GetColumnCompression(c.CompressCol); err != nil {
+		return NewCommandError("invalid --compress-col: %w", err)
+	}
+
+	if err := geoparquet.ValidateDataPageSize(c.DataPageSize); err != nil {
+		return NewCommandError("invalid --data-page-size: %w", err)
+	}
+
+	if c.SampleBytes != 0 && inputFormat != GeoJSONType {
+		return NewCommandError("the --sample-bytes option is only supported when converting GeoJSON to GeoParquet")
+	}
+
+	if c.SampleBytes < 0 {
+		return NewCommandError("invalid --sample-bytes: must not be negative, got %d", c.SampleBytes)
+	}
+
+	if c.MaxRowGroupBytes < 0 {
+		return NewCommandError("invalid --max-row-group-bytes: must not be negative, got %d", c.MaxRowGroupBytes)
+	}
+
+	if c.KeepCollectionName && inputFormat != GeoJSONType {
+		return NewCommandError("the --keep-collection-name option is only supported when converting GeoJSON to GeoParquet")
+	}
+
+	if c.IdFromIndex && inputFormat != GeoJSONType {
+		return NewCommandError("the --geojson-feature-id-from-index option is only supported when converting GeoJSON to GeoParquet")
+	}
+
+	if c.ForeignMembers && inputFormat != GeoJSONType {
+		return NewCommandError("the --foreign-members option is only supported when converting GeoJSON to GeoParquet")
+	}
+
+	if c.GeometryFirst && inputFormat != GeoJSONType {
+		return NewCommandError("the --geometry-first option is only supported when converting GeoJSON to GeoParquet")
+	}
+
+	if c.FlattenCollections && inputFormat != GeoJSONType {
+		return NewCommandError("the --flatten-geometry-collection option is only supported when converting GeoJSON to GeoParquet")
+	}
+
+	if c.PartitionBy != 0 && inputFormat != GeoJSONType {
+		return NewCommandError("the --partition-by option is only supported when converting GeoJSON to GeoParquet")
+	}
+
+	if err := geoparquet.ValidatePartitionCellSize(c.PartitionBy); err != nil {
+		return NewCommandError("invalid --partition-by: %w", err)
+	}
+
+	if c.WKTProperty != "" && outputFormat != GeoJSONType {
+		return NewCommandError("the --geometry-as-wkt-property option is only supported when writing GeoJSON")
+	}
+
+	if c.RFC7946 && outputFormat != GeoJSONType {
+		return NewCommandError("the --rfc7946 option is only supported when writing GeoJSON")
+	}
+
+	if c.GeometryPrecision != nil && inputFormat != GeoJSONType && outputFormat != GeoJSONType {
+		return NewCommandError("the --geometry-precision option is only supported when converting GeoJSON to GeoParquet or GeoParquet to GeoJSON")
+	}
+
+	if err := geo.ValidatePrecision(c.GeometryPrecision); err != nil {
+		return NewCommandError("invalid --geometry-precision: %w", err)
+	}
+
+	if c.BboxPrecision != nil && outputFormat != ParquetType && outputFormat != GeoParquetType {
+		return NewCommandError("the --bbox-precision option is only supported when writing GeoParquet")
+	}
+
+	if err := geo.ValidatePrecision(c.BboxPrecision); err != nil {
+		return NewCommandError("invalid --bbox-precision: %w", err)
+	}
+
+	if c.AddCentroid != "" && (outputFormat == GeoJSONType || (inputFormat != ParquetType && inputFormat != GeoParquetType) || isDirectory(inputSource)) {
+		return NewCommandError("the --add-centroid option is only supported when converting Parquet or GeoParquet to GeoParquet")
+	}
+
+	if c.ReadAhead && (outputFormat == GeoJSONType || (inputFormat != ParquetType && inputFormat != GeoParquetType) || isDirectory(inputSource)) {
+		return NewCommandError("the --read-ahead option is only supported when converting Parquet or GeoParquet to GeoParquet")
+	}
+
+	if c.CopyColumnChunks && (outputFormat == GeoJSONType || (inputFormat != ParquetType && inputFormat != GeoParquetType) || isDirectory(inputSource)) {
+		return NewCommandError("the --copy-column-chunks option is only supported when converting Parquet or GeoParquet to GeoParquet")
+	}
+
+	if c.NoMetadata {
+		if inputFormat != GeoJSONType || (outputFormat != ParquetType && outputFormat != GeoParquetType) {
+			return NewCommandError("the --no-metadata option is only supported when converting GeoJSON to Parquet")
+		}
+		if c.GeometryTypes != nil || c.Edges != "" || c.GeoParquetVersion != "" || c.BboxPrecision != nil || c.MetadataSidecar != "" {
+			return NewCommandError("the --no-metadata option cannot be used with options for the geo metadata (--geometry-types, --edges, --geoparquet-version, --bbox-precision, or --metadata-sidecar)")
+		}
+	}
+
+	if c.DetectGeometry {
+		if outputFormat == GeoJSONType || (inputFormat != ParquetType && inputFormat != GeoParquetType) || isDirectory(inputSource) {
+			return NewCommandError("the --detect-geometry option is only supported when converting Parquet to GeoParquet")
+		}
+		if c.InputPrimaryColumn != "" && c.InputPrimaryColumn != geoparquet.DefaultGeometryColumn {
+			return NewCommandError("the --detect-geometry option cannot be used with --input-primary-column")
+		}
+	}
+
+	if c.RecomputeMetadata && (outputFormat == GeoJSONType || (inputFormat != ParquetType && inputFormat != GeoParquetType) || isDirectory(inputSource)) {
+		return NewCommandError("the --recompute-metadata option is only supported when converting Parquet or GeoParquet to GeoParquet")
+	}
+
+	if c.DropGeometry {
+		if outputFormat == GeoJSONType || (inputFormat != ParquetType && inputFormat != GeoParquetType) || isDirectory(inputSource) {
+			return NewCommandError("the --drop-geometry option is only supported when converting Parquet or GeoParquet to Parquet")
+		}
+		if c.AddCentroid != "" || c.GeometryTypes != nil || c.Edges != "" || c.GeoParquetVersion != "" || c.BboxPrecision != nil || c.RecomputeMetadata {
+			return NewCommandError("the --drop-geometry option cannot be used with options for the geo metadata (--add-centroid, --geometry-types, --edges, --geoparquet-version, --bbox-precision, or --recompute-metadata)")
+		}
+	}
+
+	if c.Split != 0 {
+		if inputFormat != GeoJSONType || (outputFormat != ParquetType && outputFormat != GeoParquetType) {
+			return NewCommandError("the --split option is only supported when converting GeoJSON to GeoParquet")
+		}
+		if c.Split < 0 {
+			return NewCommandError("invalid --split: must not be negative, got %d", c.Split)
+		}
+		if outputSource == "" {
+			return NewCommandError("the --split option requires an output directory")
+		}
+		if c.MetadataSidecar != "" {
+			return NewCommandError("the --split option cannot be used with --metadata-sidecar")
+		}
+	}
+
+	if c.MetadataSidecar != "" {
+		if outputFormat != ParquetType && outputFormat != GeoParquetType {
+			return NewCommandError("the --metadata-sidecar option is only supported when writing GeoParquet")
+		}
+		if outputSource == "" {
+			return NewCommandError("the --metadata-sidecar option requires an output file")
+		}
+	}
+
+	// the output file is created with O_EXCL, but the sidecar is only written
+	// after the conversion
+	if !c.Overwrite && c.MetadataSidecar != "" {
+		if err := checkNoClobber(c.MetadataSidecar); err != nil {
+			return err
+		}
+	}
+
+	logger.Debug("converting %s from %s to %s", inputFormat, describeSource(inputSource, "stdin"), outputFormat)
+	if err := c.convert(inputSource, outputSource, inputFormat, outputFormat); err != nil {
+		return err
+	}
+
+	logger.Debug("wrote %s", describeSource(outputSource, "stdout"))
+
+	if c.MetadataSidecar != "" {
+		logger.Debug("writing the metadata sidecar to %s", c.MetadataSidecar)
+		return writeMetadataSidecar(outputSource, c.MetadataSidecar, c.Overwrite)
+	}
+	return nil
+}
+
+// describeSource returns the source for a log message.
+func describeSource(source string, standard string) string {
+	if source == "" {
+		return standard
+	}
+	return source
+}
+
+// geojsonOptions returns the options for converting GeoJSON to GeoParquet.
+func (c *ConvertCmd) geojsonOptions() *geojson.ConvertOptions {
+	return &geojson.ConvertOptions{
+		MinFeatures:        c.Min,
+		MaxFeatures:        c.Max,
+		MaxSampleBytes:     c.SampleBytes,
+		FlattenCollections: c.FlattenCollections,
+		Compression:        c.Compression,
+		ColumnCompression:  c.CompressCol,
+		RowGroupLength:     c.RowGroupLength,
+		MaxRowGroupBytes:   c.MaxRowGroupBytes,
+		GeometryTypes:      c.GeometryTypes,
+		JSONProperties:     c.JSONProperties,
+		ForeignMembers:     c.ForeignMembers,
+		IdFromIndex:        c.IdFromIndex,
+		KeepCollectionName: c.KeepCollectionName,
+		Edges:              c.Edges,
+		Version:            c.GeoParquetVersion,
+		DisableDictionary:  !c.Dictionary,
+		DataPageSize:       c.DataPageSize,
+		BoundsPrecision:    c.BboxPrecision,
+		PartitionCellSize:  c.PartitionBy,
+		Force2D:            c.Force2D,
+		GeometryFirst:      c.GeometryFirst,
+		GeometryPrecision:  c.GeometryPrecision,
+		NoMetadata:         c.NoMetadata,
+		Context:            commandContext,
+	}
+}
+
+// splitPartName returns the file name for a part of split output.
+func splitPartName(part int) string {
+	return fmt.Sprintf("part-%05d.parquet", part)
+}
+
+// splitPartPattern matches the names of split output parts.
+const splitPartPattern = "part-*.parquet"
+
+// existingSplitParts returns the parts of previous split output in the output
+// directory.  Unless overwrite is true, it is an error if there are any parts.
+func existingSplitParts(outputDir string, overwrite bool) ([]string, error) {
+	existing, err := filepath.Glob(filepath.Join(outputDir, splitPartPattern))
+	if err != nil {
+		return nil, NewCommandError("trouble checking for existing output in %q: %w", outputDir, err)
+	}
+	if len(existing) > 0 && !overwrite {
+		return nil, NewCommandError("%q already exists, use --overwrite to replace it", existing[0])
+	}
+	return existing, nil
+}
+
+// replaceSplitParts moves the new parts into the output directory and removes
+// any previous parts that were not replaced, so that stale parts are not mixed
+// with new ones.
+func replaceSplitParts(outputDir string, parts []string, existing []string) error {
+	replaced := map[string]bool{}
+	for _, part := range parts {
+		path := filepath.Join(outputDir, filepath.Base(part))
+		if err := os.Rename(part, path); err != nil {
+			return NewCommandError("failed to move %q to %q: %w", part, path, err)
+		}
+		replaced[path] = true
+	}
+	for _, path := range existing {
+		if replaced[path] {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return NewCommandError("failed to remove %q: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// lazyWriter creates its output on the first write, so that no file is left
+// for input without features.
+type lazyWriter struct {
+	create func() (io.Writer, error)
+	writer io.Writer
+}
+
+func (w *lazyWriter) Write(data []byte) (int, error) {
+	if w.writer == nil {
+		writer, err := w.create()
+		if err != nil {
+			return 0, err
+		}
+		w.writer = writer
+	}
+	return w.writer.Write(data)
+}
+
+// convertSplit converts GeoJSON to GeoParquet files in the output directory
+// with up to c.Split features each.  Parts are written to a temporary
+// directory and only replace any previous parts once the conversion succeeds,
+// so a failed conversion leaves the previous output in place.
+func (c *ConvertCmd) convertSplit(inputSource string, outputDir string) (err error) {
+	input, inputErr := streamFromInput(inputSource)
 	if inputErr != nil {
 		return NewCommandError("trouble getting a reader from %q: %w", c.Input, inputErr)
 	}
+	if closer, ok := input.(io.Closer); ok {
+		defer closer.Close()
+	}
 
-	var output *os.File
-	if outputSource == "" {
-		output = os.Stdout
-	} else {
-		o, createErr := os.Create(outputSource)
-		if createErr != nil {
-			return NewCommandError("failed to open %q for writing: %w", outputSource, createErr)
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return NewCommandError("failed to create the %q directory: %w", outputDir, err)
+	}
+	existing, existingErr := existingSplitParts(outputDir, c.Overwrite)
+	if existingErr != nil {
+		return existingErr
+	}
+
+	tempDir, tempErr := os.MkdirTemp(outputDir, ".parts-")
+	if tempErr != nil {
+		return NewCommandError("failed to create a temporary directory in %q: %w", outputDir, tempErr)
+	}
+	defer os.RemoveAll(tempDir)
+
+	parts := []*os.File{}
+	defer func() {
+		for _, part := range parts {
+			_ = part.Close()
 		}
-		defer o.Close()
-		output = o
+	}()
+	createPart := func(part int) (io.Writer, error) {
+		logger.Debug("writing part %d to %s", part, outputDir)
+		output, err := createOutput(filepath.Join(tempDir, splitPartName(part)), false)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, output)
+		return output, nil
+	}
+
+	output := &lazyWriter{create: func() (io.Writer, error) { return createPart(1) }}
+
+	convertOptions := c.geojsonOptions()
+	convertOptions.SplitFeatures = c.Split
+	convertOptions.NextOutput = createPart
+	if err := geojson.ToParquet(input, output, convertOptions); err != nil {
+		return geojsonError(err)
+	}
+
+	names := make([]string, len(parts))
+	for i, part := range parts {
+		if err := part.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+			return NewCommandError("failed to close %q: %w", part.Name(), err)
+		}
+		names[i] = part.Name()
+	}
+	return replaceSplitParts(outputDir, names, existing)
+}
+
+// geojsonError returns a command error for a failed GeoJSON conversion with a
+// hint about the flag that allows mixed coordinate dimensions.
+func geojsonError(err error) error {
+	if errors.Is(err, geojson.ErrMixedDimensions) {
+		return NewCommandError("%w, use --force-2d to drop the Z values", err)
 	}
+	return NewCommandError("%w", err)
+}
 
+func (c *ConvertCmd) convert(inputSource string, outputSource string, inputFormat FormatType, outputFormat FormatType) (err error) {
+	if isDirectory(inputSource) {
+		return c.convertDataset(inputSource, outputSource, outputFormat)
+	}
+	if c.Split > 0 {
+		return c.convertSplit(inputSource, outputSource)
+	}
 	if inputFormat == GeoJSONType {
+		return c.convertGeoJSON(inputSource, outputSource, outputFormat)
+	}
+
+	input, inputErr := readerFromInput(inputSource)
+	if inputErr != nil {
+		return NewCommandError("trouble getting a reader from %q: %w", c.Input, inputErr)
+	}
+	if closer, ok := input.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	output, closeOutput, outputErr := writerFromOutput(outputSource, c.Overwrite)
+	if outputErr != nil {
+		return outputErr
+	}
+	defer func() { closeOutput(err != nil) }()
+
+	if inputFormat == ArrowType {
 		if outputFormat != ParquetType && outputFormat != GeoParquetType {
-			return NewCommandError("GeoJSON input can only be converted to GeoParquet")
+			return NewCommandError("Arrow IPC input can only be converted to GeoParquet")
 		}
-		convertOptions := &geojson.ConvertOptions{
-			MinFeatures:    c.Min,
-			MaxFeatures:    c.Max,
-			Compression:    c.Compression,
-			RowGroupLength: c.RowGroupLength,
+		convertOptions := &geoparquet.ConvertOptions{
+			InputPrimaryColumn: c.InputPrimaryColumn,
+			Compression:        c.Compression,
+			ColumnCompression:  c.CompressCol,
+			RowGroupLength:     c.RowGroupLength,
+			GeometryTypes:      c.GeometryTypes,
+			Edges:              c.Edges,
+			Version:            c.GeoParquetVersion,
+			DisableDictionary:  !c.Dictionary,
+			DataPageSize:       c.DataPageSize,
+			BoundsPrecision:    c.BboxPrecision,
+			Context:            commandContext,
 		}
-		if err := geojson.ToParquet(input, output, convertOptions); err != nil {
+		if err := geoparquet.FromArrow(input, output, convertOptions); err != nil {
 			return NewCommandError("%w", err)
 		}
 		return nil
 	}
 
 	if outputFormat == GeoJSONType {
-		if err := geojson.FromParquet(input, output); err != nil {
+		fromParquetOptions := &geojson.FromParquetOptions{
+			CollectionBbox:    c.CollectionBbox,
+			Flatten:           c.Flatten,
+			Pretty:            c.Pretty,
+			IdColumn:          c.IdColumn,
+			WKTProperty:       c.WKTProperty,
+			RFC7946:           c.RFC7946,
+			GeometryPrecision: c.GeometryPrecision,
+			Context:           commandContext,
+		}
+		if err := geojson.FromParquet(input, output, fromParquetOptions); err != nil {
 			return NewCommandError("%w", err)
 		}
 		return nil
 	}
 
+	inputPrimaryColumn := c.InputPrimaryColumn
+	if c.DetectGeometry || inputPrimaryColumn == "" || inputPrimaryColumn == geoparquet.DefaultGeometryColumn {
+		detected, err := detectGeometryColumn(input, c.DetectGeometry)
+		if err != nil {
+			return NewCommandError("%w", err)
+		}
+		if detected != "" {
+			inputPrimaryColumn = detected
+		}
+	}
+
+	compression := c.Compression
+	if c.CopyColumnChunks {
+		// copied column chunks keep the compression of the input
+		compression = ""
+	}
+
 	convertOptions := &geoparquet.ConvertOptions{
-		InputPrimaryColumn: c.InputPrimaryColumn,
-		Compression:        c.Compression,
+		InputPrimaryColumn: inputPrimaryColumn,
+		Compression:        compression,
+		ColumnCompression:  c.CompressCol,
 		RowGroupLength:     c.RowGroupLength,
+		GeometryTypes:      c.GeometryTypes,
+		Rename:             c.Rename,
+		Edges:              c.Edges,
+		Version:            c.GeoParquetVersion,
+		DisableDictionary:  !c.Dictionary,
+		DataPageSize:       c.DataPageSize,
+		BoundsPrecision:    c.BboxPrecision,
+		AddCentroid:        c.AddCentroid,
+		DropGeometry:       c.DropGeometry,
+		RecomputeMetadata:  c.RecomputeMetadata,
+		ReadAhead:          c.ReadAhead,
+		CopyColumnChunks:   c.CopyColumnChunks,
+		Context:            commandContext,
 	}
 
 	if err := geoparquet.FromParquet(input, output, convertOptions); err != nil {
+		notGeometry := &geoparquet.NotGeometryColumnError{}
+		if errors.As(err, &notGeometry) && len(notGeometry.Candidates) > 0 {
+			return NewCommandError("%w, use --input-primary-column to choose a geometry column", err)
+		}
 		return NewCommandError("%w", err)
 	}
 	return nil
 }
+
+// detectGeometryColumn returns the only column that looks like geometries in
+// Parquet input without "geo" metadata.  An empty string is returned if the
+// input has metadata.  Unless required is true, an empty string is also
+// returned if the input has a column with the default name or if no column
+// looks like geometries.  It is an error if more than one column looks like
+// geometries.
+func detectGeometryColumn(input parquet.ReaderAtSeeker, required bool) (string, error) {
+	// the reader is not closed because that would close the input
+	fileReader, err := file.NewParquetReader(input)
+	if err != nil {
+		if !required {
+			return "", nil
+		}
+		return "", fmt.Errorf("trouble reading the input: %w", err)
+	}
+	if _, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata()); err == nil {
+		if required {
+			logger.Debug("the input has geo metadata, the --detect-geometry option is ignored")
+		}
+		return "", nil
+	}
+	if !required && fileReader.MetaData().Schema.Root().FieldIndexByName(geoparquet.DefaultGeometryColumn) >= 0 {
+		return "", nil
+	}
+
+	names := geoparquet.DetectGeometryColumns(fileReader)
+	switch len(names) {
+	case 0:
+		if !required {
+			return "", nil
+		}
+		return "", errors.New("no columns look like WKB or WKT geometries, use --input-primary-column to name the geometry column")
+	case 1:
+		if required {
+			logger.Debug("using %q as the primary geometry column", names[0])
+		} else {
+			logger.Warn("no geo metadata found, using %q as the primary geometry column", names[0])
+		}
+		return names[0], nil
+	}
+	return "", fmt.Errorf("found more than one column that looks like geometries (%s), use --input-primary-column to choose one", strings.Join(names, ", "))
+}
+
+// convertGeoJSON converts GeoJSON to a single GeoParquet output file.  The
+// input is read sequentially, so stdin is not copied to a temporary file.
+func (c *ConvertCmd) convertGeoJSON(inputSource string, outputSource string, outputFormat FormatType) (err error) {
+	if outputFormat != ParquetType && outputFormat != GeoParquetType {
+		return NewCommandError("GeoJSON input can only be converted to GeoParquet")
+	}
+
+	input, inputErr := streamFromInput(inputSource)
+	if inputErr != nil {
+		return NewCommandError("trouble getting a reader from %q: %w", c.Input, inputErr)
+	}
+	if closer, ok := input.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	output, closeOutput, outputErr := writerFromOutput(outputSource, c.Overwrite)
+	if outputErr != nil {
+		return outputErr
+	}
+	defer func() { closeOutput(err != nil) }()
+
+	if err := geojson.ToParquet(input, output, c.geojsonOptions()); err != nil {
+		return geojsonError(err)
+	}
+	return nil
+}
+
+// convertDataset converts a directory of GeoParquet part files to a single
+// output file.
+func (c *ConvertCmd) convertDataset(inputSource string, outputSource string, outputFormat FormatType) (err error) {
+	if len(c.Rename) > 0 {
+		return NewCommandError("the --rename option is not supported when reading a directory")
+	}
+
+	inputs, closeInputs, inputErr := readersFromDirectory(inputSource)
+	if inputErr != nil {
+		return NewCommandError("trouble reading the dataset in %q: %w", inputSource, inputErr)
+	}
+	defer closeInputs()
+
+	output, closeOutput, outputErr := writerFromOutput(outputSource, c.Overwrite)
+	if outputErr != nil {
+		return outputErr
+	}
+	defer func() { closeOutput(err != nil) }()
+
+	if outputFormat == GeoJSONType {
+		fromParquetOptions := &geojson.FromParquetOptions{
+			CollectionBbox:    c.CollectionBbox,
+			Flatten:           c.Flatten,
+			Pretty:            c.Pretty,
+			IdColumn:          c.IdColumn,
+			WKTProperty:       c.WKTProperty,
+			RFC7946:           c.RFC7946,
+			GeometryPrecision: c.GeometryPrecision,
+			Context:           commandContext,
+		}
+		if err := geojson.FromParquetDataset(inputs, output, fromParquetOptions); err != nil {
+			return NewCommandError("%w", err)
+		}
+		return nil
+	}
+
+	convertOptions := &geoparquet.ConvertOptions{
+		Compression:       c.Compression,
+		ColumnCompression: c.CompressCol,
+		RowGroupLength:    c.RowGroupLength,
+		GeometryTypes:     c.GeometryTypes,
+		Edges:             c.Edges,
+		Version:           c.GeoParquetVersion,
+		DisableDictionary: !c.Dictionary,
+		DataPageSize:      c.DataPageSize,
+		BoundsPrecision:   c.BboxPrecision,
+		Context:           commandContext,
+	}
+	if err := geoparquet.FromDataset(inputs, output, convertOptions); err != nil {
+		return NewCommandError("%w", err)
+	}
+	return nil
+}
+
+// writeMetadataSidecar writes the same information as `describe --format json`
+// for the output file to a separate JSON file.
+func writeMetadataSidecar(outputSource string, sidecarPath string, overwrite bool) error {
+	output, openErr := os.Open(outputSource)
+	if openErr != nil {
+		return NewCommandError("trouble reading %q: %w", outputSource, openErr)
+	}
+	defer output.Close()
+
+	fileReader, fileErr := file.NewParquetReader(output)
+	if fileErr != nil {
+		return NewCommandError("failed to read %q as parquet: %w", outputSource, fileErr)
+	}
+	defer fileReader.Close()
+
+	data, jsonErr := json.MarshalIndent(newDescribeInfo(fileReader), "", "  ")
+	if jsonErr != nil {
+		return NewCommandError("failed to encode metadata: %w", jsonErr)
+	}
+
+	sidecar, err := createOutput(sidecarPath, overwrite)
+	if err != nil {
+		return err
+	}
+	if _, err := sidecar.Write(append(data, '\n')); err != nil {
+		_ = sidecar.Close()
+		return NewCommandError("failed to write %q: %w", sidecarPath, err)
+	}
+	if err := sidecar.Close(); err != nil {
+		return NewCommandError("failed to write %q: %w", sidecarPath, err)
+	}
+	return nil
+}
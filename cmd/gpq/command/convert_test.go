@@ -2,12 +2,29 @@ package command_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
 
+	"github.com/alecthomas/kong"
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/ipc"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/apache/arrow/go/v16/parquet"
+	"github.com/apache/arrow/go/v16/parquet/compress"
 	"github.com/apache/arrow/go/v16/parquet/file"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/wkb"
+	"github.com/paulmach/orb/encoding/wkt"
 	"github.com/planetlabs/gpq/cmd/gpq/command"
 	"github.com/planetlabs/gpq/internal/geo"
+	"github.com/planetlabs/gpq/internal/geoparquet"
+	"github.com/planetlabs/gpq/internal/pqutil"
 	"github.com/planetlabs/gpq/internal/test"
+	"github.com/planetlabs/gpq/internal/validator"
 )
 
 func (s *Suite) TestConvertGeoParquetToGeoJSONStdout() {
@@ -25,6 +42,22 @@ func (s *Suite) TestConvertGeoParquetToGeoJSONStdout() {
 	s.Len(collection.Features, 5)
 }
 
+func (s *Suite) TestConvertGeoParquetToGeoJSONCollectionBbox() {
+	cmd := &command.ConvertCmd{
+		From:           "auto",
+		Input:          "../../../internal/testdata/cases/example-v0.4.0.parquet",
+		To:             "geojson",
+		CollectionBbox: true,
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	collection := map[string]any{}
+	s.Require().NoError(json.Unmarshal(data, &collection))
+	s.Equal([]any{-180.0, -90.0, 180.0, 83.6451}, collection["bbox"])
+}
+
 func (s *Suite) TestConvertGeoJSONToGeoParquetStdout() {
 	cmd := &command.ConvertCmd{
 		From:  "auto",
@@ -134,3 +167,1318 @@ func (s *Suite) TestConvertGeoParquetUrlToGeoJSONStdout() {
 	s.Require().NoError(json.Unmarshal(data, collection))
 	s.Len(collection.Features, 5)
 }
+
+func (s *Suite) TestConvertArrowStdinToGeoParquetStdout() {
+	point, err := wkb.Marshal(orb.Point{1, 2})
+	s.Require().NoError(err)
+
+	arrowSchema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "geometry", Type: arrow.BinaryTypes.Binary, Nullable: true},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, arrowSchema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).Append("Null Island")
+	builder.Field(1).(*array.BinaryBuilder).Append(point)
+	record := builder.NewRecord()
+	defer record.Release()
+
+	input := &bytes.Buffer{}
+	ipcWriter := ipc.NewWriter(input, ipc.WithSchema(arrowSchema))
+	s.Require().NoError(ipcWriter.Write(record))
+	s.Require().NoError(ipcWriter.Close())
+	s.writeStdin(input.Bytes())
+
+	cmd := &command.ConvertCmd{
+		From: "arrow",
+		To:   "geoparquet",
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	s.Equal(int64(1), fileReader.NumRows())
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	s.Require().NoError(err)
+	s.Equal([]string{"Point"}, metadata.Columns["geometry"].GetGeometryTypes())
+}
+
+func (s *Suite) TestConvertGeoJSONToGeoParquetGeometryTypes() {
+	s.writeStdin([]byte(`{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {
+					"name": "Null Island"
+				},
+				"geometry": {
+					"type": "Point",
+					"coordinates": [0, 0]
+				}
+			}
+		]
+	}`))
+
+	cmd := &command.ConvertCmd{
+		From:          "geojson",
+		To:            "geoparquet",
+		GeometryTypes: []string{"Point", "MultiPoint"},
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	s.Require().NoError(err)
+	s.Equal([]string{"Point", "MultiPoint"}, metadata.Columns["geometry"].GetGeometryTypes())
+}
+
+func (s *Suite) TestConvertInvalidGeometryTypes() {
+	cmd := &command.ConvertCmd{
+		Input:         "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:            "geoparquet",
+		GeometryTypes: []string{"Polygon", "Blob"},
+	}
+
+	s.ErrorContains(cmd.Run(), `invalid --geometry-types: unsupported geometry type "Blob"`)
+}
+
+func (s *Suite) TestConvertGeoParquetRename() {
+	cmd := &command.ConvertCmd{
+		Input:  "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:     "geoparquet",
+		Rename: map[string]string{"geometry": "geom", "pop_est": "population"},
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	root := fileReader.MetaData().Schema.Root()
+	s.GreaterOrEqual(root.FieldIndexByName("population"), 0)
+	s.Equal(-1, root.FieldIndexByName("pop_est"))
+
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	s.Require().NoError(err)
+	s.Equal("geom", metadata.PrimaryColumn)
+	s.Contains(metadata.Columns, "geom")
+}
+
+func (s *Suite) TestConvertRenameGeoJSON() {
+	cmd := &command.ConvertCmd{
+		Input:  "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		From:   "geojson",
+		To:     "geoparquet",
+		Rename: map[string]string{"name": "label"},
+	}
+
+	s.ErrorContains(cmd.Run(), "the --rename option is only supported when converting Parquet or GeoParquet to GeoParquet")
+}
+
+func (s *Suite) TestConvertGeoJSONToGeoParquetEdges() {
+	s.writeStdin([]byte(`{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {
+					"name": "Null Island"
+				},
+				"geometry": {
+					"type": "Point",
+					"coordinates": [0, 0]
+				}
+			}
+		]
+	}`))
+
+	cmd := &command.ConvertCmd{
+		From:  "geojson",
+		To:    "geoparquet",
+		Edges: "spherical",
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	s.Require().NoError(err)
+	s.Equal("spherical", metadata.Columns["geometry"].Edges)
+	s.Equal([]string{"Point"}, metadata.Columns["geometry"].GetGeometryTypes())
+}
+
+func (s *Suite) TestConvertInvalidEdges() {
+	cmd := &command.ConvertCmd{
+		Input: "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:    "geoparquet",
+		Edges: "curved",
+	}
+
+	s.ErrorContains(cmd.Run(), `unsupported edges "curved"`)
+}
+
+func (s *Suite) TestConvertEdgesFlag() {
+	cli := &struct {
+		Convert command.ConvertCmd `cmd:""`
+	}{}
+	parser, err := kong.New(cli)
+	s.Require().NoError(err)
+
+	_, err = parser.Parse([]string{"convert", "input.geojson", "output.parquet"})
+	s.Require().NoError(err)
+	s.Equal("keep", cli.Convert.Edges)
+
+	_, err = parser.Parse([]string{"convert", "input.geojson", "output.parquet", "--edges", "spherical"})
+	s.Require().NoError(err)
+	s.Equal("spherical", cli.Convert.Edges)
+
+	_, err = parser.Parse([]string{"convert", "input.geojson", "output.parquet", "--edges", "curved"})
+	s.ErrorContains(err, `--edges must be one of "keep","planar","spherical" but got "curved"`)
+}
+
+func (s *Suite) TestConvertGeometryPrecisionFlag() {
+	cli := &struct {
+		Convert command.ConvertCmd `cmd:""`
+	}{}
+	parser, err := kong.New(cli)
+	s.Require().NoError(err)
+
+	_, err = parser.Parse([]string{"convert", "input.geojson", "output.parquet"})
+	s.Require().NoError(err)
+	s.Nil(cli.Convert.GeometryPrecision)
+
+	_, err = parser.Parse([]string{"convert", "input.geojson", "output.parquet", "--geometry-precision", "0"})
+	s.Require().NoError(err)
+	s.Require().NotNil(cli.Convert.GeometryPrecision)
+	s.Equal(0, *cli.Convert.GeometryPrecision)
+}
+
+func (s *Suite) TestConvertGeoJSONToGeoParquetJSONProperties() {
+	s.writeStdin([]byte(`{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {
+					"extra": {"answer": 42}
+				},
+				"geometry": {
+					"type": "Point",
+					"coordinates": [0, 0]
+				}
+			}
+		]
+	}`))
+
+	cmd := &command.ConvertCmd{
+		From:           "geojson",
+		To:             "geoparquet",
+		JSONProperties: true,
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	extra, ok := pqutil.LookupPrimitiveNode(fileReader.MetaData().Schema, "extra")
+	s.Require().True(ok)
+	s.Equal(pqutil.ParquetStringType, extra.LogicalType())
+}
+
+func (s *Suite) convertedEncodings(dictionary bool) []parquet.Encoding {
+	cmd := &command.ConvertCmd{
+		Input:        "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:           "geoparquet",
+		Dictionary:   dictionary,
+		DataPageSize: 1024,
+	}
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	columnChunk, err := fileReader.RowGroup(0).MetaData().ColumnChunk(2)
+	s.Require().NoError(err)
+	return columnChunk.Encodings()
+}
+
+func (s *Suite) TestConvertDictionary() {
+	s.Contains(s.convertedEncodings(true), parquet.Encodings.RLEDict)
+}
+
+func (s *Suite) TestConvertNoDictionary() {
+	s.NotContains(s.convertedEncodings(false), parquet.Encodings.RLEDict)
+}
+
+func (s *Suite) TestConvertInvalidDataPageSize() {
+	cmd := &command.ConvertCmd{
+		Input:        "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:           "geoparquet",
+		DataPageSize: -1,
+	}
+
+	s.ErrorContains(cmd.Run(), "invalid --data-page-size: data page size must not be negative, got -1")
+}
+
+func (s *Suite) TestConvertInvalidMaxRowGroupBytes() {
+	cmd := &command.ConvertCmd{
+		Input:            "../../../internal/geojson/testdata/example.geojson",
+		To:               "geoparquet",
+		Output:           filepath.Join(s.T().TempDir(), "output.parquet"),
+		MaxRowGroupBytes: -1,
+	}
+
+	s.ErrorContains(cmd.Run(), "invalid --max-row-group-bytes: must not be negative, got -1")
+}
+
+func (s *Suite) TestConvertPartitionByRequiresGeoJSON() {
+	cmd := &command.ConvertCmd{
+		Input:       "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:          "geoparquet",
+		PartitionBy: 10,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --partition-by option is only supported when converting GeoJSON to GeoParquet")
+}
+
+func (s *Suite) TestConvertFlattenCollectionsRequiresGeoJSON() {
+	cmd := &command.ConvertCmd{
+		Input:              "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:                 "geoparquet",
+		FlattenCollections: true,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --flatten-geometry-collection option is only supported when converting GeoJSON to GeoParquet")
+}
+
+func (s *Suite) TestConvertBboxPrecision() {
+	precision := 1
+	cmd := &command.ConvertCmd{
+		Input:         "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:            "geoparquet",
+		BboxPrecision: &precision,
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	s.Require().NoError(err)
+	s.Equal([]float64{-180, -18.3, 180, 83.3}, metadata.Columns[metadata.PrimaryColumn].Bounds)
+}
+
+func (s *Suite) TestConvertBboxPrecisionRequiresGeoParquet() {
+	precision := 1
+	cmd := &command.ConvertCmd{
+		Input:         "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:            "geojson",
+		BboxPrecision: &precision,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --bbox-precision option is only supported when writing GeoParquet")
+}
+
+func (s *Suite) TestConvertReadAhead() {
+	cmd := &command.ConvertCmd{
+		Input:     "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:        "geoparquet",
+		ReadAhead: true,
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	s.Equal(int64(5), fileReader.NumRows())
+}
+
+func (s *Suite) TestConvertCopyColumnChunks() {
+	inputPath := "../../../internal/testdata/cases/example-v1.0.0.parquet"
+	cmd := &command.ConvertCmd{
+		Input:            inputPath,
+		To:               "geoparquet",
+		Compression:      "zstd",
+		Dictionary:       true,
+		Edges:            "spherical",
+		CopyColumnChunks: true,
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	input, err := os.ReadFile(inputPath)
+	s.Require().NoError(err)
+	inputReader, err := file.NewParquetReader(bytes.NewReader(input))
+	s.Require().NoError(err)
+	defer inputReader.Close()
+
+	footerOffset := len(input) - inputReader.MetaData().Size() - 8
+	s.Equal(input[:footerOffset], data[:footerOffset])
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	s.Require().NoError(err)
+	s.Equal("spherical", metadata.Columns[metadata.PrimaryColumn].Edges)
+}
+
+func (s *Suite) TestConvertCopyColumnChunksRequiresParquet() {
+	cmd := &command.ConvertCmd{
+		Input:            "../../../internal/geojson/testdata/example.geojson",
+		To:               "geoparquet",
+		CopyColumnChunks: true,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --copy-column-chunks option is only supported when converting Parquet or GeoParquet to GeoParquet")
+}
+
+func (s *Suite) TestConvertReadAheadRequiresParquet() {
+	cmd := &command.ConvertCmd{
+		Input:     "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:        "geojson",
+		ReadAhead: true,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --read-ahead option is only supported when converting Parquet or GeoParquet to GeoParquet")
+}
+
+func (s *Suite) TestConvertRecomputeMetadata() {
+	cmd := &command.ConvertCmd{
+		Input:             "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:                "geoparquet",
+		RecomputeMetadata: true,
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	s.Require().NoError(err)
+	primary := metadata.Columns[metadata.PrimaryColumn]
+	s.Len(primary.Bounds, 4)
+	s.ElementsMatch([]string{"Polygon", "MultiPolygon"}, primary.GetGeometryTypes())
+}
+
+func (s *Suite) TestConvertRecomputeMetadataRequiresGeoParquet() {
+	cmd := &command.ConvertCmd{
+		Input:             "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:                "geojson",
+		RecomputeMetadata: true,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --recompute-metadata option is only supported when converting Parquet or GeoParquet to GeoParquet")
+}
+
+func (s *Suite) TestConvertDropGeometry() {
+	cmd := &command.ConvertCmd{
+		Input:        "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:           "parquet",
+		DropGeometry: true,
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	s.Equal(int64(5), fileReader.NumRows())
+	s.Equal(-1, fileReader.MetaData().Schema.Root().FieldIndexByName("geometry"))
+	s.Nil(fileReader.MetaData().KeyValueMetadata().FindValue(geoparquet.MetadataKey))
+}
+
+func (s *Suite) TestConvertDropGeometryRequiresParquet() {
+	cmd := &command.ConvertCmd{
+		Input:        "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:           "geojson",
+		DropGeometry: true,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --drop-geometry option is only supported when converting Parquet or GeoParquet to Parquet")
+}
+
+func (s *Suite) TestConvertDropGeometryWithMetadataOptions() {
+	cmd := &command.ConvertCmd{
+		Input:        "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:           "parquet",
+		DropGeometry: true,
+		Edges:        "spherical",
+	}
+
+	s.ErrorContains(cmd.Run(), "the --drop-geometry option cannot be used with options for the geo metadata")
+}
+
+func (s *Suite) TestConvertRFC7946RequiresGeoJSON() {
+	cmd := &command.ConvertCmd{
+		Input:   "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:      "geoparquet",
+		RFC7946: true,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --rfc7946 option is only supported when writing GeoJSON")
+}
+
+func (s *Suite) TestConvertSampleBytesRequiresGeoJSON() {
+	cmd := &command.ConvertCmd{
+		Input:       "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:          "geoparquet",
+		SampleBytes: 1000,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --sample-bytes option is only supported when converting GeoJSON to GeoParquet")
+}
+
+func (s *Suite) TestConvertSampleBytes() {
+	cmd := &command.ConvertCmd{
+		From:        "auto",
+		Input:       "../../../internal/geojson/testdata/example.geojson",
+		To:          "parquet",
+		Min:         10,
+		Max:         100,
+		SampleBytes: 1000,
+	}
+
+	s.Require().NoError(cmd.Run())
+
+	data := s.readStdout()
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	s.Equal(int64(5), fileReader.NumRows())
+}
+
+// newDataset copies an example file into a directory as two part files.
+func (s *Suite) newDataset() string {
+	data, err := os.ReadFile("../../../internal/testdata/cases/example-v1.0.0.parquet")
+	s.Require().NoError(err)
+
+	dir := s.T().TempDir()
+	for _, name := range []string{"part-0.parquet", "part-1.parquet", "_SUCCESS"} {
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, name), data, 0o644))
+	}
+	return dir
+}
+
+func (s *Suite) TestConvertDatasetToGeoJSON() {
+	cmd := &command.ConvertCmd{
+		Input: s.newDataset(),
+		To:    "geojson",
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	collection := &geo.FeatureCollection{}
+	s.Require().NoError(json.Unmarshal(data, collection))
+	s.Len(collection.Features, 10)
+}
+
+func (s *Suite) TestConvertDatasetToGeoParquet() {
+	cmd := &command.ConvertCmd{
+		Input: s.newDataset(),
+		To:    "geoparquet",
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	s.Equal(int64(10), fileReader.NumRows())
+
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	s.Require().NoError(err)
+	s.Equal("geometry", metadata.PrimaryColumn)
+}
+
+func (s *Suite) TestConvertDatasetFromGeoJSON() {
+	cmd := &command.ConvertCmd{
+		Input: s.newDataset(),
+		From:  "geojson",
+		To:    "geoparquet",
+	}
+
+	s.ErrorContains(cmd.Run(), "a directory can only be read as a GeoParquet dataset")
+}
+
+func (s *Suite) TestConvertMixedDimensions() {
+	cmd := &command.ConvertCmd{
+		Input: "../../../internal/geojson/testdata/mixed-dimensions.geojson",
+		To:    "geoparquet",
+	}
+
+	s.ErrorContains(cmd.Run(), "use --force-2d to drop the Z values")
+}
+
+func (s *Suite) TestConvertMixedDimensionsForce2D() {
+	cmd := &command.ConvertCmd{
+		Input:   "../../../internal/geojson/testdata/mixed-dimensions.geojson",
+		To:      "geoparquet",
+		Force2D: true,
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	s.Equal(int64(2), fileReader.NumRows())
+}
+
+func (s *Suite) TestConvertGeoParquetToGeoJSONIdColumn() {
+	cmd := &command.ConvertCmd{
+		Input:    "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:       "geojson",
+		IdColumn: "iso_a3",
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	collection := &geo.FeatureCollection{}
+	s.Require().NoError(json.Unmarshal(data, collection))
+	s.Require().Len(collection.Features, 5)
+	for _, feature := range collection.Features {
+		s.NotNil(feature.Id)
+		s.NotContains(feature.Properties, "iso_a3")
+	}
+}
+
+func (s *Suite) TestConvertGeoParquetToGeoJSONWKTProperty() {
+	cmd := &command.ConvertCmd{
+		Input:       "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:          "geojson",
+		WKTProperty: "wkt",
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	collection := &geo.FeatureCollection{}
+	s.Require().NoError(json.Unmarshal(data, collection))
+	s.Require().Len(collection.Features, 5)
+	for _, feature := range collection.Features {
+		s.Require().NotNil(feature.Geometry)
+		s.Equal(wkt.MarshalString(feature.Geometry), feature.Properties["wkt"])
+	}
+}
+
+func (s *Suite) TestConvertWKTPropertyRequiresGeoJSON() {
+	cmd := &command.ConvertCmd{
+		Input:       "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:          "geoparquet",
+		WKTProperty: "wkt",
+	}
+
+	s.ErrorContains(cmd.Run(), "the --geometry-as-wkt-property option is only supported when writing GeoJSON")
+}
+
+func (s *Suite) TestConvertGeoParquetVersion() {
+	cmd := &command.ConvertCmd{
+		Input:             "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:                "geoparquet",
+		GeoParquetVersion: "1.1.0",
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	s.Require().NoError(err)
+	s.Equal("1.1.0", metadata.Version)
+}
+
+func (s *Suite) TestConvertInvalidGeoParquetVersion() {
+	cmd := &command.ConvertCmd{
+		Input:             "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:                "geoparquet",
+		GeoParquetVersion: "0.1",
+	}
+
+	s.ErrorContains(cmd.Run(), `invalid --geoparquet-version: unsupported version "0.1"`)
+}
+
+func (s *Suite) TestConvertCompressCol() {
+	cmd := &command.ConvertCmd{
+		Input:       "../../../internal/geojson/testdata/example.geojson",
+		To:          "geoparquet",
+		Compression: "snappy",
+		CompressCol: map[string]string{"geometry": "zstd"},
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	rowGroup := fileReader.RowGroup(0).MetaData()
+	for i := 0; i < rowGroup.NumColumns(); i += 1 {
+		columnChunk, err := rowGroup.ColumnChunk(i)
+		s.Require().NoError(err)
+		expected := compress.Codecs.Snappy
+		if columnChunk.PathInSchema().String() == "geometry" {
+			expected = compress.Codecs.Zstd
+		}
+		s.Equal(expected, columnChunk.Compression(), columnChunk.PathInSchema().String())
+	}
+}
+
+func (s *Suite) TestConvertInvalidCompressCol() {
+	cmd := &command.ConvertCmd{
+		Input:       "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:          "geoparquet",
+		CompressCol: map[string]string{"geometry": "zip"},
+	}
+
+	s.ErrorContains(cmd.Run(), `invalid --compress-col: invalid compression for column "geometry"`)
+}
+
+func (s *Suite) TestConvertMetadataSidecar() {
+	dir := s.T().TempDir()
+	output := filepath.Join(dir, "example.parquet")
+	sidecar := filepath.Join(dir, "example.json")
+
+	cmd := &command.ConvertCmd{
+		Input:           "../../../internal/geojson/testdata/example.geojson",
+		Output:          output,
+		MetadataSidecar: sidecar,
+	}
+	s.Require().NoError(cmd.Run())
+
+	data, err := os.ReadFile(sidecar)
+	s.Require().NoError(err)
+
+	info := &command.DescribeInfo{}
+	s.Require().NoError(json.Unmarshal(data, info))
+
+	s.Equal(int64(5), info.NumRows)
+	s.Equal(int64(1), info.NumRowGroups)
+	s.Empty(info.Issues)
+	s.Require().NotNil(info.Metadata)
+	s.Equal("geometry", info.Metadata.PrimaryColumn)
+	s.Contains(info.Metadata.Columns, "geometry")
+	s.Require().NotNil(info.Schema)
+	s.NotEmpty(info.Schema.Fields)
+}
+
+func (s *Suite) TestConvertMetadataSidecarStdout() {
+	cmd := &command.ConvertCmd{
+		Input:           "../../../internal/geojson/testdata/example.geojson",
+		To:              "geoparquet",
+		MetadataSidecar: filepath.Join(s.T().TempDir(), "example.json"),
+	}
+
+	s.ErrorContains(cmd.Run(), "the --metadata-sidecar option requires an output file")
+}
+
+func (s *Suite) TestConvertForeignMembersRequiresGeoJSON() {
+	cmd := &command.ConvertCmd{
+		Input:          "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:             "geoparquet",
+		ForeignMembers: true,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --foreign-members option is only supported when converting GeoJSON to GeoParquet")
+}
+
+func (s *Suite) TestConvertIdFromIndexRequiresGeoJSON() {
+	cmd := &command.ConvertCmd{
+		Input:       "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:          "geoparquet",
+		IdFromIndex: true,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --geojson-feature-id-from-index option is only supported when converting GeoJSON to GeoParquet")
+}
+
+func (s *Suite) TestConvertAddCentroid() {
+	output := filepath.Join(s.T().TempDir(), "centroid.parquet")
+	cmd := &command.ConvertCmd{
+		Input:       "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		Output:      output,
+		AddCentroid: "centroid",
+	}
+	s.Require().NoError(cmd.Run())
+
+	data, err := os.ReadFile(output)
+	s.Require().NoError(err)
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	s.Require().NoError(err)
+	s.Equal("geometry", metadata.PrimaryColumn)
+	s.Require().Contains(metadata.Columns, "centroid")
+	s.Equal([]string{"Point"}, metadata.Columns["centroid"].GetGeometryTypes())
+	s.Equal(int64(5), fileReader.NumRows())
+
+	report, err := validator.New(false).Validate(context.Background(), bytes.NewReader(data), output)
+	s.Require().NoError(err)
+	for _, check := range report.Checks {
+		if check.Run {
+			s.True(check.Passed, "%s: %s", check.Title, check.Message)
+		}
+	}
+}
+
+func (s *Suite) TestConvertAddCentroidRequiresParquet() {
+	cmd := &command.ConvertCmd{
+		Input:       "../../../internal/geojson/testdata/example.geojson",
+		To:          "geoparquet",
+		AddCentroid: "centroid",
+	}
+
+	s.ErrorContains(cmd.Run(), "the --add-centroid option is only supported when converting Parquet or GeoParquet to GeoParquet")
+}
+
+func (s *Suite) TestConvertParquetDetectPrimaryColumn() {
+	type Row struct {
+		Name  string `parquet:"name=name, logical=String" json:"name"`
+		Shape []byte `parquet:"name=shape" json:"shape"`
+	}
+
+	shape, err := wkb.Marshal(orb.Point{1, 2})
+	s.Require().NoError(err)
+
+	input := test.ParquetFromStructs(s.T(), []*Row{{Name: "test-point", Shape: shape}})
+	data, err := io.ReadAll(io.NewSectionReader(input, 0, 1<<20))
+	s.Require().NoError(err)
+	s.writeStdin(data)
+
+	cmd := &command.ConvertCmd{
+		From:               "parquet",
+		To:                 "geoparquet",
+		InputPrimaryColumn: "geometry",
+	}
+	s.Require().NoError(cmd.Run())
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(s.readStdout()))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	s.Require().NoError(err)
+	s.Equal("shape", metadata.PrimaryColumn)
+	s.Contains(metadata.Columns, "shape")
+}
+
+func (s *Suite) TestConvertParquetDetectPrimaryColumnMultiple() {
+	type Row struct {
+		Name  string `parquet:"name=name, logical=String" json:"name"`
+		Shape []byte `parquet:"name=shape" json:"shape"`
+		Other []byte `parquet:"name=other" json:"other"`
+	}
+
+	shape, err := wkb.Marshal(orb.Point{1, 2})
+	s.Require().NoError(err)
+
+	input := test.ParquetFromStructs(s.T(), []*Row{{Name: "test-point", Shape: shape, Other: shape}})
+	data, err := io.ReadAll(io.NewSectionReader(input, 0, 1<<20))
+	s.Require().NoError(err)
+	s.writeStdin(data)
+
+	cmd := &command.ConvertCmd{
+		From: "parquet",
+		To:   "geoparquet",
+	}
+	s.ErrorContains(cmd.Run(), "found more than one column that looks like geometries (shape, other)")
+}
+
+func (s *Suite) TestConvertParquetNonSpatialPrimaryColumn() {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry int32  `parquet:"name=geometry" json:"geometry"`
+		Shape    []byte `parquet:"name=shape" json:"shape"`
+	}
+
+	shape, err := wkb.Marshal(orb.Point{1, 2})
+	s.Require().NoError(err)
+
+	input := test.ParquetFromStructs(s.T(), []*Row{{Name: "test-point", Geometry: 42, Shape: shape}})
+	data, err := io.ReadAll(io.NewSectionReader(input, 0, 1<<20))
+	s.Require().NoError(err)
+	s.writeStdin(data)
+
+	cmd := &command.ConvertCmd{
+		From: "parquet",
+		To:   "geoparquet",
+	}
+	err = cmd.Run()
+	s.ErrorContains(err, "possible columns: shape")
+	s.ErrorContains(err, "use --input-primary-column to choose a geometry column")
+}
+
+func (s *Suite) TestConvertNoMetadata() {
+	cmd := &command.ConvertCmd{
+		Input:      "../../../internal/geojson/testdata/example.geojson",
+		To:         "parquet",
+		NoMetadata: true,
+	}
+
+	s.Require().NoError(cmd.Run())
+	data := s.readStdout()
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	s.Equal(int64(5), fileReader.NumRows())
+	s.Nil(fileReader.MetaData().KeyValueMetadata().FindValue(geoparquet.MetadataKey))
+	s.GreaterOrEqual(fileReader.MetaData().Schema.Root().FieldIndexByName("geometry"), 0)
+}
+
+func (s *Suite) TestConvertNoMetadataRequiresGeoJSON() {
+	cmd := &command.ConvertCmd{
+		Input:      "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:         "parquet",
+		NoMetadata: true,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --no-metadata option is only supported when converting GeoJSON to Parquet")
+}
+
+func (s *Suite) TestConvertNoMetadataWithMetadataOptions() {
+	cmd := &command.ConvertCmd{
+		Input:      "../../../internal/geojson/testdata/example.geojson",
+		To:         "parquet",
+		NoMetadata: true,
+		Edges:      "spherical",
+	}
+
+	s.ErrorContains(cmd.Run(), "the --no-metadata option cannot be used with options for the geo metadata")
+}
+
+func (s *Suite) TestConvertDetectGeometry() {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry int32  `parquet:"name=geometry" json:"geometry"`
+		Shape    []byte `parquet:"name=shape" json:"shape"`
+	}
+
+	shape, err := wkb.Marshal(orb.Point{1, 2})
+	s.Require().NoError(err)
+
+	input := test.ParquetFromStructs(s.T(), []*Row{{Name: "test-point", Geometry: 42, Shape: shape}})
+	data, err := io.ReadAll(io.NewSectionReader(input, 0, 1<<20))
+	s.Require().NoError(err)
+	s.writeStdin(data)
+
+	cmd := &command.ConvertCmd{
+		From:           "parquet",
+		To:             "geoparquet",
+		DetectGeometry: true,
+	}
+	s.Require().NoError(cmd.Run())
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(s.readStdout()))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	s.Require().NoError(err)
+	s.Equal("shape", metadata.PrimaryColumn)
+}
+
+func (s *Suite) TestConvertDetectGeometryMultiple() {
+	type Row struct {
+		Name  string `parquet:"name=name, logical=String" json:"name"`
+		Shape []byte `parquet:"name=shape" json:"shape"`
+		Other []byte `parquet:"name=other" json:"other"`
+	}
+
+	shape, err := wkb.Marshal(orb.Point{1, 2})
+	s.Require().NoError(err)
+
+	input := test.ParquetFromStructs(s.T(), []*Row{{Name: "test-point", Shape: shape, Other: shape}})
+	data, err := io.ReadAll(io.NewSectionReader(input, 0, 1<<20))
+	s.Require().NoError(err)
+	s.writeStdin(data)
+
+	cmd := &command.ConvertCmd{
+		From:           "parquet",
+		To:             "geoparquet",
+		DetectGeometry: true,
+	}
+	s.ErrorContains(cmd.Run(), "found more than one column that looks like geometries (shape, other)")
+}
+
+func (s *Suite) TestConvertDetectGeometryNone() {
+	type Row struct {
+		Name  string `parquet:"name=name, logical=String" json:"name"`
+		Count int32  `parquet:"name=count" json:"count"`
+	}
+
+	input := test.ParquetFromStructs(s.T(), []*Row{{Name: "not-spatial", Count: 1}})
+	data, err := io.ReadAll(io.NewSectionReader(input, 0, 1<<20))
+	s.Require().NoError(err)
+	s.writeStdin(data)
+
+	cmd := &command.ConvertCmd{
+		From:           "parquet",
+		To:             "geoparquet",
+		DetectGeometry: true,
+	}
+	s.ErrorContains(cmd.Run(), "no columns look like WKB or WKT geometries")
+}
+
+func (s *Suite) TestConvertDetectGeometryWithInputPrimaryColumn() {
+	cmd := &command.ConvertCmd{
+		Input:              "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:                 "geoparquet",
+		DetectGeometry:     true,
+		InputPrimaryColumn: "geom",
+	}
+
+	s.ErrorContains(cmd.Run(), "the --detect-geometry option cannot be used with --input-primary-column")
+}
+
+func (s *Suite) TestConvertGeometryFirstRequiresGeoJSON() {
+	cmd := &command.ConvertCmd{
+		Input:         "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:            "geoparquet",
+		GeometryFirst: true,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --geometry-first option is only supported when converting GeoJSON to GeoParquet")
+}
+
+func (s *Suite) TestConvertGeometryPrecisionRequiresGeoJSON() {
+	precision := 3
+	cmd := &command.ConvertCmd{
+		Input:             "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:                "geoparquet",
+		GeometryPrecision: &precision,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --geometry-precision option is only supported when converting GeoJSON to GeoParquet or GeoParquet to GeoJSON")
+}
+
+func (s *Suite) TestConvertGeometryPrecisionNegative() {
+	precision := -1
+	cmd := &command.ConvertCmd{
+		Input:             "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:                "geojson",
+		GeometryPrecision: &precision,
+	}
+
+	s.ErrorContains(cmd.Run(), "invalid --geometry-precision")
+}
+
+func (s *Suite) TestConvertGeometryPrecisionTooLarge() {
+	precision := geo.MaxPrecision + 1
+	cmd := &command.ConvertCmd{
+		Input:             "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		To:                "geojson",
+		GeometryPrecision: &precision,
+	}
+
+	s.ErrorContains(cmd.Run(), "precision must be between 0 and 10")
+}
+
+func (s *Suite) TestConvertNoClobber() {
+	output := filepath.Join(s.T().TempDir(), "existing.parquet")
+	s.Require().NoError(os.WriteFile(output, []byte("keep me"), 0o644))
+
+	cmd := &command.ConvertCmd{
+		Input:  "../../../internal/geojson/testdata/example.geojson",
+		Output: output,
+	}
+	s.ErrorContains(cmd.Run(), "already exists, use --overwrite to replace it")
+
+	data, err := os.ReadFile(output)
+	s.Require().NoError(err)
+	s.Equal("keep me", string(data))
+}
+
+func (s *Suite) TestConvertGeoJSONFromPipe() {
+	data, err := os.ReadFile("../../../internal/geojson/testdata/example.geojson")
+	s.Require().NoError(err)
+
+	reader, writer, err := os.Pipe()
+	s.Require().NoError(err)
+	defer reader.Close()
+	os.Stdin = reader
+
+	go func() {
+		_, _ = writer.Write(data)
+		_ = writer.Close()
+	}()
+
+	cmd := &command.ConvertCmd{
+		Input: "-",
+		From:  "geojson",
+		To:    "geoparquet",
+	}
+	s.Require().NoError(cmd.Run())
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(s.readStdout()))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+	s.Equal(int64(5), fileReader.NumRows())
+}
+
+func (s *Suite) TestConvertFailureRemovesOutput() {
+	dir := s.T().TempDir()
+	input := filepath.Join(dir, "invalid.geojson")
+	s.Require().NoError(os.WriteFile(input, []byte(`{"type": "FeatureCollection", "features": [{"type": "Feature", "geometry": {"type": "Curve"}}]}`), 0o644))
+	output := filepath.Join(dir, "output.parquet")
+
+	cmd := &command.ConvertCmd{
+		Input:  input,
+		Output: output,
+	}
+	s.Error(cmd.Run())
+
+	_, err := os.Stat(output)
+	s.ErrorIs(err, os.ErrNotExist)
+}
+
+func (s *Suite) TestConvertNoClobberSidecar() {
+	dir := s.T().TempDir()
+	sidecar := filepath.Join(dir, "example.json")
+	s.Require().NoError(os.WriteFile(sidecar, []byte("{}"), 0o644))
+
+	cmd := &command.ConvertCmd{
+		Input:           "../../../internal/geojson/testdata/example.geojson",
+		Output:          filepath.Join(dir, "example.parquet"),
+		MetadataSidecar: sidecar,
+	}
+	s.ErrorContains(cmd.Run(), "already exists, use --overwrite to replace it")
+
+	_, err := os.Stat(filepath.Join(dir, "example.parquet"))
+	s.ErrorIs(err, os.ErrNotExist)
+}
+
+func (s *Suite) TestConvertOverwrite() {
+	output := filepath.Join(s.T().TempDir(), "existing.parquet")
+	s.Require().NoError(os.WriteFile(output, []byte("replace me"), 0o644))
+
+	cmd := &command.ConvertCmd{
+		Input:     "../../../internal/geojson/testdata/example.geojson",
+		Output:    output,
+		Overwrite: true,
+	}
+	s.Require().NoError(cmd.Run())
+
+	data, err := os.ReadFile(output)
+	s.Require().NoError(err)
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+	s.Require().NoError(err)
+	defer fileReader.Close()
+	s.Equal(int64(5), fileReader.NumRows())
+}
+
+func (s *Suite) TestConvertSplit() {
+	dir := filepath.Join(s.T().TempDir(), "parts")
+
+	cmd := &command.ConvertCmd{
+		Input:  "../../../internal/geojson/testdata/example.geojson",
+		Output: dir,
+		Split:  2,
+	}
+	s.Require().NoError(cmd.Run())
+
+	entries, err := os.ReadDir(dir)
+	s.Require().NoError(err)
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	s.Equal([]string{"part-00001.parquet", "part-00002.parquet", "part-00003.parquet"}, names)
+
+	rows := []int64{}
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		s.Require().NoError(err)
+
+		fileReader, err := file.NewParquetReader(bytes.NewReader(data))
+		s.Require().NoError(err)
+		rows = append(rows, fileReader.NumRows())
+
+		metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+		s.Require().NoError(err)
+		s.Len(metadata.Columns[metadata.PrimaryColumn].Bounds, 4)
+		s.Require().NoError(fileReader.Close())
+	}
+	s.Equal([]int64{2, 2, 1}, rows)
+}
+
+func (s *Suite) TestConvertSplitNoClobber() {
+	dir := s.T().TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "part-00001.parquet"), []byte("keep me"), 0o644))
+
+	cmd := &command.ConvertCmd{
+		Input:  "../../../internal/geojson/testdata/example.geojson",
+		Output: dir,
+		Split:  2,
+	}
+	s.ErrorContains(cmd.Run(), "already exists, use --overwrite to replace it")
+
+	data, err := os.ReadFile(filepath.Join(dir, "part-00001.parquet"))
+	s.Require().NoError(err)
+	s.Equal("keep me", string(data))
+}
+
+func (s *Suite) TestConvertSplitOverwriteRemovesOldParts() {
+	dir := s.T().TempDir()
+	for _, name := range []string{"part-00001.parquet", "part-00002.parquet", "part-00003.parquet", "part-00004.parquet"} {
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, name), []byte("stale"), 0o644))
+	}
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "other.txt"), []byte("keep me"), 0o644))
+
+	cmd := &command.ConvertCmd{
+		Input:     "../../../internal/geojson/testdata/example.geojson",
+		Output:    dir,
+		Split:     2,
+		Overwrite: true,
+	}
+	s.Require().NoError(cmd.Run())
+
+	entries, err := os.ReadDir(dir)
+	s.Require().NoError(err)
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	s.Equal([]string{"other.txt", "part-00001.parquet", "part-00002.parquet", "part-00003.parquet"}, names)
+}
+
+func (s *Suite) TestConvertSplitEmptyInput() {
+	dir := filepath.Join(s.T().TempDir(), "parts")
+	s.writeStdin([]byte(`{"type": "FeatureCollection", "features": []}`))
+
+	cmd := &command.ConvertCmd{
+		From:   "geojson",
+		Output: dir,
+		Split:  2,
+	}
+	s.Require().NoError(cmd.Run())
+
+	entries, err := os.ReadDir(dir)
+	s.Require().NoError(err)
+	s.Empty(entries)
+}
+
+func (s *Suite) TestConvertSplitFailureRemovesParts() {
+	dir := filepath.Join(s.T().TempDir(), "parts")
+	s.writeStdin([]byte(`{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [1, 2]}, "properties": {}},
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [3, 4]}, "properties": {}},
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [5, 6]}, "properties": {}},
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [7, 8, 9]}, "properties": {}}
+	]}`))
+
+	cmd := &command.ConvertCmd{
+		From:   "geojson",
+		Output: dir,
+		Split:  2,
+		Min:    1,
+	}
+	s.ErrorContains(cmd.Run(), "found both 2D and 3D coordinates")
+
+	entries, err := os.ReadDir(dir)
+	s.Require().NoError(err)
+	s.Empty(entries)
+}
+
+func (s *Suite) TestConvertSplitOverwriteFailureKeepsOldParts() {
+	dir := s.T().TempDir()
+	for _, name := range []string{"part-00001.parquet", "part-00002.parquet"} {
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, name), []byte("keep me"), 0o644))
+	}
+	s.writeStdin([]byte(`{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [1, 2]}, "properties": {}},
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [3, 4]}, "properties": {}},
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [5, 6]}, "properties": {}},
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [7, 8, 9]}, "properties": {}}
+	]}`))
+
+	cmd := &command.ConvertCmd{
+		From:      "geojson",
+		Output:    dir,
+		Split:     2,
+		Min:       1,
+		Overwrite: true,
+	}
+	s.ErrorContains(cmd.Run(), "found both 2D and 3D coordinates")
+
+	entries, err := os.ReadDir(dir)
+	s.Require().NoError(err)
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		s.Require().NoError(err)
+		s.Equal("keep me", string(data))
+	}
+	s.Equal([]string{"part-00001.parquet", "part-00002.parquet"}, names)
+}
+
+func (s *Suite) TestConvertSplitRequiresGeoJSON() {
+	cmd := &command.ConvertCmd{
+		Input:  "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		Output: s.T().TempDir(),
+		Split:  2,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --split option is only supported when converting GeoJSON to GeoParquet")
+}
+
+func (s *Suite) TestConvertSplitRequiresOutput() {
+	cmd := &command.ConvertCmd{
+		Input: "../../../internal/geojson/testdata/example.geojson",
+		To:    "geoparquet",
+		Split: 2,
+	}
+
+	s.ErrorContains(cmd.Run(), "the --split option requires an output directory")
+}
@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -28,14 +29,19 @@ import (
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
 	"github.com/planetlabs/gpq/internal/geoparquet"
+	"github.com/planetlabs/gpq/internal/pqutil"
 	"golang.org/x/term"
 )
 
 type DescribeCmd struct {
-	Input        string `arg:"" optional:"" name:"input" help:"Path or URL for a GeoParquet file.  If not provided, input is read from stdin."`
-	Format       string `help:"Report format.  Possible values: ${enum}." enum:"text, json" default:"text"`
-	MetadataOnly bool   `help:"Print the unformatted geo metadata only (other arguments will be ignored)."`
-	Unpretty     bool   `help:"No newlines or indentation in the JSON output."`
+	Input        string   `arg:"" optional:"" name:"input" help:"Path or URL for a GeoParquet file (or a directory of GeoParquet part files).  If not provided, input is read from stdin."`
+	Format       string   `help:"Report format.  Possible values: ${enum}." enum:"text, json" default:"text"`
+	MetadataOnly bool     `help:"Print the unformatted geo metadata only (other arguments will be ignored)."`
+	Unpretty     bool     `help:"No newlines or indentation in the JSON output."`
+	Stats        bool     `help:"Include the total compressed size in bytes of each column (summed across row groups)."`
+	MaxColumns   int      `help:"Show at most this many columns.  The number of columns not shown is included in the report."`
+	Columns      []string `help:"Comma-separated list of top-level columns to show (e.g. --columns id,geometry).  By default, all columns are shown."`
+	Scan         bool     `help:"Sample the values of the geometry columns to detect their encoding (WKB, WKT, or hex WKB) and report columns where it does not match the metadata.  For a directory, the first file is sampled."`
 }
 
 const (
@@ -44,6 +50,7 @@ const (
 	ColAnnotation    = "Annotation"
 	ColRepetition    = "Repetition"
 	ColCompression   = "Compression"
+	ColSize          = "Size"
 	ColEncoding      = "Encoding"
 	ColGeometryTypes = "Geometry Types"
 	ColBounds        = "Bounds"
@@ -51,6 +58,10 @@ const (
 )
 
 func (c *DescribeCmd) Run() error {
+	if isDirectory(c.Input) {
+		return c.describeDataset()
+	}
+
 	input, inputErr := readerFromInput(c.Input)
 	if inputErr != nil {
 		return NewCommandError("trouble getting a reader from %q: %w", c.Input, inputErr)
@@ -58,6 +69,9 @@ func (c *DescribeCmd) Run() error {
 
 	fileReader, fileErr := file.NewParquetReader(input)
 	if fileErr != nil {
+		if closer, ok := input.(io.Closer); ok {
+			_ = closer.Close()
+		}
 		return fmt.Errorf("failed to read %q as parquet: %w", c.Input, fileErr)
 	}
 	defer fileReader.Close()
@@ -68,12 +82,36 @@ func (c *DescribeCmd) Run() error {
 			if errors.Is(err, geoparquet.ErrNoMetadata) {
 				return NewCommandError("missing %q metadata key", geoparquet.MetadataKey)
 			}
+			if errors.Is(err, geoparquet.ErrDuplicateMetadata) {
+				return NewCommandError("%s", duplicateMetadataMessage)
+			}
 			return err
 		}
 		fmt.Println(value)
 		return nil
 	}
 
+	info := newDescribeInfo(fileReader)
+	if c.Stats {
+		addColumnSizes([]*file.Reader{fileReader}, info.Schema, fileReader.MetaData().Schema.Root())
+	}
+	if c.Scan {
+		addDetectedEncodings(fileReader, info)
+	}
+	return c.format(info)
+}
+
+var duplicateMetadataMessage = fmt.Sprintf(
+	"Not a valid GeoParquet file (found more than one %q metadata key)."+
+		" This usually means that a tool appended new metadata to a file that already had it instead of replacing the existing key."+
+		" Rewrite the file with a single %q key (e.g. replace the schema metadata instead of adding to it).",
+	geoparquet.MetadataKey,
+	geoparquet.MetadataKey,
+)
+
+// newDescribeInfo builds the schema information and metadata for a Parquet
+// file.  Problems with the "geo" metadata are reported as issues.
+func newDescribeInfo(fileReader *file.Reader) *DescribeInfo {
 	fileMetadata := fileReader.MetaData()
 
 	info := &DescribeInfo{
@@ -91,6 +129,8 @@ func (c *DescribeCmd) Run() error {
 				geoparquet.MetadataKey,
 			)
 			info.Issues = append(info.Issues, message)
+		} else if errors.Is(geoErr, geoparquet.ErrDuplicateMetadata) {
+			info.Issues = append(info.Issues, duplicateMetadataMessage)
 		} else {
 			message := fmt.Sprintf(
 				"Not a valid GeoParquet file (invalid %q metadata)."+
@@ -105,6 +145,87 @@ func (c *DescribeCmd) Run() error {
 		info.Metadata = metadata
 	}
 
+	return info
+}
+
+// describeDataset describes a directory of GeoParquet part files as a single
+// dataset with combined metadata.
+func (c *DescribeCmd) describeDataset() error {
+	inputs, closeInputs, inputErr := readersFromDirectory(c.Input)
+	if inputErr != nil {
+		return NewCommandError("trouble reading the dataset in %q: %w", c.Input, inputErr)
+	}
+	defer closeInputs()
+
+	datasetReader, readerErr := geoparquet.NewDatasetReader(&geoparquet.DatasetConfig{Readers: inputs})
+	if readerErr != nil {
+		return NewCommandError("trouble reading the dataset in %q: %w", c.Input, readerErr)
+	}
+	defer datasetReader.Close()
+
+	if c.MetadataOnly {
+		value, err := datasetReader.Metadata().MarshalStable("")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(value))
+		return nil
+	}
+
+	info := &DescribeInfo{
+		Schema:       buildSchema(datasetReader.Parts()[0], "", datasetReader.Schema().Root()),
+		Metadata:     datasetReader.Metadata(),
+		NumRows:      datasetReader.NumRows(),
+		NumRowGroups: int64(datasetReader.NumRowGroups()),
+		NumFiles:     len(datasetReader.Parts()),
+	}
+	if c.Stats {
+		addColumnSizes(datasetReader.Parts(), info.Schema, datasetReader.Schema().Root())
+	}
+	if c.Scan {
+		addDetectedEncodings(datasetReader.Parts()[0], info)
+	}
+
+	return c.format(info)
+}
+
+// addDetectedEncodings samples the values of the geometry columns to detect
+// their encoding.  Columns with an encoding that does not match the metadata
+// are reported as issues.
+func addDetectedEncodings(fileReader *file.Reader, info *DescribeInfo) {
+	if info.Metadata == nil {
+		return
+	}
+	for _, field := range info.Schema.Fields {
+		geoColumn, ok := info.Metadata.Columns[field.Name]
+		if !ok {
+			continue
+		}
+		detected := geoparquet.DetectEncoding(fileReader, field.Name)
+		if detected == "" {
+			field.DetectedEncoding = unknownEncoding
+			continue
+		}
+		field.DetectedEncoding = detected
+		if !strings.EqualFold(detected, geoColumn.Encoding) {
+			message := fmt.Sprintf(
+				"The %q column has %q encoding in the metadata, but its values look like %s.",
+				field.Name, geoColumn.Encoding, detected,
+			)
+			info.Issues = append(info.Issues, message)
+		}
+	}
+}
+
+// unknownEncoding is reported for geometry columns without values that could
+// be decoded.
+const unknownEncoding = "unknown"
+
+func (c *DescribeCmd) format(info *DescribeInfo) error {
+	if err := c.selectColumns(info); err != nil {
+		return err
+	}
+
 	if c.Format == "json" {
 		err := c.formatJSON(info)
 		if err != nil {
@@ -120,10 +241,46 @@ func (c *DescribeCmd) Run() error {
 	return nil
 }
 
+// selectColumns limits the schema fields to the --columns and --max-columns
+// values.  The number of fields left out because of --max-columns is recorded.
+func (c *DescribeCmd) selectColumns(info *DescribeInfo) error {
+	if c.MaxColumns < 0 {
+		return NewCommandError("invalid --max-columns: must not be negative, got %d", c.MaxColumns)
+	}
+
+	fields := info.Schema.Fields
+	if len(c.Columns) > 0 {
+		byName := make(map[string]*DescribeSchema, len(fields))
+		for _, field := range fields {
+			byName[field.Name] = field
+		}
+		selected := make([]*DescribeSchema, 0, len(c.Columns))
+		for _, name := range c.Columns {
+			field, ok := byName[name]
+			if !ok {
+				return NewCommandError("invalid --columns: no column named %q", name)
+			}
+			selected = append(selected, field)
+		}
+		fields = selected
+	}
+
+	if c.MaxColumns > 0 && len(fields) > c.MaxColumns {
+		info.NumOmittedColumns = len(fields) - c.MaxColumns
+		fields = fields[:c.MaxColumns]
+	}
+
+	info.Schema.Fields = fields
+	return nil
+}
+
 func (c *DescribeCmd) formatText(info *DescribeInfo) error {
 	metadata := info.Metadata
 
 	header := table.Row{ColName, ColType, ColAnnotation, ColRepetition, ColCompression}
+	if c.Stats {
+		header = append(header, ColSize)
+	}
 	columnConfigs := []table.ColumnConfig{}
 	if metadata != nil {
 		header = append(header, ColEncoding, ColGeometryTypes, ColBounds, ColDetail)
@@ -166,6 +323,9 @@ func (c *DescribeCmd) formatText(info *DescribeInfo) error {
 			repetition = "0..1"
 		}
 		row := table.Row{name, field.Type, field.Annotation, repetition, field.Compression}
+		if c.Stats {
+			row = append(row, field.Size)
+		}
 		if metadata != nil {
 			geoColumn, ok := metadata.Columns[field.Name]
 			if !ok {
@@ -192,7 +352,11 @@ func (c *DescribeCmd) formatText(info *DescribeInfo) error {
 				if geoColumn.CRS != nil {
 					details.AppendRow(table.Row{"crs", geoColumn.CRS})
 				}
-				row = append(row, geoColumn.Encoding, types, bounds, details.Render())
+				encoding := geoColumn.Encoding
+				if field.DetectedEncoding != "" && !strings.EqualFold(field.DetectedEncoding, encoding) {
+					encoding = fmt.Sprintf("%s (detected %s)", encoding, field.DetectedEncoding)
+				}
+				row = append(row, encoding, types, bounds, details.Render())
 			}
 		}
 
@@ -200,8 +364,15 @@ func (c *DescribeCmd) formatText(info *DescribeInfo) error {
 	}
 
 	footerConfig := table.RowConfig{AutoMerge: true, AutoMergeAlign: text.AlignLeft}
+	if info.NumOmittedColumns > 0 {
+		more := fmt.Sprintf("and %d more", info.NumOmittedColumns)
+		tbl.AppendFooter(makeFooter("...", more, header), footerConfig)
+	}
 	tbl.AppendFooter(makeFooter("Rows", info.NumRows, header), footerConfig)
 	tbl.AppendFooter(makeFooter("Row Groups", info.NumRowGroups, header), footerConfig)
+	if info.NumFiles > 0 {
+		tbl.AppendFooter(makeFooter("Files", info.NumFiles, header), footerConfig)
+	}
 	if metadata != nil {
 		version := metadata.Version
 		if version == "" {
@@ -218,7 +389,7 @@ func (c *DescribeCmd) formatText(info *DescribeInfo) error {
 	tbl.Render()
 
 	for _, issue := range info.Issues {
-		fmt.Printf(" ⚠️  %s\n", issue)
+		logger.Warn("%s", issue)
 	}
 
 	return nil
@@ -250,7 +421,12 @@ type DescribeInfo struct {
 	Metadata     *geoparquet.Metadata `json:"metadata"`
 	NumRows      int64                `json:"rows"`
 	NumRowGroups int64                `json:"groups"`
+	NumFiles     int                  `json:"files,omitempty"`
 	Issues       []string             `json:"issues"`
+
+	// NumOmittedColumns is the number of columns left out of the schema
+	// because of the --max-columns limit.
+	NumOmittedColumns int `json:"omittedColumns,omitempty"`
 }
 
 type DescribeSchema struct {
@@ -260,7 +436,12 @@ type DescribeSchema struct {
 	Type        string            `json:"type,omitempty"`
 	Annotation  string            `json:"annotation,omitempty"`
 	Compression string            `json:"compression,omitempty"`
+	Size        string            `json:"size,omitempty"`
 	Fields      []*DescribeSchema `json:"fields,omitempty"`
+
+	// DetectedEncoding is the encoding detected from the values of a geometry
+	// column with --scan.
+	DetectedEncoding string `json:"detectedEncoding,omitempty"`
 }
 
 func getCompression(fileReader *file.Reader, node schema.Node) string {
@@ -282,12 +463,55 @@ func getCompression(fileReader *file.Reader, node schema.Node) string {
 	return strings.ToLower(col.Compression().String())
 }
 
+// addColumnSizes sets the total compressed size in bytes of the column chunks
+// for each field (summed across row groups in all files).  The size of a group
+// includes all of its leaf columns.  The size is "unknown" if there are no row
+// groups.
+func addColumnSizes(fileReaders []*file.Reader, field *DescribeSchema, node schema.Node) {
+	if node.Path() != "" {
+		field.Size = getColumnSize(fileReaders, node)
+	}
+	if group, ok := node.(*schema.GroupNode); ok {
+		for i := 0; i < group.NumFields() && i < len(field.Fields); i += 1 {
+			addColumnSizes(fileReaders, field.Fields[i], group.Field(i))
+		}
+	}
+}
+
+func getColumnSize(fileReaders []*file.Reader, node schema.Node) string {
+	path := node.Path()
+	total := int64(0)
+	numRowGroups := 0
+	for _, fileReader := range fileReaders {
+		fileMetadata := fileReader.MetaData()
+		indices := []int{}
+		for i := 0; i < fileMetadata.Schema.NumColumns(); i += 1 {
+			columnPath := fileMetadata.Schema.Column(i).Path()
+			if columnPath == path || strings.HasPrefix(columnPath, path+".") {
+				indices = append(indices, i)
+			}
+		}
+		for rowGroup := 0; rowGroup < fileReader.NumRowGroups(); rowGroup += 1 {
+			numRowGroups += 1
+			rowGroupMetadata := fileMetadata.RowGroup(rowGroup)
+			for _, i := range indices {
+				col, err := rowGroupMetadata.ColumnChunk(i)
+				if err != nil {
+					return "unknown"
+				}
+				total += col.TotalCompressedSize()
+			}
+		}
+	}
+	if numRowGroups == 0 {
+		return "unknown"
+	}
+	return strconv.FormatInt(total, 10)
+}
+
 func buildSchema(fileReader *file.Reader, name string, node schema.Node) *DescribeSchema {
-	annotation := ""
-	logicalType := node.LogicalType()
-	if !logicalType.IsNone() {
-		annotation = strings.ToLower(logicalType.String())
-	} else if _, isGroup := node.(*schema.GroupNode); isGroup {
+	annotation := pqutil.Annotation(node)
+	if _, isGroup := node.(*schema.GroupNode); isGroup && annotation == "" {
 		annotation = "group"
 	}
 
@@ -1,10 +1,19 @@
 package command_test
 
 import (
+	"bytes"
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
 
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
 	"github.com/apache/arrow/go/v16/parquet"
+	"github.com/apache/arrow/go/v16/parquet/pqarrow"
 	"github.com/planetlabs/gpq/cmd/gpq/command"
+	"github.com/planetlabs/gpq/internal/geoparquet"
 	"github.com/planetlabs/gpq/internal/test"
 )
 
@@ -173,6 +182,199 @@ func (s *Suite) TestDescribeMissingMetadata() {
 	s.Contains(info.Issues[0], "Not a valid GeoParquet file (missing the \"geo\" metadata key).")
 }
 
+func (s *Suite) TestDescribeDuplicateMetadata() {
+	cmd := &command.DescribeCmd{
+		Input:  "../../../internal/testdata/cases/example-duplicate-geo.parquet",
+		Format: "json",
+	}
+
+	s.Require().NoError(cmd.Run())
+
+	output := s.readStdout()
+	info := &command.DescribeInfo{}
+	err := json.Unmarshal(output, info)
+	s.Require().NoError(err)
+
+	s.Equal(int64(5), info.NumRows)
+	s.Require().Len(info.Issues, 1)
+	s.Contains(info.Issues[0], "Not a valid GeoParquet file (found more than one \"geo\" metadata key).")
+}
+
+func (s *Suite) TestDescribeDuplicateMetadataOnly() {
+	cmd := &command.DescribeCmd{
+		Input:        "../../../internal/testdata/cases/example-duplicate-geo.parquet",
+		Format:       "json",
+		MetadataOnly: true,
+	}
+
+	err := cmd.Run()
+	s.Require().ErrorContains(err, "found more than one \"geo\" metadata key")
+}
+
+func (s *Suite) TestDescribeMaxColumns() {
+	cmd := &command.DescribeCmd{
+		Input:      "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		Format:     "json",
+		MaxColumns: 2,
+	}
+
+	s.Require().NoError(cmd.Run())
+
+	info := &command.DescribeInfo{}
+	s.Require().NoError(json.Unmarshal(s.readStdout(), info))
+
+	s.Require().Len(info.Schema.Fields, 2)
+	s.Equal("geometry", info.Schema.Fields[0].Name)
+	s.Equal("pop_est", info.Schema.Fields[1].Name)
+	s.Equal(4, info.NumOmittedColumns)
+}
+
+func (s *Suite) TestDescribeMaxColumnsNegative() {
+	cmd := &command.DescribeCmd{
+		Input:      "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		Format:     "json",
+		MaxColumns: -1,
+	}
+
+	s.ErrorContains(cmd.Run(), "invalid --max-columns: must not be negative, got -1")
+}
+
+func (s *Suite) TestDescribeColumns() {
+	cmd := &command.DescribeCmd{
+		Input:   "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		Format:  "json",
+		Columns: []string{"name", "geometry"},
+	}
+
+	s.Require().NoError(cmd.Run())
+
+	info := &command.DescribeInfo{}
+	s.Require().NoError(json.Unmarshal(s.readStdout(), info))
+
+	s.Require().Len(info.Schema.Fields, 2)
+	s.Equal("name", info.Schema.Fields[0].Name)
+	s.Equal("geometry", info.Schema.Fields[1].Name)
+	s.Equal(0, info.NumOmittedColumns)
+}
+
+func (s *Suite) TestDescribeColumnsMissing() {
+	cmd := &command.DescribeCmd{
+		Input:   "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		Format:  "json",
+		Columns: []string{"nope"},
+	}
+
+	s.ErrorContains(cmd.Run(), `invalid --columns: no column named "nope"`)
+}
+
+func (s *Suite) TestDescribeDecimal() {
+	arrowSchema := arrow.NewSchema([]arrow.Field{
+		{Name: "price", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}, Nullable: true},
+	}, nil)
+
+	output := &bytes.Buffer{}
+	writer, err := pqarrow.NewFileWriter(arrowSchema, output, nil, pqarrow.DefaultWriterProps())
+	s.Require().NoError(err)
+	s.Require().NoError(writer.Close())
+	s.writeStdin(output.Bytes())
+
+	cmd := &command.DescribeCmd{
+		Format: "json",
+	}
+
+	s.Require().NoError(cmd.Run())
+
+	info := &command.DescribeInfo{}
+	s.Require().NoError(json.Unmarshal(s.readStdout(), info))
+
+	s.Require().Len(info.Schema.Fields, 1)
+	s.Equal("price", info.Schema.Fields[0].Name)
+	s.Equal("decimal(10,2)", info.Schema.Fields[0].Annotation)
+}
+
+func (s *Suite) TestDescribeScan() {
+	cmd := &command.DescribeCmd{
+		Input:  "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		Format: "json",
+		Scan:   true,
+	}
+
+	s.Require().NoError(cmd.Run())
+
+	info := &command.DescribeInfo{}
+	s.Require().NoError(json.Unmarshal(s.readStdout(), info))
+
+	s.Require().Len(info.Schema.Fields, 6)
+	s.Equal("geometry", info.Schema.Fields[0].Name)
+	s.Equal("WKB", info.Schema.Fields[0].DetectedEncoding)
+	s.Equal("", info.Schema.Fields[1].DetectedEncoding)
+	s.Empty(info.Issues)
+}
+
+// writeStringGeometries writes a file with string geometry values declared as
+// WKB in the metadata to stdin.
+func (s *Suite) writeStringGeometries(values []string) {
+	geoMetadata := `{"version":"1.0.0","primary_column":"geometry","columns":{"geometry":{"encoding":"WKB","geometry_types":[]}}}`
+	metadata := arrow.NewMetadata([]string{geoparquet.MetadataKey}, []string{geoMetadata})
+	arrowSchema := arrow.NewSchema([]arrow.Field{
+		{Name: "geometry", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, &metadata)
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, arrowSchema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).AppendValues(values, nil)
+	record := builder.NewRecord()
+	defer record.Release()
+
+	output := &bytes.Buffer{}
+	writer, err := pqarrow.NewFileWriter(arrowSchema, output, nil, pqarrow.DefaultWriterProps())
+	s.Require().NoError(err)
+	s.Require().NoError(writer.Write(record))
+	s.Require().NoError(writer.Close())
+	s.writeStdin(output.Bytes())
+}
+
+func (s *Suite) TestDescribeScanMismatch() {
+	s.writeStringGeometries([]string{"POINT (1 2)", "POINT (3 4)"})
+
+	cmd := &command.DescribeCmd{
+		Format: "json",
+		Scan:   true,
+	}
+
+	s.Require().NoError(cmd.Run())
+
+	info := &command.DescribeInfo{}
+	s.Require().NoError(json.Unmarshal(s.readStdout(), info))
+
+	s.Require().Len(info.Schema.Fields, 1)
+	s.Equal("WKT", info.Schema.Fields[0].DetectedEncoding)
+	s.Require().Len(info.Issues, 1)
+	s.Equal(`The "geometry" column has "WKB" encoding in the metadata, but its values look like WKT.`, info.Issues[0])
+}
+
+func (s *Suite) TestDescribeScanHexWKB() {
+	s.writeStringGeometries([]string{
+		"0101000000000000000000F03F0000000000000040",
+		"010100000000000000000008400000000000001040",
+	})
+
+	cmd := &command.DescribeCmd{
+		Format: "json",
+		Scan:   true,
+	}
+
+	s.Require().NoError(cmd.Run())
+
+	info := &command.DescribeInfo{}
+	s.Require().NoError(json.Unmarshal(s.readStdout(), info))
+
+	s.Require().Len(info.Schema.Fields, 1)
+	s.Equal("hex WKB", info.Schema.Fields[0].DetectedEncoding)
+	s.Require().Len(info.Issues, 1)
+	s.Equal(`The "geometry" column has "WKB" encoding in the metadata, but its values look like hex WKB.`, info.Issues[0])
+}
+
 func (s *Suite) TestDescribeFromUrl() {
 	cmd := &command.DescribeCmd{
 		Format: "json",
@@ -197,3 +399,151 @@ func (s *Suite) TestDescribeFromUrl() {
 
 	s.Len(info.Issues, 0)
 }
+
+func (s *Suite) TestDescribeSingularGeometryType() {
+	cmd := &command.DescribeCmd{
+		Input:  "../../../internal/testdata/cases/example-v0.4.0.parquet",
+		Format: "json",
+	}
+
+	s.Require().NoError(cmd.Run())
+
+	output := s.readStdout()
+	info := map[string]any{}
+	s.Require().NoError(json.Unmarshal(output, &info))
+
+	metadata, ok := info["metadata"].(map[string]any)
+	s.Require().True(ok)
+	columns, ok := metadata["columns"].(map[string]any)
+	s.Require().True(ok)
+	geometry, ok := columns["geometry"].(map[string]any)
+	s.Require().True(ok)
+
+	s.Equal([]any{"Polygon", "MultiPolygon"}, geometry["geometry_types"])
+	s.NotContains(geometry, "geometry_type")
+}
+
+func (s *Suite) TestDescribeDataset() {
+	cmd := &command.DescribeCmd{
+		Input:  s.newDataset(),
+		Format: "json",
+	}
+
+	s.Require().NoError(cmd.Run())
+
+	info := &command.DescribeInfo{}
+	s.Require().NoError(json.Unmarshal(s.readStdout(), info))
+
+	s.Equal(int64(10), info.NumRows)
+	s.Equal(int64(2), info.NumRowGroups)
+	s.Equal(2, info.NumFiles)
+	s.Len(info.Schema.Fields, 6)
+	s.Require().NotNil(info.Metadata)
+	s.Equal("geometry", info.Metadata.PrimaryColumn)
+}
+
+func (s *Suite) TestDescribeStats() {
+	cmd := &command.DescribeCmd{
+		Input:  "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		Format: "json",
+		Stats:  true,
+	}
+
+	s.Require().NoError(cmd.Run())
+
+	output := s.readStdout()
+	info := &command.DescribeInfo{}
+	s.Require().NoError(json.Unmarshal(output, info))
+
+	s.Require().Len(info.Schema.Fields, 6)
+	s.Equal("", info.Schema.Size)
+
+	sizes := map[string]int64{}
+	for _, field := range info.Schema.Fields {
+		size, err := strconv.ParseInt(field.Size, 10, 64)
+		s.Require().NoError(err, field.Name)
+		s.Greater(size, int64(0), field.Name)
+		sizes[field.Name] = size
+	}
+	for name, size := range sizes {
+		if name != "geometry" {
+			s.Greater(sizes["geometry"], size, name)
+		}
+	}
+}
+
+func (s *Suite) TestDescribeWithoutStats() {
+	cmd := &command.DescribeCmd{
+		Input:  "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		Format: "json",
+	}
+
+	s.Require().NoError(cmd.Run())
+
+	output := s.readStdout()
+	info := &command.DescribeInfo{}
+	s.Require().NoError(json.Unmarshal(output, info))
+
+	for _, field := range info.Schema.Fields {
+		s.Equal("", field.Size, field.Name)
+	}
+}
+
+func (s *Suite) TestDescribeFromPipe() {
+	data, err := os.ReadFile("../../../internal/testdata/cases/example-v1.0.0.parquet")
+	s.Require().NoError(err)
+
+	reader, writer, err := os.Pipe()
+	s.Require().NoError(err)
+	defer reader.Close()
+	os.Stdin = reader
+
+	go func() {
+		_, _ = writer.Write(data)
+		_ = writer.Close()
+	}()
+
+	spilled := filepath.Join(os.TempDir(), "gpq-stdin-*")
+	before, err := filepath.Glob(spilled)
+	s.Require().NoError(err)
+
+	cmd := &command.DescribeCmd{
+		Input:  "-",
+		Format: "json",
+	}
+	s.Require().NoError(cmd.Run())
+
+	info := &command.DescribeInfo{}
+	s.Require().NoError(json.Unmarshal(s.readStdout(), info))
+	s.Equal(int64(5), info.NumRows)
+
+	after, err := filepath.Glob(spilled)
+	s.Require().NoError(err)
+	s.Len(after, len(before), "the temporary file should be removed")
+}
+
+func (s *Suite) TestDescribeInvalidFromPipe() {
+	reader, writer, err := os.Pipe()
+	s.Require().NoError(err)
+	defer reader.Close()
+	os.Stdin = reader
+
+	go func() {
+		_, _ = writer.Write([]byte("not parquet"))
+		_ = writer.Close()
+	}()
+
+	spilled := filepath.Join(os.TempDir(), "gpq-stdin-*")
+	before, err := filepath.Glob(spilled)
+	s.Require().NoError(err)
+
+	cmd := &command.DescribeCmd{
+		Input:  "-",
+		Format: "json",
+	}
+	s.ErrorContains(cmd.Run(), "failed to read \"-\" as parquet")
+
+	after, err := filepath.Glob(spilled)
+	s.Require().NoError(err)
+	s.Len(after, len(before), "the temporary file should be removed")
+}
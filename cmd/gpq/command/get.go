@@ -0,0 +1,90 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/apache/arrow/go/v16/parquet/file"
+	orbjson "github.com/paulmach/orb/geojson"
+	"github.com/planetlabs/gpq/internal/geoparquet"
+)
+
+type GetCmd struct {
+	Input    string `arg:"" optional:"" name:"input" help:"Path or URL for a GeoParquet file.  If not provided, input is read from stdin."`
+	Row      int64  `help:"Zero-based index of the row to print.  Only the row group containing the row is read." required:""`
+	Format   string `help:"Output format.  Possible values: ${enum}.  The json format is an object with the column values (the primary geometry as a GeoJSON geometry)." enum:"geojson, json" default:"geojson"`
+	Unpretty bool   `help:"No newlines or indentation in the output."`
+}
+
+func (c *GetCmd) Run() error {
+	if isDirectory(c.Input) {
+		return NewCommandError("the get command does not support reading a directory")
+	}
+
+	input, inputErr := readerFromInput(c.Input)
+	if inputErr != nil {
+		return NewCommandError("trouble getting a reader from %q: %w", c.Input, inputErr)
+	}
+
+	fileReader, fileErr := file.NewParquetReader(input)
+	if fileErr != nil {
+		if closer, ok := input.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		return NewCommandError("failed to read %q as parquet: %w", c.Input, fileErr)
+	}
+	defer fileReader.Close()
+
+	metadata, metadataErr := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	if metadataErr != nil {
+		if errors.Is(metadataErr, geoparquet.ErrNoMetadata) {
+			return NewCommandError("missing %q metadata key", geoparquet.MetadataKey)
+		}
+		return NewCommandError("%w", metadataErr)
+	}
+
+	feature, err := geoparquet.ReadFeatureAt(fileReader, c.Row)
+	if err != nil {
+		return NewCommandError("%w", err)
+	}
+
+	var value any = feature
+	if c.Format == "json" {
+		row := map[string]any{}
+		for name, property := range feature.Properties {
+			row[name] = property
+		}
+		if feature.Geometry != nil {
+			row[metadata.PrimaryColumn] = orbjson.NewGeometry(feature.Geometry)
+		} else {
+			row[metadata.PrimaryColumn] = nil
+		}
+		value = row
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	if !c.Unpretty {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(value); err != nil {
+		return NewCommandError("failed to encode row %d: %w", c.Row, err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package command_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/planetlabs/gpq/cmd/gpq/command"
+)
+
+func (s *Suite) TestGet() {
+	cmd := &command.GetCmd{
+		Input:  "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		Row:    3,
+		Format: "geojson",
+	}
+
+	s.Require().NoError(cmd.Run())
+
+	feature := map[string]any{}
+	s.Require().NoError(json.Unmarshal(s.readStdout(), &feature))
+
+	s.Equal("Feature", feature["type"])
+	properties, ok := feature["properties"].(map[string]any)
+	s.Require().True(ok)
+	s.Equal("Canada", properties["name"])
+	s.NotContains(properties, "geometry")
+
+	geometry, ok := feature["geometry"].(map[string]any)
+	s.Require().True(ok)
+	s.Equal("MultiPolygon", geometry["type"])
+}
+
+func (s *Suite) TestGetJSON() {
+	cmd := &command.GetCmd{
+		Input:    "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		Row:      1,
+		Format:   "json",
+		Unpretty: true,
+	}
+
+	s.Require().NoError(cmd.Run())
+
+	row := map[string]any{}
+	s.Require().NoError(json.Unmarshal(s.readStdout(), &row))
+
+	s.Equal("Tanzania", row["name"])
+	s.NotContains(row, "type")
+	geometry, ok := row["geometry"].(map[string]any)
+	s.Require().True(ok)
+	s.Equal("Polygon", geometry["type"])
+}
+
+func (s *Suite) TestGetOutOfRange() {
+	cmd := &command.GetCmd{
+		Input:  "../../../internal/testdata/cases/example-v1.0.0.parquet",
+		Row:    5,
+		Format: "geojson",
+	}
+
+	s.ErrorContains(cmd.Run(), "feature index 5 is out of range, the file has 5 rows")
+}
+
+func (s *Suite) TestGetUnprettyNoHTMLEscape() {
+	input := filepath.Join(s.T().TempDir(), "input.parquet")
+	s.writeStdin([]byte(`{"type": "Feature", "geometry": {"type": "Point", "coordinates": [1, 2]}, "properties": {"name": "<Fish & Chips>"}}`))
+	convertCmd := &command.ConvertCmd{
+		From:       "geojson",
+		Output:     input,
+		Dictionary: true,
+	}
+	s.Require().NoError(convertCmd.Run())
+
+	cmd := &command.GetCmd{
+		Input:    input,
+		Row:      0,
+		Format:   "json",
+		Unpretty: true,
+	}
+	s.Require().NoError(cmd.Run())
+
+	s.Contains(string(s.readStdout()), `"name":"<Fish & Chips>"`)
+}
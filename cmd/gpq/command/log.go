@@ -0,0 +1,76 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Verbosity controls how much diagnostic output is written by commands.
+type Verbosity int
+
+const (
+	VerbosityQuiet Verbosity = iota
+	VerbosityNormal
+	VerbosityVerbose
+)
+
+// GetVerbosity returns the verbosity for the --quiet and --verbose flags.
+func GetVerbosity(quiet bool, verbose bool) Verbosity {
+	if quiet {
+		return VerbosityQuiet
+	}
+	if verbose {
+		return VerbosityVerbose
+	}
+	return VerbosityNormal
+}
+
+// Logger writes diagnostic messages (warnings and notes about what a command
+// is doing) to stderr.  Command output and reports are written to stdout and
+// are not affected by the verbosity.  Errors are always reported.
+type Logger struct {
+	out       io.Writer
+	verbosity Verbosity
+}
+
+func NewLogger(out io.Writer, verbosity Verbosity) *Logger {
+	return &Logger{out: out, verbosity: verbosity}
+}
+
+var logger = NewLogger(os.Stderr, VerbosityNormal)
+
+// SetLogger sets the logger used by all commands.
+func SetLogger(l *Logger) {
+	logger = l
+}
+
+// Warn writes a warning unless the verbosity is quiet.
+func (l *Logger) Warn(format string, a ...any) {
+	if l.verbosity < VerbosityNormal {
+		return
+	}
+	_, _ = fmt.Fprintf(l.out, "warning: "+format+"\n", a...)
+}
+
+// Debug writes a note only if the verbosity is verbose.
+func (l *Logger) Debug(format string, a ...any) {
+	if l.verbosity < VerbosityVerbose {
+		return
+	}
+	_, _ = fmt.Fprintf(l.out, format+"\n", a...)
+}
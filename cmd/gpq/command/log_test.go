@@ -0,0 +1,34 @@
+package command_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/planetlabs/gpq/cmd/gpq/command"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetVerbosity(t *testing.T) {
+	assert.Equal(t, command.VerbosityNormal, command.GetVerbosity(false, false))
+	assert.Equal(t, command.VerbosityQuiet, command.GetVerbosity(true, false))
+	assert.Equal(t, command.VerbosityVerbose, command.GetVerbosity(false, true))
+}
+
+func TestLogger(t *testing.T) {
+	cases := []struct {
+		verbosity command.Verbosity
+		expected  string
+	}{
+		{verbosity: command.VerbosityQuiet, expected: ""},
+		{verbosity: command.VerbosityNormal, expected: "warning: missing 1 thing\n"},
+		{verbosity: command.VerbosityVerbose, expected: "warning: missing 1 thing\nreading 2 things\n"},
+	}
+
+	for _, c := range cases {
+		out := &bytes.Buffer{}
+		logger := command.NewLogger(out, c.verbosity)
+		logger.Warn("missing %d thing", 1)
+		logger.Debug("reading %d things", 2)
+		assert.Equal(t, c.expected, out.String())
+	}
+}
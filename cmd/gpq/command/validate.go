@@ -15,10 +15,14 @@
 package command
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/alecthomas/kong"
@@ -27,37 +31,152 @@ import (
 )
 
 type ValidateCmd struct {
-	Input        string `arg:"" optional:"" name:"input" help:"Path or URL for a GeoParquet file.  If not provided, input is read from stdin."`
-	MetadataOnly bool   `help:"Only run rules that apply to file metadata and schema (no data will be scanned)."`
-	Unpretty     bool   `help:"No colors in text output, no newlines and indentation in JSON output."`
-	Format       string `help:"Report format.  Possible values: ${enum}." enum:"text, json" default:"text"`
+	Input        []string `arg:"" optional:"" name:"input" help:"Paths, directories, glob patterns, or URLs for GeoParquet files.  If not provided, input is read from stdin."`
+	MetadataOnly bool     `help:"Only run rules that apply to file metadata and schema (no data will be scanned)."`
+	Extended     bool     `help:"Also run the more expensive rules that check geometry validity (ring closure, degenerate rings, and self-intersection)."`
+	Unpretty     bool     `help:"No colors in text output, no newlines and indentation in JSON output."`
+	Format       string   `help:"Report format.  Possible values: ${enum}." enum:"text, json" default:"text"`
+	CountOnly    bool     `help:"Only print the number of passed, failed, and unrun checks."`
+	SchemaURL    string   `name:"schema-url" help:"Path or URL for the PROJJSON schema used to validate CRS metadata (use a local file to validate without network access)."`
+	NoNetwork    bool     `help:"Skip PROJJSON schema validation of CRS metadata so that no schema is fetched."`
+	Skip         []string `help:"Comma-separated list of rule IDs to skip (e.g. GeometryBounds,OptionalCRS).  Skipped rules do not cause validation to fail."`
+	Only         []string `help:"Comma-separated list of rule IDs to run (e.g. GeometryOrientation).  Other rules are left out of the report."`
+	ListRules    bool     `help:"List the available rules with their IDs and categories instead of validating."`
+	FailFast     bool     `help:"Report only the first failure of each data scanning rule and stop scanning at the first fatal failure (the default)." xor:"scan"`
+	CollectAll   bool     `help:"Keep scanning data after a data scanning rule fails and report the failing rows for each rule (up to --max-failures)." xor:"scan"`
+	MaxFailures  int      `help:"Maximum number of failing rows to report for each rule with --collect-all." default:"100"`
 }
 
-func (c *ValidateCmd) Run(ctx *kong.Context) error {
-	input, inputErr := readerFromInput(c.Input)
+var parquetFileSuffixes = append(append([]string{}, geoParquetSuffixes...), parquetSuffixes...)
+
+// expandInputs resolves glob patterns and directories to the list of files to
+// validate.  Directories are searched recursively for Parquet files.
+func expandInputs(inputs []string) ([]string, error) {
+	expanded := []string{}
+	for _, input := range inputs {
+		if u, err := url.Parse(input); err == nil && u.Scheme != "" {
+			expanded = append(expanded, input)
+			continue
+		}
+
+		if strings.ContainsAny(input, "*?[") {
+			matches, err := filepath.Glob(input)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", input, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %q", input)
+			}
+			expanded = append(expanded, matches...)
+			continue
+		}
+
+		info, err := os.Stat(input)
+		if err != nil || !info.IsDir() {
+			expanded = append(expanded, input)
+			continue
+		}
+
+		walkErr := filepath.WalkDir(input, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && slices.Contains(parquetFileSuffixes, filepath.Ext(path)) {
+				expanded = append(expanded, path)
+			}
+			return nil
+		})
+		if walkErr != nil {
+			return nil, fmt.Errorf("trouble reading directory %q: %w", input, walkErr)
+		}
+	}
+	return expanded, nil
+}
+
+func (c *ValidateCmd) newValidator() *validator.Validator {
+	return validator.NewFromConfig(&validator.Config{
+		MetadataOnly:   c.MetadataOnly,
+		Extended:       c.Extended,
+		ProjJSONSchema: c.SchemaURL,
+		NoNetwork:      c.NoNetwork,
+		Skip:           c.Skip,
+		Only:           c.Only,
+		CollectAll:     c.CollectAll,
+		MaxFailures:    c.MaxFailures,
+	})
+}
+
+func (c *ValidateCmd) validate(v *validator.Validator, inputSource string) (*validator.Report, error) {
+	input, inputErr := readerFromInput(inputSource)
 	if inputErr != nil {
-		return NewCommandError("trouble getting a reader from %q: %w", c.Input, inputErr)
+		return nil, fmt.Errorf("trouble getting a reader from %q: %w", inputSource, inputErr)
+	}
+	if closer, ok := input.(io.Closer); ok {
+		defer closer.Close()
 	}
 
-	inputName := c.Input
-	if inputName == "" {
+	inputName := inputSource
+	if inputName == "" || inputName == stdinInput {
 		inputName = "<stdin>"
 	}
-	v := validator.New(c.MetadataOnly)
-	report, err := v.Validate(context.Background(), input, inputName)
+	report, err := v.Validate(commandContext, input, inputName)
 	if err != nil {
-		return NewCommandError("validation failed: %w", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
 	}
+	return report, nil
+}
 
-	valid := true
+func isValid(report *validator.Report) bool {
 	for _, check := range report.Checks {
-		if !check.Passed {
-			valid = false
-			break
+		if !check.Passed && !check.Skipped {
+			return false
 		}
 	}
+	return true
+}
 
-	if c.Format == "json" {
+func (c *ValidateCmd) Run(ctx *kong.Context) error {
+	if c.ListRules {
+		if err := c.formatRules(validator.ListRules()); err != nil {
+			return NewCommandError("unable to list rules: %w", err)
+		}
+		return nil
+	}
+
+	if err := validator.ValidateRuleIDs(c.Skip); err != nil {
+		return NewCommandError("invalid --skip: %w", err)
+	}
+	if err := validator.ValidateRuleIDs(c.Only); err != nil {
+		return NewCommandError("invalid --only: %w", err)
+	}
+
+	inputSource := ""
+	if len(c.Input) > 0 {
+		inputs, expandErr := expandInputs(c.Input)
+		if expandErr != nil {
+			return NewCommandError("%w", expandErr)
+		}
+		if len(inputs) == 0 {
+			return NewCommandError("no Parquet files found in %s", strings.Join(c.Input, ", "))
+		}
+		if len(c.Input) > 1 || len(inputs) > 1 || inputs[0] != c.Input[0] {
+			return c.runMultiple(ctx, inputs)
+		}
+		inputSource = inputs[0]
+	}
+
+	report, err := c.validate(c.newValidator(), inputSource)
+	if err != nil {
+		return NewCommandError("%w", err)
+	}
+
+	valid := isValid(report)
+
+	if c.CountOnly {
+		if err := c.formatCounts(report); err != nil {
+			return NewCommandError("unable to format counts: %w", err)
+		}
+	} else if c.Format == "json" {
 		if err := c.formatJSON(report); err != nil {
 			return NewCommandError("unable to format report as json: %w", err)
 		}
@@ -73,6 +192,79 @@ func (c *ValidateCmd) Run(ctx *kong.Context) error {
 	return nil
 }
 
+// FileReport is the validation result for one of multiple inputs.
+type FileReport struct {
+	Input  string            `json:"input"`
+	Valid  bool              `json:"valid"`
+	Error  string            `json:"error,omitempty"`
+	Report *validator.Report `json:"report,omitempty"`
+}
+
+// MultipleReport is the JSON report when validating multiple inputs.
+type MultipleReport struct {
+	Valid bool          `json:"valid"`
+	Files []*FileReport `json:"files"`
+}
+
+func (c *ValidateCmd) runMultiple(ctx *kong.Context, inputs []string) error {
+	v := c.newValidator()
+	multiple := &MultipleReport{Valid: true, Files: make([]*FileReport, len(inputs))}
+	for i, input := range inputs {
+		fileReport := &FileReport{Input: input}
+		report, err := c.validate(v, input)
+		if err != nil {
+			fileReport.Error = err.Error()
+		} else {
+			fileReport.Report = report
+			fileReport.Valid = isValid(report)
+		}
+		if !fileReport.Valid {
+			multiple.Valid = false
+		}
+		multiple.Files[i] = fileReport
+	}
+
+	if c.CountOnly {
+		if err := c.formatMultipleCounts(multiple); err != nil {
+			return NewCommandError("unable to format counts: %w", err)
+		}
+	} else if c.Format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		if !c.Unpretty {
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+		}
+		if err := encoder.Encode(multiple); err != nil {
+			return NewCommandError("unable to format report as json: %w", err)
+		}
+	} else {
+		c.formatMultipleText(multiple)
+	}
+
+	if !multiple.Valid {
+		ctx.Kong.Exit(1)
+	}
+	return nil
+}
+
+func (c *ValidateCmd) formatRules(rules []*validator.RuleInfo) error {
+	if c.Format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		if !c.Unpretty {
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+		}
+		return encoder.Encode(rules)
+	}
+
+	for _, rule := range rules {
+		if _, err := fmt.Printf("%-24s %-14s %s\n", rule.ID, rule.Category, rule.Title); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *ValidateCmd) formatJSON(report *validator.Report) error {
 	encoder := json.NewEncoder(os.Stdout)
 	if !c.Unpretty {
@@ -83,19 +275,72 @@ func (c *ValidateCmd) formatJSON(report *validator.Report) error {
 	return encoder.Encode(report)
 }
 
-func (c *ValidateCmd) formatText(report *validator.Report) error {
-	passed := 0
-	failed := 0
-	unrun := 0
+type CheckCounts struct {
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Unrun   int `json:"unrun"`
+	Skipped int `json:"skipped,omitempty"`
+}
+
+func countChecks(report *validator.Report) *CheckCounts {
+	counts := &CheckCounts{}
 	for _, check := range report.Checks {
-		if !check.Run {
-			unrun++
+		if check.Skipped {
+			counts.Skipped++
+		} else if !check.Run {
+			counts.Unrun++
 		} else if check.Passed {
-			passed++
+			counts.Passed++
 		} else {
-			failed++
+			counts.Failed++
+		}
+	}
+	return counts
+}
+
+func (c *ValidateCmd) formatCounts(report *validator.Report) error {
+	return c.writeCounts(countChecks(report))
+}
+
+func (c *ValidateCmd) formatMultipleCounts(multiple *MultipleReport) error {
+	counts := &CheckCounts{}
+	for _, fileReport := range multiple.Files {
+		if fileReport.Report == nil {
+			continue
 		}
+		fileCounts := countChecks(fileReport.Report)
+		counts.Passed += fileCounts.Passed
+		counts.Failed += fileCounts.Failed
+		counts.Unrun += fileCounts.Unrun
+		counts.Skipped += fileCounts.Skipped
 	}
+	return c.writeCounts(counts)
+}
+
+func (c *ValidateCmd) writeCounts(counts *CheckCounts) error {
+	if c.Format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		if !c.Unpretty {
+			encoder.SetIndent("", "  ")
+		}
+		return encoder.Encode(counts)
+	}
+
+	if _, err := fmt.Printf("passed: %d\nfailed: %d\nunrun: %d\n", counts.Passed, counts.Failed, counts.Unrun); err != nil {
+		return err
+	}
+	if counts.Skipped > 0 {
+		if _, err := fmt.Printf("skipped: %d\n", counts.Skipped); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func summarizeCounts(counts *CheckCounts) string {
+	passed := counts.Passed
+	failed := counts.Failed
+	unrun := counts.Unrun
 
 	summaries := []string{
 		fmt.Sprintf("Passed %d check%s", passed, maybeS(passed)),
@@ -106,12 +351,52 @@ func (c *ValidateCmd) formatText(report *validator.Report) error {
 	if unrun > 0 {
 		summaries = append(summaries, fmt.Sprintf("%d check%s not run", unrun, maybeS(unrun)))
 	}
+	if counts.Skipped > 0 {
+		summaries = append(summaries, fmt.Sprintf("%d check%s skipped", counts.Skipped, maybeS(counts.Skipped)))
+	}
+	return strings.Join(summaries, ", ")
+}
 
+func (c *ValidateCmd) formatMultipleText(multiple *MultipleReport) {
 	if c.Unpretty {
 		color.NoColor = true
 	}
 
-	fmt.Printf("\nSummary: %s.\n\n", strings.Join(summaries, ", "))
+	passPrefix := " ✓"
+	failPrefix := " ✗"
+	reasonPrefix := "   ↳"
+	numValid := 0
+	fmt.Println()
+	for _, fileReport := range multiple.Files {
+		if fileReport.Report == nil {
+			color.Red("%s %s", failPrefix, fileReport.Input)
+			color.Red("%s %s", reasonPrefix, fileReport.Error)
+			continue
+		}
+		summary := summarizeCounts(countChecks(fileReport.Report))
+		if fileReport.Valid {
+			numValid += 1
+			color.Green("%s %s (%s)", passPrefix, fileReport.Input, summary)
+			continue
+		}
+		color.Red("%s %s (%s)", failPrefix, fileReport.Input, summary)
+		for _, check := range fileReport.Report.Checks {
+			if check.Run && !check.Passed {
+				color.Red("%s %s", reasonPrefix, check.Message)
+			}
+		}
+	}
+
+	numFiles := len(multiple.Files)
+	fmt.Printf("\nSummary: %d of %d file%s passed validation.\n\n", numValid, numFiles, maybeS(numFiles))
+}
+
+func (c *ValidateCmd) formatText(report *validator.Report) error {
+	if c.Unpretty {
+		color.NoColor = true
+	}
+
+	fmt.Printf("\nSummary: %s.\n\n", summarizeCounts(countChecks(report)))
 	if report.MetadataOnly {
 		skipped := len(validator.DataScanningRules())
 		color.Yellow("Metadata and schema checks only.  Skipped %d data scanning check%s.\n\n", skipped, maybeS(skipped))
@@ -120,21 +405,44 @@ func (c *ValidateCmd) formatText(report *validator.Report) error {
 	passPrefix := " ✓"
 	failPrefix := " ✗"
 	unrunPrefix := " !"
+	skipPrefix := " -"
 	reasonPrefix := "   ↳"
 	for _, check := range report.Checks {
+		if check.Skipped {
+			color.Yellow("%s %s", skipPrefix, check.Title)
+			color.Yellow("%s skipped (%s)", reasonPrefix, check.ID)
+			continue
+		}
+
 		if !check.Run {
+			reason := "not checked"
+			if check.Message != "" {
+				reason = check.Message
+			}
 			color.Yellow("%s %s", unrunPrefix, check.Title)
-			color.Yellow("%s %s", reasonPrefix, "not checked")
+			color.Yellow("%s %s", reasonPrefix, reason)
 			continue
 		}
 
 		if check.Passed {
 			color.Green("%s %s", passPrefix, check.Title)
+			if check.Message != "" {
+				color.Green("%s %s", reasonPrefix, check.Message)
+			}
 			continue
 		}
 
 		color.Red("%s %s", failPrefix, check.Title)
-		color.Red("%s %s", reasonPrefix, check.Message)
+		if len(check.Failures) == 0 {
+			color.Red("%s %s", reasonPrefix, check.Message)
+			continue
+		}
+		for _, failure := range check.Failures {
+			color.Red("%s %s", reasonPrefix, failure)
+		}
+		if omitted := check.NumFailures - len(check.Failures); omitted > 0 {
+			color.Red("%s %d more failure%s not shown", reasonPrefix, omitted, maybeS(omitted))
+		}
 	}
 	fmt.Println()
 
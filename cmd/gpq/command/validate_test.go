@@ -0,0 +1,240 @@
+package command_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/planetlabs/gpq/cmd/gpq/command"
+	"github.com/planetlabs/gpq/internal/validator"
+)
+
+func (s *Suite) TestValidateCountOnly() {
+	cmd := &command.ValidateCmd{
+		Input:     []string{"../../../internal/testdata/cases/example-v1.0.0.parquet"},
+		Format:    "json",
+		CountOnly: true,
+	}
+
+	s.Require().NoError(cmd.Run(nil))
+
+	output := s.readStdout()
+	counts := &command.CheckCounts{}
+	s.Require().NoError(json.Unmarshal(output, counts))
+
+	s.Equal(21, counts.Passed)
+	s.Equal(0, counts.Failed)
+	s.Equal(0, counts.Unrun)
+}
+
+func (s *Suite) TestValidateCountOnlyText() {
+	cmd := &command.ValidateCmd{
+		Input:        []string{"../../../internal/testdata/cases/example-v1.0.0.parquet"},
+		Format:       "text",
+		MetadataOnly: true,
+		CountOnly:    true,
+	}
+
+	s.Require().NoError(cmd.Run(nil))
+
+	output := s.readStdout()
+	s.Equal("passed: 16\nfailed: 0\nunrun: 0\n", string(output))
+}
+
+func (s *Suite) TestValidateMultiple() {
+	cmd := &command.ValidateCmd{
+		Input: []string{
+			"../../../internal/testdata/cases/example-v1.0.0.parquet",
+			"../../../internal/testdata/cases/example-v1.0.0-beta.1.parquet",
+		},
+		Format:       "json",
+		MetadataOnly: true,
+		NoNetwork:    true,
+	}
+
+	s.Require().NoError(cmd.Run(nil))
+
+	output := s.readStdout()
+	report := &command.MultipleReport{}
+	s.Require().NoError(json.Unmarshal(output, report))
+
+	s.True(report.Valid)
+	s.Require().Len(report.Files, 2)
+	s.Equal("../../../internal/testdata/cases/example-v1.0.0.parquet", report.Files[0].Input)
+	s.True(report.Files[0].Valid)
+	s.Equal("../../../internal/testdata/cases/example-v1.0.0-beta.1.parquet", report.Files[1].Input)
+	s.True(report.Files[1].Valid)
+}
+
+func (s *Suite) TestValidateGlob() {
+	cmd := &command.ValidateCmd{
+		Input:        []string{"../../../internal/testdata/cases/example-v1.0.0*.parquet"},
+		MetadataOnly: true,
+		CountOnly:    true,
+		NoNetwork:    true,
+	}
+
+	s.Require().NoError(cmd.Run(nil))
+
+	output := s.readStdout()
+	s.Equal("passed: 32\nfailed: 0\nunrun: 0\n", string(output))
+}
+
+func (s *Suite) TestValidateDirectory() {
+	dir := s.T().TempDir()
+	data, err := os.ReadFile("../../../internal/testdata/cases/example-v1.0.0.parquet")
+	s.Require().NoError(err)
+	s.Require().NoError(os.MkdirAll(filepath.Join(dir, "tiles", "0"), 0755))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "tiles", "0", "a.parquet"), data, 0644))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "tiles", "0", "b.parquet"), data, 0644))
+	s.Require().NoError(os.WriteFile(filepath.Join(dir, "tiles", "readme.txt"), []byte("not parquet"), 0644))
+
+	cmd := &command.ValidateCmd{
+		Input:        []string{filepath.Join(dir, "tiles")},
+		Format:       "json",
+		MetadataOnly: true,
+	}
+
+	s.Require().NoError(cmd.Run(nil))
+
+	output := s.readStdout()
+	report := &command.MultipleReport{}
+	s.Require().NoError(json.Unmarshal(output, report))
+
+	s.True(report.Valid)
+	s.Require().Len(report.Files, 2)
+	s.Equal(filepath.Join(dir, "tiles", "0", "a.parquet"), report.Files[0].Input)
+	s.Equal(filepath.Join(dir, "tiles", "0", "b.parquet"), report.Files[1].Input)
+}
+
+func (s *Suite) TestValidateGlobNoMatches() {
+	cmd := &command.ValidateCmd{
+		Input: []string{"../../../internal/testdata/cases/*.nothing"},
+	}
+
+	s.ErrorContains(cmd.Run(nil), `no files match "../../../internal/testdata/cases/*.nothing"`)
+}
+
+func (s *Suite) TestValidateSkip() {
+	cmd := &command.ValidateCmd{
+		Input:     []string{"../../../internal/testdata/cases/example-v1.0.0.parquet"},
+		Format:    "json",
+		CountOnly: true,
+		Skip:      []string{"GeometryBounds", "OptionalCRS"},
+	}
+
+	s.Require().NoError(cmd.Run(nil))
+
+	output := s.readStdout()
+	counts := &command.CheckCounts{}
+	s.Require().NoError(json.Unmarshal(output, counts))
+
+	s.Equal(19, counts.Passed)
+	s.Equal(0, counts.Failed)
+	s.Equal(0, counts.Unrun)
+	s.Equal(2, counts.Skipped)
+}
+
+func (s *Suite) TestValidateSkipUnknownRule() {
+	cmd := &command.ValidateCmd{
+		Input: []string{"../../../internal/testdata/cases/example-v1.0.0.parquet"},
+		Skip:  []string{"GeometryBoundz"},
+	}
+
+	s.ErrorContains(cmd.Run(nil), `invalid --skip: unknown rule "GeometryBoundz"`)
+}
+
+func (s *Suite) TestValidateOnly() {
+	cmd := &command.ValidateCmd{
+		Input:  []string{"../../../internal/testdata/cases/example-v1.0.0.parquet"},
+		Format: "json",
+		Only:   []string{"RequiredVersion", "GeometryTypes"},
+	}
+
+	s.Require().NoError(cmd.Run(nil))
+
+	output := s.readStdout()
+	report := &validator.Report{}
+	s.Require().NoError(json.Unmarshal(output, report))
+
+	s.Require().Len(report.Checks, 2)
+	s.Equal("RequiredVersion", report.Checks[0].ID)
+	s.Equal("GeometryTypes", report.Checks[1].ID)
+	s.True(report.Checks[0].Passed)
+	s.True(report.Checks[1].Passed)
+}
+
+func (s *Suite) TestValidateOnlyUnknownRule() {
+	cmd := &command.ValidateCmd{
+		Input: []string{"../../../internal/testdata/cases/example-v1.0.0.parquet"},
+		Only:  []string{"Orientation"},
+	}
+
+	s.ErrorContains(cmd.Run(nil), `invalid --only: unknown rule "Orientation"`)
+}
+
+func (s *Suite) TestValidateListRules() {
+	cmd := &command.ValidateCmd{
+		Format:    "json",
+		ListRules: true,
+	}
+
+	s.Require().NoError(cmd.Run(nil))
+
+	output := s.readStdout()
+	rules := []*validator.RuleInfo{}
+	s.Require().NoError(json.Unmarshal(output, &rules))
+	s.Equal(validator.ListRules(), rules)
+}
+
+func (s *Suite) TestValidateListRulesText() {
+	cmd := &command.ValidateCmd{
+		Format:    "text",
+		ListRules: true,
+	}
+
+	s.Require().NoError(cmd.Run(nil))
+
+	output := string(s.readStdout())
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	s.Len(lines, len(validator.ListRules()))
+	s.True(strings.HasPrefix(lines[0], "RequiredGeoKey           metadata       file must include"), lines[0])
+}
+
+func (s *Suite) TestValidateCollectAll() {
+	cmd := &command.ValidateCmd{
+		Input:       []string{"../../../internal/testdata/cases/example-v1.0.0.parquet"},
+		Format:      "json",
+		CollectAll:  true,
+		MaxFailures: 10,
+	}
+
+	s.Require().NoError(cmd.Run(nil))
+
+	output := s.readStdout()
+	report := &validator.Report{}
+	s.Require().NoError(json.Unmarshal(output, report))
+
+	for _, check := range report.Checks {
+		s.True(check.Passed, check.ID)
+		s.Empty(check.Failures, check.ID)
+	}
+}
+
+func (s *Suite) TestValidateCanceled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	command.SetContext(ctx)
+	defer command.SetContext(context.Background())
+
+	cmd := &command.ValidateCmd{
+		Input:     []string{"../../../internal/testdata/cases/example-v1.0.0.parquet"},
+		NoNetwork: true,
+	}
+
+	err := cmd.Run(nil)
+	s.ErrorIs(err, context.Canceled)
+	s.ErrorContains(err, "validation failed")
+}
@@ -14,19 +14,38 @@
 
 package command
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+)
 
 type VersionCmd struct {
 	Detail bool `help:"Include detail about the commit and build date."`
+	JSON   bool `help:"Print the version, commit, build date, and Go version as JSON."`
 }
 
 type VersionInfo struct {
-	Version string
-	Commit  string
-	Date    string
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+}
+
+type versionReport struct {
+	*VersionInfo
+	GoVersion string `json:"goVersion"`
 }
 
 func (c *VersionCmd) Run(info *VersionInfo) error {
+	if c.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(&versionReport{VersionInfo: info, GoVersion: runtime.Version()}); err != nil {
+			return NewCommandError("failed to format version as json: %w", err)
+		}
+		return nil
+	}
 	output := info.Version
 	if c.Detail {
 		output = fmt.Sprintf("%s (%s %s)", output, info.Commit, info.Date)
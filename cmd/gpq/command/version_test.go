@@ -0,0 +1,36 @@
+package command_test
+
+import (
+	"encoding/json"
+	"runtime"
+
+	"github.com/planetlabs/gpq/cmd/gpq/command"
+)
+
+var testVersionInfo = &command.VersionInfo{Version: "1.2.3", Commit: "abc123", Date: "2024-01-02T03:04:05Z"}
+
+func (s *Suite) TestVersion() {
+	cmd := &command.VersionCmd{}
+	s.Require().NoError(cmd.Run(testVersionInfo))
+	s.Equal("1.2.3\n", string(s.readStdout()))
+}
+
+func (s *Suite) TestVersionDetail() {
+	cmd := &command.VersionCmd{Detail: true}
+	s.Require().NoError(cmd.Run(testVersionInfo))
+	s.Equal("1.2.3 (abc123 2024-01-02T03:04:05Z)\n", string(s.readStdout()))
+}
+
+func (s *Suite) TestVersionJSON() {
+	cmd := &command.VersionCmd{JSON: true}
+	s.Require().NoError(cmd.Run(testVersionInfo))
+
+	output := map[string]string{}
+	s.Require().NoError(json.Unmarshal(s.readStdout(), &output))
+	s.Equal(map[string]string{
+		"version":   "1.2.3",
+		"commit":    "abc123",
+		"date":      "2024-01-02T03:04:05Z",
+		"goVersion": runtime.Version(),
+	}, output)
+}
@@ -15,7 +15,10 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os"
 
 	"github.com/alecthomas/kong"
 	"github.com/planetlabs/gpq/cmd/gpq/command"
@@ -29,6 +32,12 @@ var (
 
 func main() {
 	ctx := kong.Parse(&command.CLI)
+	command.SetLogger(command.NewLogger(os.Stderr, command.GetVerbosity(command.CLI.Quiet, command.CLI.Verbose)))
+	if command.CLI.Timeout > 0 {
+		timeoutCtx, cancel := context.WithTimeout(context.Background(), command.CLI.Timeout)
+		defer cancel()
+		command.SetContext(timeoutCtx)
+	}
 	err := ctx.Run(ctx, &command.VersionInfo{Version: version, Commit: commit, Date: date})
 	if err == nil {
 		return
@@ -37,5 +46,8 @@ func main() {
 	if errors.As(err, &commandError) {
 		err = commandError
 	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("stopped after the %s timeout: %w", command.CLI.Timeout, err)
+	}
 	ctx.FatalIfErrorf(err)
 }
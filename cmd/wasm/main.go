@@ -59,7 +59,7 @@ var fromParquet = js.FuncOf(func(this js.Value, args []js.Value) any {
 	js.CopyBytesToGo(data, args[0])
 
 	output := &bytes.Buffer{}
-	convertErr := geojson.FromParquet(bytes.NewReader(data), output)
+	convertErr := geojson.FromParquet(bytes.NewReader(data), output, nil)
 	if convertErr != nil {
 		return returnFromError(convertErr)
 	}
@@ -83,6 +83,32 @@ var fromParquet = js.FuncOf(func(this js.Value, args []js.Value) any {
 	})
 })
 
+var metadata = js.FuncOf(func(this js.Value, args []js.Value) any {
+	if len(args) != 1 {
+		return returnFromErrorMessage("Must be called with a single argument")
+	}
+	if !args[0].InstanceOf(uint8ArrayConstructor) {
+		return returnFromErrorMessage("Must be called with a Uint8Array")
+	}
+
+	numBytes := args[0].Length()
+	data := make([]byte, numBytes)
+	js.CopyBytesToGo(data, args[0])
+
+	reader, readerErr := file.NewParquetReader(bytes.NewReader(data))
+	if readerErr != nil {
+		return returnFromError(readerErr)
+	}
+	defer reader.Close()
+
+	value, metadataErr := geoparquet.GetMetadataValue(reader.MetaData().KeyValueMetadata())
+	if metadataErr != nil {
+		return returnFromError(metadataErr)
+	}
+
+	return returnFromValue(value)
+})
+
 var toParquet = js.FuncOf(func(this js.Value, args []js.Value) any {
 	if len(args) != 1 {
 		return returnFromErrorMessage("Must be called with a single argument")
@@ -126,6 +152,7 @@ func main() {
 	exports := map[string]interface{}{
 		"fromParquet": fromParquet,
 		"toParquet":   toParquet,
+		"metadata":    metadata,
 	}
 	js.Global().Get("Go").Set("exports", exports)
 	<-make(chan struct{})
@@ -1,13 +1,17 @@
 package geo
 
 import (
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"sync"
 
 	"github.com/paulmach/orb"
-	"github.com/paulmach/orb/encoding/wkb"
+	"github.com/paulmach/orb/encoding/ewkb"
 	"github.com/paulmach/orb/encoding/wkt"
 	orbjson "github.com/paulmach/orb/geojson"
 )
@@ -34,23 +38,87 @@ type Feature struct {
 	Type       string         `json:"type"`
 	Geometry   orb.Geometry   `json:"geometry"`
 	Properties map[string]any `json:"properties"`
+
+	// Bbox holds the "bbox" member of the feature.  It is read from GeoJSON
+	// (invalid values are ignored) and written when not empty.  Use
+	// GeometryBbox or a FeatureEncoder to compute a bbox from the geometry.
+	Bbox []float64 `json:"bbox,omitempty"`
+
+	// Dimensions describes the number of values in the positions of the
+	// geometry as read from GeoJSON.  The geometry itself only has X and Y.
+	Dimensions CoordinateDimensions `json:"-"`
+
+	// ForeignMembers holds any members of the Feature object other than the
+	// ones defined by GeoJSON.  These are only read when requested.
+	ForeignMembers map[string]any `json:"-"`
 }
 
+// FeatureMembers are the Feature members defined by GeoJSON.  Other members
+// are foreign members.
+var FeatureMembers = []string{"type", "id", "geometry", "properties", "bbox"}
+
 var (
 	_ json.Marshaler   = (*Feature)(nil)
 	_ json.Unmarshaler = (*Feature)(nil)
 )
 
+// A Feature has its geometry in a "geometry" member and a bare Geometry object
+// has "coordinates".  These errors are returned for objects that mix the two.
+var (
+	ErrGeometryAndCoordinates = errors.New("found both geometry and coordinates members (a Feature must use geometry and a Geometry must use coordinates)")
+	ErrFeatureCoordinates     = errors.New("found coordinates in a Feature (a Feature must use a geometry member)")
+	ErrMissingGeometryType    = errors.New("found coordinates without a geometry type")
+)
+
 func (f *Feature) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.members(f.Bbox))
+}
+
+// FeatureEncoder encodes features as GeoJSON with options that MarshalJSON
+// does not take.
+type FeatureEncoder struct {
+	// ComputeBbox writes a "bbox" member computed from the geometry for
+	// features without a stored Bbox.
+	ComputeBbox bool
+}
+
+// Marshal returns the GeoJSON encoding of the feature.
+func (e *FeatureEncoder) Marshal(f *Feature) ([]byte, error) {
+	bbox := f.Bbox
+	if len(bbox) == 0 && e.ComputeBbox {
+		bbox = GeometryBbox(f.Geometry)
+	}
+	return json.Marshal(f.members(bbox))
+}
+
+func (f *Feature) members(bbox []float64) map[string]any {
 	m := map[string]any{
 		"type":       "Feature",
 		"geometry":   orbjson.NewGeometry(f.Geometry),
 		"properties": f.Properties,
 	}
+	for key, value := range f.ForeignMembers {
+		if !slices.Contains(FeatureMembers, key) {
+			m[key] = value
+		}
+	}
 	if f.Id != nil {
 		m["id"] = f.Id
 	}
-	return json.Marshal(m)
+	if len(bbox) > 0 {
+		m["bbox"] = bbox
+	}
+	return m
+}
+
+// GeometryBbox returns the [minx, miny, maxx, maxy] bounds of a geometry for
+// use as a GeoJSON "bbox" member.  It returns nil for nil or empty geometries.
+func GeometryBbox(geometry orb.Geometry) []float64 {
+	if geometry == nil || IsEmpty(geometry) {
+		return nil
+	}
+	bounds := geometry.Bound()
+	return []float64{bounds.Left(), bounds.Bottom(), bounds.Right(), bounds.Top()}
 }
 
 type jsonFeature struct {
@@ -58,6 +126,9 @@ type jsonFeature struct {
 	Type       string          `json:"type"`
 	Geometry   json.RawMessage `json:"geometry"`
 	Properties map[string]any  `json:"properties"`
+	Bbox       json.RawMessage `json:"bbox"`
+
+	Coordinates json.RawMessage `json:"coordinates"`
 }
 
 var rawNull = json.RawMessage([]byte("null"))
@@ -84,26 +155,274 @@ func (f *Feature) UnmarshalJSON(data []byte) error {
 	f.Id = jf.Id
 	f.Properties = jf.Properties
 
+	if jf.Bbox != nil {
+		var bbox []float64
+		if json.Unmarshal(jf.Bbox, &bbox) == nil && len(bbox) > 0 {
+			f.Bbox = bbox
+		}
+	}
+
+	if jf.Coordinates != nil {
+		if jf.Geometry != nil {
+			return ErrGeometryAndCoordinates
+		}
+		if jf.Type == "Feature" {
+			return ErrFeatureCoordinates
+		}
+	}
+
 	if isRawNull(jf.Geometry) {
 		return nil
 	}
-	geometry := &orbjson.Geometry{}
-	if err := json.Unmarshal(jf.Geometry, geometry); err != nil {
+	geometry, dimensions, err := DecodeGeoJSONGeometry(jf.Geometry)
+	if err != nil {
 		return err
 	}
 
-	f.Geometry = geometry.Geometry()
+	f.Geometry = geometry
+	f.Dimensions = dimensions
 	return nil
 }
 
+// CoordinateDimensions records the smallest and largest number of values found
+// in the positions of a GeoJSON geometry.  The zero value means that no
+// positions have been found.
+type CoordinateDimensions struct {
+	Min int
+	Max int
+}
+
+// Mixed returns true if positions with and without a Z value were found.
+func (d CoordinateDimensions) Mixed() bool {
+	return d.Min > 0 && d.Min < 3 && d.Max >= 3
+}
+
+// Add includes the dimensions from other.
+func (d *CoordinateDimensions) Add(other CoordinateDimensions) {
+	if other.Min > 0 && (d.Min == 0 || other.Min < d.Min) {
+		d.Min = other.Min
+	}
+	if other.Max > d.Max {
+		d.Max = other.Max
+	}
+}
+
+func (d *CoordinateDimensions) point(position []float64) (orb.Point, error) {
+	if len(position) < 2 {
+		return orb.Point{}, fmt.Errorf("expected a position with at least 2 values, got %d", len(position))
+	}
+	d.Add(CoordinateDimensions{Min: len(position), Max: len(position)})
+	return orb.Point{position[0], position[1]}, nil
+}
+
+func (d *CoordinateDimensions) points(positions [][]float64) ([]orb.Point, error) {
+	points := make([]orb.Point, len(positions))
+	for i, position := range positions {
+		point, err := d.point(position)
+		if err != nil {
+			return nil, err
+		}
+		points[i] = point
+	}
+	return points, nil
+}
+
+func (d *CoordinateDimensions) rings(positions [][][]float64) ([]orb.Ring, error) {
+	rings := make([]orb.Ring, len(positions))
+	for i, ring := range positions {
+		points, err := d.points(ring)
+		if err != nil {
+			return nil, err
+		}
+		rings[i] = points
+	}
+	return rings, nil
+}
+
+type jsonGeometry struct {
+	Type        string            `json:"type"`
+	Coordinates json.RawMessage   `json:"coordinates"`
+	Geometries  []json.RawMessage `json:"geometries"`
+}
+
+// DecodeGeoJSONGeometry decodes a GeoJSON geometry and returns it along with the
+// smallest and largest number of values in its positions (including members
+// of geometry collections).  Values beyond X and Y are not included in the
+// returned geometry.  A null geometry is returned as nil and a Point with
+// empty coordinates is returned as an empty point.  Other positions with fewer
+// than 2 values are an error.
+func DecodeGeoJSONGeometry(data json.RawMessage) (orb.Geometry, CoordinateDimensions, error) {
+	dimensions := CoordinateDimensions{}
+	if isRawNull(data) {
+		return nil, dimensions, nil
+	}
+	geometry, err := dimensions.decode(data)
+	if err != nil {
+		return nil, dimensions, err
+	}
+	return geometry, dimensions, nil
+}
+
+func (d *CoordinateDimensions) decode(data json.RawMessage) (orb.Geometry, error) {
+	jg := &jsonGeometry{}
+	if err := json.Unmarshal(data, jg); err != nil {
+		return nil, err
+	}
+
+	switch jg.Type {
+	case "Point":
+		var position []float64
+		if err := json.Unmarshal(jg.Coordinates, &position); err != nil {
+			return nil, err
+		}
+		if len(position) == 0 {
+			// an empty Point, as written for POINT EMPTY
+			return orb.Point{math.NaN(), math.NaN()}, nil
+		}
+		return d.point(position)
+	case "MultiPoint":
+		var positions [][]float64
+		if err := json.Unmarshal(jg.Coordinates, &positions); err != nil {
+			return nil, err
+		}
+		points, err := d.points(positions)
+		if err != nil {
+			return nil, err
+		}
+		return orb.MultiPoint(points), nil
+	case "LineString":
+		var positions [][]float64
+		if err := json.Unmarshal(jg.Coordinates, &positions); err != nil {
+			return nil, err
+		}
+		points, err := d.points(positions)
+		if err != nil {
+			return nil, err
+		}
+		return orb.LineString(points), nil
+	case "MultiLineString":
+		var positions [][][]float64
+		if err := json.Unmarshal(jg.Coordinates, &positions); err != nil {
+			return nil, err
+		}
+		multiLineString := make(orb.MultiLineString, len(positions))
+		for i, lineString := range positions {
+			points, err := d.points(lineString)
+			if err != nil {
+				return nil, err
+			}
+			multiLineString[i] = points
+		}
+		return multiLineString, nil
+	case "Polygon":
+		var positions [][][]float64
+		if err := json.Unmarshal(jg.Coordinates, &positions); err != nil {
+			return nil, err
+		}
+		rings, err := d.rings(positions)
+		if err != nil {
+			return nil, err
+		}
+		return orb.Polygon(rings), nil
+	case "MultiPolygon":
+		var positions [][][][]float64
+		if err := json.Unmarshal(jg.Coordinates, &positions); err != nil {
+			return nil, err
+		}
+		multiPolygon := make(orb.MultiPolygon, len(positions))
+		for i, polygon := range positions {
+			rings, err := d.rings(polygon)
+			if err != nil {
+				return nil, err
+			}
+			multiPolygon[i] = rings
+		}
+		return multiPolygon, nil
+	case "GeometryCollection":
+		collection := make(orb.Collection, 0, len(jg.Geometries))
+		for _, member := range jg.Geometries {
+			geometry, err := d.decode(member)
+			if err != nil {
+				return nil, err
+			}
+			collection = append(collection, geometry)
+		}
+		return collection, nil
+	}
+	return nil, orbjson.ErrInvalidGeometry
+}
+
 const (
 	EncodingWKB = "WKB"
 	EncodingWKT = "WKT"
 )
 
 func DecodeGeometry(value any, encoding string) (*orbjson.Geometry, error) {
+	geometry, _, err := DecodeGeometryWithSRID(value, encoding)
+	return geometry, err
+}
+
+// IsHexWKB returns true if a string looks like hex-encoded WKB (as written by
+// DuckDB and some other tools).  The string must have an even number of hex
+// digits and start with a valid byte order marker.  WKT strings never match
+// because they start with a geometry type name.
+func IsHexWKB(str string) bool {
+	// a byte order marker and a geometry type are required
+	if len(str) < 10 || len(str)%2 != 0 {
+		return false
+	}
+	if str[:2] != "00" && str[:2] != "01" {
+		return false
+	}
+	for _, c := range str {
+		if !('0' <= c && c <= '9') && !('a' <= c && c <= 'f') && !('A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// flags set in the geometry type of EWKB (PostGIS extended WKB)
+const (
+	ewkbZFlag    = 0x80000000
+	ewkbMFlag    = 0x40000000
+	ewkbSRIDFlag = 0x20000000
+)
+
+// IsEWKB returns true if WKB data has the flags for an SRID or for Z or M
+// values that PostGIS sets in the geometry type.  This extended WKB is not the
+// standard (ISO) WKB required by GeoParquet.
+func IsEWKB(data []byte) bool {
+	if len(data) < 5 {
+		return false
+	}
+	var geometryType uint32
+	switch data[0] {
+	case 0:
+		geometryType = binary.BigEndian.Uint32(data[1:5])
+	case 1:
+		geometryType = binary.LittleEndian.Uint32(data[1:5])
+	default:
+		return false
+	}
+	return geometryType&(ewkbZFlag|ewkbMFlag|ewkbSRIDFlag) != 0
+}
+
+// DecodeGeometryWithSRID decodes a geometry value and also returns the SRID
+// embedded in EWKB values (PostGIS extended WKB).  The SRID is zero if
+// the value does not include one.  String values that look like hex-encoded
+// WKB are decoded as WKB with either encoding.
+func DecodeGeometryWithSRID(value any, encoding string) (*orbjson.Geometry, int, error) {
 	if value == nil {
-		return nil, nil
+		return nil, 0, nil
+	}
+	if str, ok := value.(string); ok && (encoding == "" || encoding == EncodingWKB || encoding == EncodingWKT) && IsHexWKB(str) {
+		data, err := hex.DecodeString(str)
+		if err != nil {
+			return nil, 0, err
+		}
+		value = data
+		encoding = EncodingWKB
 	}
 	if encoding == "" {
 		if _, ok := value.([]byte); ok {
@@ -115,29 +434,30 @@ func DecodeGeometry(value any, encoding string) (*orbjson.Geometry, error) {
 	if encoding == EncodingWKB {
 		data, ok := value.([]byte)
 		if !ok {
-			return nil, fmt.Errorf("expected bytes for wkb geometry, got %T", value)
+			return nil, 0, fmt.Errorf("expected bytes for wkb geometry, got %T", value)
 		}
 		if len(data) == 0 {
-			return nil, nil
+			return nil, 0, nil
 		}
-		g, err := wkb.Unmarshal(data)
+		// the ewkb decoder also handles standard WKB (without an SRID)
+		g, srid, err := ewkb.Unmarshal(data)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
-		return orbjson.NewGeometry(g), nil
+		return orbjson.NewGeometry(g), srid, nil
 	}
 	if encoding == EncodingWKT {
 		str, ok := value.(string)
 		if !ok {
-			return nil, fmt.Errorf("expected string for wkt geometry, got %T", value)
+			return nil, 0, fmt.Errorf("expected string for wkt geometry, got %T", value)
 		}
 		g, err := wkt.Unmarshal(str)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
-		return orbjson.NewGeometry(g), nil
+		return orbjson.NewGeometry(g), 0, nil
 	}
-	return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+	return nil, 0, fmt.Errorf("unsupported encoding: %s", encoding)
 }
 
 type GeometryStats struct {
@@ -243,6 +563,25 @@ func (i *GeometryStats) Types() []string {
 	return types
 }
 
+// Reset clears the accumulated bounds and types so the stats can be reused.
+func (i *GeometryStats) Reset() {
+	i.writeLock()
+	i.types = map[string]bool{}
+	i.minX = math.MaxFloat64
+	i.maxX = -math.MaxFloat64
+	i.minY = math.MaxFloat64
+	i.maxY = -math.MaxFloat64
+	i.writeUnlock()
+}
+
+// Merge adds the bounds and types from other to these stats.
+func (i *GeometryStats) Merge(other *GeometryStats) {
+	bounds := other.Bounds()
+	types := other.Types()
+	i.AddBounds(bounds)
+	i.AddTypes(types)
+}
+
 type DatasetStats struct {
 	mutex       *sync.RWMutex
 	collections map[string]*GeometryStats
@@ -334,3 +673,125 @@ func (i *DatasetStats) Types(name string) []string {
 	i.readUnlock()
 	return collection.Types()
 }
+
+// Reset clears the accumulated stats for all collections.  The collections
+// themselves are retained.
+func (i *DatasetStats) Reset() {
+	i.readLock()
+	for _, collection := range i.collections {
+		collection.Reset()
+	}
+	i.readUnlock()
+}
+
+// Merge adds the stats for all collections in other to this dataset.
+// Collections that are not yet present are added.
+func (i *DatasetStats) Merge(other *DatasetStats) {
+	other.readLock()
+	collections := make(map[string]*GeometryStats, len(other.collections))
+	for name, collection := range other.collections {
+		collections[name] = collection
+	}
+	other.readUnlock()
+
+	for name, collection := range collections {
+		if !i.HasCollection(name) {
+			i.AddCollection(name)
+		}
+		i.readLock()
+		target := i.collections[name]
+		i.readUnlock()
+		target.Merge(collection)
+	}
+}
+
+// IsEmpty returns true if a geometry has no positions.  A collection is empty
+// if all of its members are empty.  An empty point is encoded in WKB with NaN
+// coordinates.  The bounds of empty geometries should not be included in the
+// bounds of a column.
+func IsEmpty(geometry orb.Geometry) bool {
+	switch g := geometry.(type) {
+	case orb.Point:
+		return math.IsNaN(g[0]) || math.IsNaN(g[1])
+	case orb.MultiPoint:
+		return len(g) == 0
+	case orb.LineString:
+		return len(g) == 0
+	case orb.MultiLineString:
+		return len(g) == 0
+	case orb.Ring:
+		return len(g) == 0
+	case orb.Polygon:
+		return len(g) == 0
+	case orb.MultiPolygon:
+		return len(g) == 0
+	case orb.Collection:
+		for _, member := range g {
+			if !IsEmpty(member) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// MaxPrecision is the largest number of decimal places supported when
+// rounding coordinates.  Larger values would scale coordinates beyond the
+// range where float64 values are exact integers.
+const MaxPrecision = 10
+
+// ValidatePrecision returns an error if the number of decimal places used to
+// round coordinates is not supported.  Nil means no rounding and zero means
+// rounding to integers.
+func ValidatePrecision(precision *int) error {
+	if precision == nil {
+		return nil
+	}
+	if *precision < 0 || *precision > MaxPrecision {
+		return fmt.Errorf("precision must be between 0 and %d, got %d", MaxPrecision, *precision)
+	}
+	return nil
+}
+
+// RoundGeometry rounds the coordinates of a geometry to the given number of
+// decimal places (zero rounds to integers).  The coordinates of the geometry
+// are modified in place.
+func RoundGeometry(geometry orb.Geometry, precision int) orb.Geometry {
+	return orb.Round(geometry, int(math.Pow10(precision)))
+}
+
+// RingOrientation returns the orientation expected for a polygon ring by RFC
+// 7946 and the GeoParquet "counterclockwise" orientation.  The exterior ring
+// (index 0) is counterclockwise and interior rings are clockwise.
+func RingOrientation(ringIndex int) orb.Orientation {
+	if ringIndex == 0 {
+		return orb.CCW
+	}
+	return orb.CW
+}
+
+// Rewind reverses polygon rings as needed so that they follow the orientation
+// returned by RingOrientation.  Geometries other than polygons, multipolygons,
+// and collections are returned as they are.  The rings of the geometry are
+// modified in place.
+func Rewind(geometry orb.Geometry) orb.Geometry {
+	switch g := geometry.(type) {
+	case orb.Polygon:
+		for i, ring := range g {
+			orientation := ring.Orientation()
+			if orientation != 0 && orientation != RingOrientation(i) {
+				ring.Reverse()
+			}
+		}
+	case orb.MultiPolygon:
+		for _, polygon := range g {
+			Rewind(polygon)
+		}
+	case orb.Collection:
+		for _, member := range g {
+			Rewind(member)
+		}
+	}
+	return geometry
+}
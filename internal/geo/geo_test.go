@@ -0,0 +1,405 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo_test
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/ewkb"
+	"github.com/paulmach/orb/encoding/wkb"
+	orbjson "github.com/paulmach/orb/geojson"
+	"github.com/planetlabs/gpq/internal/geo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeGeometryEWKB(t *testing.T) {
+	data, err := ewkb.Marshal(orb.Point{1, 2}, 4326)
+	require.NoError(t, err)
+
+	geometry, decodeErr := geo.DecodeGeometry(data, geo.EncodingWKB)
+	require.NoError(t, decodeErr)
+	assert.Equal(t, orb.Point{1, 2}, geometry.Geometry())
+
+	geometry, srid, decodeErr := geo.DecodeGeometryWithSRID(data, "")
+	require.NoError(t, decodeErr)
+	assert.Equal(t, orb.Point{1, 2}, geometry.Geometry())
+	assert.Equal(t, 4326, srid)
+}
+
+func TestDecodeGeometryWKBWithoutSRID(t *testing.T) {
+	data, err := wkb.Marshal(orb.LineString{{1, 2}, {3, 4}})
+	require.NoError(t, err)
+
+	geometry, srid, decodeErr := geo.DecodeGeometryWithSRID(data, geo.EncodingWKB)
+	require.NoError(t, decodeErr)
+	assert.Equal(t, orb.LineString{{1, 2}, {3, 4}}, geometry.Geometry())
+	assert.Equal(t, 0, srid)
+}
+
+func TestDecodeGeometryWKT(t *testing.T) {
+	geometry, srid, decodeErr := geo.DecodeGeometryWithSRID("POINT (1 2)", geo.EncodingWKT)
+	require.NoError(t, decodeErr)
+	assert.Equal(t, orb.Point{1, 2}, geometry.Geometry())
+	assert.Equal(t, 0, srid)
+}
+
+func TestDecodeGeometryHexWKB(t *testing.T) {
+	data, err := wkb.Marshal(orb.LineString{{1, 2}, {3, 4}})
+	require.NoError(t, err)
+	lower := hex.EncodeToString(data)
+	upper := strings.ToUpper(lower)
+
+	for _, encoding := range []string{geo.EncodingWKB, geo.EncodingWKT, ""} {
+		for _, value := range []string{lower, upper} {
+			geometry, decodeErr := geo.DecodeGeometry(value, encoding)
+			require.NoError(t, decodeErr, encoding)
+			assert.Equal(t, orb.LineString{{1, 2}, {3, 4}}, geometry.Geometry(), encoding)
+		}
+	}
+
+	ewkbData, err := ewkb.Marshal(orb.Point{1, 2}, 4326)
+	require.NoError(t, err)
+	geometry, srid, decodeErr := geo.DecodeGeometryWithSRID(hex.EncodeToString(ewkbData), geo.EncodingWKB)
+	require.NoError(t, decodeErr)
+	assert.Equal(t, orb.Point{1, 2}, geometry.Geometry())
+	assert.Equal(t, 4326, srid)
+}
+
+func TestIsHexWKB(t *testing.T) {
+	data, err := wkb.Marshal(orb.Point{1, 2})
+	require.NoError(t, err)
+
+	assert.True(t, geo.IsHexWKB(hex.EncodeToString(data)))
+	assert.False(t, geo.IsHexWKB("POINT (1 2)"))
+	assert.False(t, geo.IsHexWKB("0101"))
+	assert.False(t, geo.IsHexWKB(hex.EncodeToString(data)[1:]))
+	assert.False(t, geo.IsHexWKB("02"+hex.EncodeToString(data)[2:]))
+	assert.False(t, geo.IsHexWKB(hex.EncodeToString(data)[:20]+"zz"))
+}
+
+func TestGeometryStatsMerge(t *testing.T) {
+	a := geo.NewGeometryStats(false)
+	a.AddBounds(&orb.Bound{Min: orb.Point{0, 0}, Max: orb.Point{10, 5}})
+	a.AddType("Point")
+
+	b := geo.NewGeometryStats(true)
+	b.AddBounds(&orb.Bound{Min: orb.Point{-5, 2}, Max: orb.Point{3, 20}})
+	b.AddTypes([]string{"Point", "Polygon"})
+
+	a.Merge(b)
+	assert.Equal(t, &orb.Bound{Min: orb.Point{-5, 0}, Max: orb.Point{10, 20}}, a.Bounds())
+	assert.ElementsMatch(t, []string{"Point", "Polygon"}, a.Types())
+
+	a.Merge(geo.NewGeometryStats(false))
+	assert.Equal(t, &orb.Bound{Min: orb.Point{-5, 0}, Max: orb.Point{10, 20}}, a.Bounds())
+	assert.ElementsMatch(t, []string{"Point", "Polygon"}, a.Types())
+}
+
+func TestGeometryStatsReset(t *testing.T) {
+	stats := geo.NewGeometryStats(false)
+	stats.AddBounds(&orb.Bound{Min: orb.Point{0, 0}, Max: orb.Point{10, 5}})
+	stats.AddType("Point")
+
+	stats.Reset()
+	assert.Empty(t, stats.Types())
+
+	stats.AddBounds(&orb.Bound{Min: orb.Point{1, 1}, Max: orb.Point{2, 2}})
+	assert.Equal(t, &orb.Bound{Min: orb.Point{1, 1}, Max: orb.Point{2, 2}}, stats.Bounds())
+}
+
+func TestDatasetStatsMerge(t *testing.T) {
+	a := geo.NewDatasetStats(false)
+	a.AddCollection("geometry")
+	a.AddBounds("geometry", &orb.Bound{Min: orb.Point{0, 0}, Max: orb.Point{1, 1}})
+	a.AddTypes("geometry", []string{"Point"})
+
+	b := geo.NewDatasetStats(true)
+	b.AddCollection("geometry")
+	b.AddBounds("geometry", &orb.Bound{Min: orb.Point{-1, -1}, Max: orb.Point{0.5, 0.5}})
+	b.AddTypes("geometry", []string{"LineString"})
+	b.AddCollection("other")
+	b.AddBounds("other", &orb.Bound{Min: orb.Point{5, 5}, Max: orb.Point{6, 6}})
+	b.AddTypes("other", []string{"Polygon"})
+
+	a.Merge(b)
+	assert.Equal(t, 2, a.NumCollections())
+	assert.Equal(t, &orb.Bound{Min: orb.Point{-1, -1}, Max: orb.Point{1, 1}}, a.Bounds("geometry"))
+	assert.ElementsMatch(t, []string{"Point", "LineString"}, a.Types("geometry"))
+	assert.Equal(t, &orb.Bound{Min: orb.Point{5, 5}, Max: orb.Point{6, 6}}, a.Bounds("other"))
+	assert.Equal(t, []string{"Polygon"}, a.Types("other"))
+
+	a.Reset()
+	assert.Equal(t, 2, a.NumCollections())
+	assert.Empty(t, a.Types("geometry"))
+	assert.Empty(t, a.Types("other"))
+}
+
+func TestDecodeGeoJSONGeometry(t *testing.T) {
+	cases := []struct {
+		geometry   string
+		expected   orb.Geometry
+		dimensions geo.CoordinateDimensions
+		mixed      bool
+	}{
+		{geometry: `null`, expected: nil, dimensions: geo.CoordinateDimensions{}},
+		{geometry: `{"type": "Point", "coordinates": [1, 2]}`, expected: orb.Point{1, 2}, dimensions: geo.CoordinateDimensions{Min: 2, Max: 2}},
+		{geometry: `{"type": "Point", "coordinates": [1, 2, 3]}`, expected: orb.Point{1, 2}, dimensions: geo.CoordinateDimensions{Min: 3, Max: 3}},
+		{
+			geometry:   `{"type": "LineString", "coordinates": [[1, 2], [3, 4, 5]]}`,
+			expected:   orb.LineString{{1, 2}, {3, 4}},
+			dimensions: geo.CoordinateDimensions{Min: 2, Max: 3},
+			mixed:      true,
+		},
+		{
+			geometry:   `{"type": "MultiPolygon", "coordinates": [[[[0, 0], [1, 0], [1, 1], [0, 0]]]]}`,
+			expected:   orb.MultiPolygon{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}},
+			dimensions: geo.CoordinateDimensions{Min: 2, Max: 2},
+		},
+		{
+			geometry:   `{"type": "GeometryCollection", "geometries": [{"type": "Point", "coordinates": [1, 2]}, {"type": "Polygon", "coordinates": [[[0, 0, 1], [1, 0, 1], [1, 1, 1], [0, 0, 1]]]}]}`,
+			expected:   orb.Collection{orb.Point{1, 2}, orb.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}},
+			dimensions: geo.CoordinateDimensions{Min: 2, Max: 3},
+			mixed:      true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.geometry, func(t *testing.T) {
+			geometry, dimensions, err := geo.DecodeGeoJSONGeometry([]byte(c.geometry))
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, geometry)
+			assert.Equal(t, c.dimensions, dimensions)
+			assert.Equal(t, c.mixed, dimensions.Mixed())
+		})
+	}
+}
+
+func TestDecodeGeoJSONGeometryInvalid(t *testing.T) {
+	_, _, err := geo.DecodeGeoJSONGeometry([]byte(`{"type": "Curve", "coordinates": [1, 2]}`))
+	assert.ErrorIs(t, err, orbjson.ErrInvalidGeometry)
+
+	_, _, err = geo.DecodeGeoJSONGeometry([]byte(`{"type": "Point", "coordinates": "nope"}`))
+	assert.Error(t, err)
+
+	for _, geometry := range []string{
+		`{"type": "Point", "coordinates": [1]}`,
+		`{"type": "LineString", "coordinates": [[1, 2], [3]]}`,
+		`{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [], [0, 0]]]}`,
+		`{"type": "GeometryCollection", "geometries": [{"type": "MultiPoint", "coordinates": [[1, 2], []]}]}`,
+	} {
+		_, _, err := geo.DecodeGeoJSONGeometry([]byte(geometry))
+		assert.ErrorContains(t, err, "expected a position with at least 2 values", geometry)
+	}
+}
+
+func TestDecodeGeoJSONGeometryEmptyPoint(t *testing.T) {
+	geometry, dimensions, err := geo.DecodeGeoJSONGeometry([]byte(`{"type": "Point", "coordinates": []}`))
+	require.NoError(t, err)
+	assert.True(t, geo.IsEmpty(geometry))
+	assert.Equal(t, geo.CoordinateDimensions{}, dimensions)
+}
+
+func TestIsEmpty(t *testing.T) {
+	cases := []struct {
+		name     string
+		geometry orb.Geometry
+		empty    bool
+	}{
+		{name: "point", geometry: orb.Point{1, 2}, empty: false},
+		{name: "origin", geometry: orb.Point{0, 0}, empty: false},
+		{name: "nan point", geometry: orb.Point{math.NaN(), math.NaN()}, empty: true},
+		{name: "empty polygon", geometry: orb.Polygon{}, empty: true},
+		{name: "empty multipolygon", geometry: orb.MultiPolygon{}, empty: true},
+		{name: "empty collection", geometry: orb.Collection{orb.LineString{}}, empty: true},
+		{name: "collection", geometry: orb.Collection{orb.LineString{}, orb.Point{1, 2}}, empty: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.empty, geo.IsEmpty(c.geometry))
+		})
+	}
+}
+
+func TestRoundGeometry(t *testing.T) {
+	polygon := orb.Polygon{{{1.23456, 2.34567}, {3.45678, 2.34567}, {3.45678, 4.56789}, {1.23456, 2.34567}}}
+	rounded := geo.RoundGeometry(polygon, 2)
+	assert.Equal(t, orb.Polygon{{{1.23, 2.35}, {3.46, 2.35}, {3.46, 4.57}, {1.23, 2.35}}}, rounded)
+
+	assert.Equal(t, orb.Point{-123, 46}, geo.RoundGeometry(orb.Point{-122.6, 45.5}, 0))
+}
+
+func TestRoundGeometryMaxPrecision(t *testing.T) {
+	rounded := geo.RoundGeometry(orb.Point{-122.123456789012, 45.123456789012}, geo.MaxPrecision)
+	assert.InDelta(t, -122.1234567890, rounded.(orb.Point).X(), 1e-12)
+	assert.InDelta(t, 45.1234567890, rounded.(orb.Point).Y(), 1e-12)
+}
+
+func TestValidatePrecision(t *testing.T) {
+	precision := func(value int) *int {
+		return &value
+	}
+	assert.NoError(t, geo.ValidatePrecision(nil))
+	assert.NoError(t, geo.ValidatePrecision(precision(0)))
+	assert.NoError(t, geo.ValidatePrecision(precision(6)))
+	assert.NoError(t, geo.ValidatePrecision(precision(geo.MaxPrecision)))
+	assert.ErrorContains(t, geo.ValidatePrecision(precision(-1)), "precision must be between 0 and 10")
+	assert.ErrorContains(t, geo.ValidatePrecision(precision(geo.MaxPrecision+1)), "precision must be between 0 and 10")
+}
+
+func TestRewind(t *testing.T) {
+	ccw := orb.Ring{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	cw := orb.Ring{{0, 0}, {0, 10}, {10, 10}, {10, 0}, {0, 0}}
+	holeCW := orb.Ring{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}}
+	holeCCW := orb.Ring{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}}
+
+	cases := []struct {
+		name     string
+		geometry orb.Geometry
+		expected orb.Geometry
+	}{
+		{
+			name:     "correct polygon",
+			geometry: orb.Polygon{ccw.Clone(), holeCW.Clone()},
+			expected: orb.Polygon{ccw, holeCW},
+		},
+		{
+			name:     "reversed polygon",
+			geometry: orb.Polygon{cw.Clone(), holeCCW.Clone()},
+			expected: orb.Polygon{ccw, holeCW},
+		},
+		{
+			name:     "multipolygon",
+			geometry: orb.MultiPolygon{{cw.Clone()}, {ccw.Clone()}},
+			expected: orb.MultiPolygon{{ccw}, {ccw}},
+		},
+		{
+			name:     "collection",
+			geometry: orb.Collection{orb.Point{1, 2}, orb.Polygon{cw.Clone()}},
+			expected: orb.Collection{orb.Point{1, 2}, orb.Polygon{ccw}},
+		},
+		{
+			name:     "line string",
+			geometry: orb.LineString(cw.Clone()),
+			expected: orb.LineString(cw),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, geo.Rewind(c.geometry))
+		})
+	}
+}
+
+func TestGeometryBbox(t *testing.T) {
+	line := orb.LineString{{-1, 2}, {3, -4}, {5, 6}}
+	assert.Equal(t, []float64{-1, -4, 5, 6}, geo.GeometryBbox(line))
+	assert.Equal(t, []float64{1, 2, 1, 2}, geo.GeometryBbox(orb.Point{1, 2}))
+	assert.Nil(t, geo.GeometryBbox(orb.Polygon{}))
+	assert.Nil(t, geo.GeometryBbox(nil))
+}
+
+func TestFeatureMarshalBbox(t *testing.T) {
+	line := orb.LineString{{-1, 2}, {3, -4}, {5, 6}}
+
+	feature := &geo.Feature{Geometry: line, Properties: map[string]any{}}
+	data, err := json.Marshal(feature)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "bbox")
+
+	feature.Bbox = geo.GeometryBbox(line)
+	data, err = json.Marshal(feature)
+	require.NoError(t, err)
+
+	decoded := map[string]any{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, []any{-1.0, -4.0, 5.0, 6.0}, decoded["bbox"])
+}
+
+func TestFeatureEncoderComputeBbox(t *testing.T) {
+	line := orb.LineString{{-1, 2}, {3, -4}, {5, 6}}
+	feature := &geo.Feature{Geometry: line, Properties: map[string]any{}}
+
+	data, err := (&geo.FeatureEncoder{}).Marshal(feature)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "bbox")
+
+	encoder := &geo.FeatureEncoder{ComputeBbox: true}
+	data, err = encoder.Marshal(feature)
+	require.NoError(t, err)
+
+	decoded := map[string]any{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, []any{-1.0, -4.0, 5.0, 6.0}, decoded["bbox"])
+	assert.Nil(t, feature.Bbox)
+
+	stored := &geo.Feature{Geometry: line, Properties: map[string]any{}, Bbox: []float64{0, 0, 1, 1}}
+	data, err = encoder.Marshal(stored)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"bbox":[0,0,1,1]`)
+
+	empty := &geo.Feature{Properties: map[string]any{}}
+	data, err = encoder.Marshal(empty)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "bbox")
+}
+
+func TestFeatureUnmarshalBbox(t *testing.T) {
+	feature := &geo.Feature{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "Feature",
+		"bbox": [0, 1, 2, 3],
+		"geometry": {"type": "LineString", "coordinates": [[0, 1], [2, 3]]},
+		"properties": {}
+	}`), feature))
+	assert.Equal(t, []float64{0, 1, 2, 3}, feature.Bbox)
+
+	data, err := json.Marshal(feature)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"bbox":[0,1,2,3]`)
+
+	invalid := &geo.Feature{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "Feature",
+		"bbox": "not a bbox",
+		"geometry": null,
+		"properties": {}
+	}`), invalid))
+	assert.Nil(t, invalid.Bbox)
+}
+
+func TestIsEWKB(t *testing.T) {
+	withSRID, err := ewkb.Marshal(orb.Point{1, 2}, 4326)
+	require.NoError(t, err)
+	assert.True(t, geo.IsEWKB(withSRID))
+
+	bigEndian, err := ewkb.Marshal(orb.Point{1, 2}, 4326, binary.BigEndian)
+	require.NoError(t, err)
+	assert.True(t, geo.IsEWKB(bigEndian))
+
+	standard, err := wkb.Marshal(orb.Point{1, 2})
+	require.NoError(t, err)
+	assert.False(t, geo.IsEWKB(standard))
+
+	assert.False(t, geo.IsEWKB(nil))
+}
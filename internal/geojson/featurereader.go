@@ -5,15 +5,22 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 
 	"github.com/paulmach/orb"
-	orbjson "github.com/paulmach/orb/geojson"
 	"github.com/planetlabs/gpq/internal/geo"
 )
 
+// FeatureReader reads features from a FeatureCollection, a single Feature, a
+// bare Geometry object, or newline-delimited Features.  A Feature must have a
+// "geometry" member and a Geometry must have "coordinates".
 type FeatureReader struct {
-	collection bool
-	decoder    *json.Decoder
+	collection     bool
+	inFeatures     bool
+	decoder        *json.Decoder
+	offset         int64
+	foreignMembers bool
+	collectionName string
 }
 
 func NewFeatureReader(input io.Reader) *FeatureReader {
@@ -22,6 +29,27 @@ func NewFeatureReader(input io.Reader) *FeatureReader {
 	}
 }
 
+// KeepForeignMembers makes the reader collect the foreign members of each
+// Feature (members not defined by GeoJSON).  This is slower because each
+// feature is decoded twice.
+func (r *FeatureReader) KeepForeignMembers() {
+	r.foreignMembers = true
+}
+
+// CollectionName returns the "name" member of a FeatureCollection.  A name
+// that follows the features is only available after all features are read.
+func (r *FeatureReader) CollectionName() string {
+	return r.collectionName
+}
+
+// Offset returns the number of input bytes read so far.
+func (r *FeatureReader) Offset() int64 {
+	if r.decoder == nil {
+		return r.offset
+	}
+	return r.decoder.InputOffset()
+}
+
 func (r *FeatureReader) Read() (*geo.Feature, error) {
 	if r.decoder == nil {
 		return nil, io.EOF
@@ -33,6 +61,7 @@ func (r *FeatureReader) Read() (*geo.Feature, error) {
 
 	defer func() {
 		if !r.collection {
+			r.offset = r.decoder.InputOffset()
 			r.decoder = nil
 		}
 	}()
@@ -53,13 +82,17 @@ func (r *FeatureReader) Read() (*geo.Feature, error) {
 	var parsedType string
 	var feature *geo.Feature
 	var coordinatesJSON json.RawMessage
+	var foreignMembers map[string]any
+	var collectionName string
+	var bbox []float64
+	hasGeometry := false
 	for {
 		keyToken, keyErr := r.decoder.Token()
 		if keyErr == io.EOF {
-			if feature == nil {
+			if feature == nil && coordinatesJSON == nil {
 				return nil, io.EOF
 			}
-			return feature, nil
+			return objectFeature(parsedType, feature, hasGeometry, coordinatesJSON, foreignMembers, bbox)
 		}
 		if keyErr != nil {
 			return nil, keyErr
@@ -70,10 +103,10 @@ func (r *FeatureReader) Read() (*geo.Feature, error) {
 			if r.decoder.More() {
 				r.collection = true
 			}
-			if feature == nil {
+			if feature == nil && coordinatesJSON == nil {
 				return nil, errors.New("expected a FeatureCollection, a Feature, or a Geometry object")
 			}
-			return feature, nil
+			return objectFeature(parsedType, feature, hasGeometry, coordinatesJSON, foreignMembers, bbox)
 		}
 
 		key, ok := keyToken.(string)
@@ -82,16 +115,26 @@ func (r *FeatureReader) Read() (*geo.Feature, error) {
 		}
 
 		if key == "geometry" {
+			if hasGeometry {
+				return nil, errors.New("found duplicate geometry")
+			}
+			if coordinatesJSON != nil {
+				return nil, geo.ErrGeometryAndCoordinates
+			}
+			hasGeometry = true
 			if feature == nil {
 				feature = &geo.Feature{}
-			} else if feature.Geometry != nil {
-				return nil, errors.New("found duplicate geometry")
 			}
-			geometry := &orbjson.Geometry{}
-			if err := r.decoder.Decode(geometry); err != nil {
+			raw := json.RawMessage{}
+			if err := r.decoder.Decode(&raw); err != nil {
 				return nil, fmt.Errorf("trouble parsing geometry: %w", err)
 			}
-			feature.Geometry = geometry.Geometry()
+			geometry, dimensions, err := geo.DecodeGeoJSONGeometry(raw)
+			if err != nil {
+				return nil, fmt.Errorf("trouble parsing geometry: %w", err)
+			}
+			feature.Geometry = geometry
+			feature.Dimensions = dimensions
 			continue
 		}
 
@@ -110,10 +153,11 @@ func (r *FeatureReader) Read() (*geo.Feature, error) {
 		}
 
 		if key == "coordinates" {
-			if feature == nil {
-				feature = &geo.Feature{}
-			} else if feature.Geometry != nil {
-				return nil, errors.New("found unexpected coordinates")
+			if hasGeometry {
+				return nil, geo.ErrGeometryAndCoordinates
+			}
+			if parsedType == "Feature" {
+				return nil, geo.ErrFeatureCoordinates
 			}
 			if coordinatesJSON != nil {
 				return nil, errors.New("found duplicate coordinates")
@@ -121,12 +165,50 @@ func (r *FeatureReader) Read() (*geo.Feature, error) {
 			if err := r.decoder.Decode(&coordinatesJSON); err != nil {
 				return nil, fmt.Errorf("trouble parsing coordinates")
 			}
-			if parsedType != "" {
-				return r.featureFromCoordinates(parsedType, coordinatesJSON)
+			continue
+		}
+
+		if key == "bbox" {
+			raw := json.RawMessage{}
+			if err := r.decoder.Decode(&raw); err != nil {
+				return nil, fmt.Errorf("trouble parsing bbox: %w", err)
+			}
+			var value []float64
+			if json.Unmarshal(raw, &value) == nil && len(value) > 0 {
+				bbox = value
 			}
 			continue
 		}
 
+		if key == "name" {
+			var value any
+			if err := r.decoder.Decode(&value); err != nil {
+				return nil, fmt.Errorf("trouble parsing name: %w", err)
+			}
+			if name, ok := value.(string); ok {
+				collectionName = name
+			}
+			if r.foreignMembers {
+				if foreignMembers == nil {
+					foreignMembers = map[string]any{}
+				}
+				foreignMembers[key] = value
+			}
+			continue
+		}
+
+		if r.foreignMembers && !slices.Contains(geo.FeatureMembers, key) && key != "features" && key != "geometries" {
+			var value any
+			if err := r.decoder.Decode(&value); err != nil {
+				return nil, fmt.Errorf("trouble parsing %q: %w", key, err)
+			}
+			if foreignMembers == nil {
+				foreignMembers = map[string]any{}
+			}
+			foreignMembers[key] = value
+			continue
+		}
+
 		valueToken, valueErr := r.decoder.Token()
 		if valueErr != nil {
 			return nil, valueErr
@@ -141,8 +223,8 @@ func (r *FeatureReader) Read() (*geo.Feature, error) {
 				return nil, fmt.Errorf("unexpected type: %s", valueToken)
 			}
 			parsedType = value
-			if coordinatesJSON != nil {
-				return r.featureFromCoordinates(parsedType, coordinatesJSON)
+			if parsedType == "Feature" && coordinatesJSON != nil {
+				return nil, geo.ErrFeatureCoordinates
 			}
 			continue
 		}
@@ -156,6 +238,8 @@ func (r *FeatureReader) Read() (*geo.Feature, error) {
 				return nil, fmt.Errorf("expected an array of features, got %s", token)
 			}
 			r.collection = true
+			r.inFeatures = true
+			r.collectionName = collectionName
 			return r.readFeature()
 		}
 
@@ -228,33 +312,122 @@ func (r *FeatureReader) scanToMatching(fromDelim json.Delim, toDelim json.Delim)
 	}
 }
 
-func (r *FeatureReader) featureFromCoordinates(geometryType string, coordinatesJSON json.RawMessage) (*geo.Feature, error) {
+// objectFeature returns the feature read from a top-level object.  An object
+// with a "geometry" member is read as a Feature.  An object with "coordinates"
+// is read as a bare Geometry of the given type (other members like properties
+// are ignored).  Objects that mix the two are rejected.  A valid bbox member is
+// kept on the feature.
+func objectFeature(parsedType string, feature *geo.Feature, hasGeometry bool, coordinatesJSON json.RawMessage, foreignMembers map[string]any, bbox []float64) (*geo.Feature, error) {
+	if coordinatesJSON == nil {
+		if hasGeometry && parsedType != "" && parsedType != "Feature" {
+			return nil, fmt.Errorf("found a geometry member in a %q object (only a Feature can have a geometry member)", parsedType)
+		}
+		if feature != nil && len(foreignMembers) > 0 {
+			feature.ForeignMembers = foreignMembers
+		}
+		if feature != nil && len(bbox) > 0 {
+			feature.Bbox = bbox
+		}
+		return feature, nil
+	}
+	if hasGeometry {
+		return nil, geo.ErrGeometryAndCoordinates
+	}
+	switch parsedType {
+	case "Feature":
+		return nil, geo.ErrFeatureCoordinates
+	case "":
+		return nil, geo.ErrMissingGeometryType
+	}
+	feature, err := featureFromCoordinates(parsedType, coordinatesJSON)
+	if err != nil {
+		return nil, err
+	}
+	if len(bbox) > 0 {
+		feature.Bbox = bbox
+	}
+	return feature, nil
+}
+
+func featureFromCoordinates(geometryType string, coordinatesJSON json.RawMessage) (*geo.Feature, error) {
 	prefix := []byte(`{"type":"` + geometryType + `","coordinates":`)
 	geometryData := append(prefix, coordinatesJSON...)
 	geometryData = append(geometryData, "}"...)
-	geometry := &orbjson.Geometry{}
-	if err := json.Unmarshal(geometryData, geometry); err != nil {
+	geometry, dimensions, err := geo.DecodeGeoJSONGeometry(geometryData)
+	if err != nil {
 		return nil, fmt.Errorf("trouble parsing geometry coordinates: %w", err)
 	}
 	feature := &geo.Feature{
-		Geometry:   geometry.Geometry(),
+		Geometry:   geometry,
 		Properties: map[string]any{},
+		Dimensions: dimensions,
 	}
 	return feature, nil
 }
 
 func (r *FeatureReader) readFeature() (*geo.Feature, error) {
 	if !r.decoder.More() {
+		if r.inFeatures {
+			r.readTrailingMembers()
+		}
+		r.offset = r.decoder.InputOffset()
 		r.decoder = nil
 		return nil, io.EOF
 	}
+	if !r.foreignMembers {
+		feature := &geo.Feature{}
+		if err := r.decoder.Decode(feature); err != nil {
+			return nil, err
+		}
+		return feature, nil
+	}
+
+	raw := json.RawMessage{}
+	if err := r.decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
 	feature := &geo.Feature{}
-	if err := r.decoder.Decode(feature); err != nil {
+	if err := json.Unmarshal(raw, feature); err != nil {
 		return nil, err
 	}
+	members := map[string]any{}
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, err
+	}
+	for _, key := range geo.FeatureMembers {
+		delete(members, key)
+	}
+	if len(members) > 0 {
+		feature.ForeignMembers = members
+	}
 	return feature, nil
 }
 
+// readTrailingMembers reads the members of a FeatureCollection that follow the
+// features array to find a name.  Errors are ignored since all features have
+// already been read.
+func (r *FeatureReader) readTrailingMembers() {
+	if _, err := r.decoder.Token(); err != nil {
+		return
+	}
+	for r.decoder.More() {
+		keyToken, err := r.decoder.Token()
+		if err != nil {
+			return
+		}
+		value := json.RawMessage{}
+		if err := r.decoder.Decode(&value); err != nil {
+			return
+		}
+		if key, ok := keyToken.(string); ok && key == "name" {
+			var name string
+			if json.Unmarshal(value, &name) == nil {
+				r.collectionName = name
+			}
+		}
+	}
+}
+
 func (r *FeatureReader) readGeometryCollection() (*geo.Feature, error) {
 	feature := &geo.Feature{Properties: map[string]any{}}
 
@@ -264,11 +437,16 @@ func (r *FeatureReader) readGeometryCollection() (*geo.Feature, error) {
 
 	geometries := []orb.Geometry{}
 	for r.decoder.More() {
-		geometry := &orbjson.Geometry{}
-		if err := r.decoder.Decode(geometry); err != nil {
+		raw := json.RawMessage{}
+		if err := r.decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("trouble parsing geometry: %w", err)
+		}
+		geometry, dimensions, err := geo.DecodeGeoJSONGeometry(raw)
+		if err != nil {
 			return nil, fmt.Errorf("trouble parsing geometry: %w", err)
 		}
-		geometries = append(geometries, geometry.Geometry())
+		feature.Dimensions.Add(dimensions)
+		geometries = append(geometries, geometry)
 	}
 
 	feature.Geometry = orb.Collection(geometries)
@@ -3,6 +3,7 @@ package geojson_test
 import (
 	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/paulmach/orb"
@@ -40,6 +41,34 @@ func TestFeatureReader(t *testing.T) {
 	assert.Equal(t, float64(326625791), usa.Properties["pop_est"])
 }
 
+func TestFeatureReaderOffset(t *testing.T) {
+	data, readErr := os.ReadFile("testdata/ten-points.geojson")
+	require.NoError(t, readErr)
+
+	reader := geojson.NewFeatureReader(strings.NewReader(string(data)))
+	assert.Equal(t, int64(0), reader.Offset())
+
+	_, err := reader.Read()
+	require.NoError(t, err)
+	first := reader.Offset()
+	assert.Greater(t, first, int64(0))
+
+	_, err = reader.Read()
+	require.NoError(t, err)
+	second := reader.Offset()
+	assert.Greater(t, second, first)
+
+	for {
+		_, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+	assert.Greater(t, reader.Offset(), second)
+	assert.LessOrEqual(t, reader.Offset(), int64(len(data)))
+}
+
 func TestFeatureReaderPointGeometry(t *testing.T) {
 	file, openErr := os.Open("testdata/point-geometry.geojson")
 	require.NoError(t, openErr)
@@ -92,6 +121,54 @@ func TestFeatureReaderSingleFeature(t *testing.T) {
 	assert.Equal(t, map[string]any{"name": "test"}, feature.Properties)
 }
 
+func TestFeatureReaderSingleFeatureBbox(t *testing.T) {
+	reader := geojson.NewFeatureReader(strings.NewReader(`{
+		"type": "Feature",
+		"bbox": [1, 2, 1, 2],
+		"geometry": {"type": "Point", "coordinates": [1, 2]},
+		"properties": {}
+	}`))
+
+	feature, err := reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 2, 1, 2}, feature.Bbox)
+
+	_, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestFeatureReaderSingleFeatureInvalidBbox(t *testing.T) {
+	reader := geojson.NewFeatureReader(strings.NewReader(`{
+		"type": "Feature",
+		"bbox": "not a bbox",
+		"geometry": {"type": "Point", "coordinates": [1, 2]},
+		"properties": {}
+	}`))
+
+	feature, err := reader.Read()
+	require.NoError(t, err)
+	assert.Nil(t, feature.Bbox)
+}
+
+func TestFeatureReaderCollectionBbox(t *testing.T) {
+	reader := geojson.NewFeatureReader(strings.NewReader(`{
+		"type": "FeatureCollection",
+		"bbox": [0, 0, 10, 10],
+		"features": [
+			{"type": "Feature", "bbox": [1, 2, 1, 2], "geometry": {"type": "Point", "coordinates": [1, 2]}, "properties": {}},
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [3, 4]}, "properties": {}}
+		]
+	}`))
+
+	first, err := reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 2, 1, 2}, first.Bbox)
+
+	second, err := reader.Read()
+	require.NoError(t, err)
+	assert.Nil(t, second.Bbox)
+}
+
 func TestFeatureReaderNewLineDelimited(t *testing.T) {
 	file, openErr := os.Open("testdata/new-line-delimited.ndgeojson")
 	require.NoError(t, openErr)
@@ -181,3 +258,105 @@ func TestFeatureReaderNotGeoJSONArray(t *testing.T) {
 	assert.Nil(t, feature)
 	assert.EqualError(t, err, "expected a JSON object, got [")
 }
+
+func TestFeatureReaderPointGeometryTypeLast(t *testing.T) {
+	file, openErr := os.Open("testdata/point-geometry-type-last.geojson")
+	require.NoError(t, openErr)
+
+	reader := geojson.NewFeatureReader(file)
+
+	feature, err := reader.Read()
+	require.NoError(t, err)
+	point, ok := feature.Geometry.(orb.Point)
+	require.True(t, ok)
+	assert.True(t, point.Equal(orb.Point{1, 2}))
+	assert.Len(t, feature.Properties, 0)
+
+	_, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestFeatureReaderAmbiguousGeometry(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      string
+		features int
+	}{
+		{
+			name: "feature-with-coordinates",
+			err:  "found coordinates in a Feature (a Feature must use a geometry member)",
+		},
+		{
+			name: "geometry-and-coordinates",
+			err:  "found both geometry and coordinates members (a Feature must use geometry and a Geometry must use coordinates)",
+		},
+		{
+			name: "geometry-with-geometry-member",
+			err:  `found a geometry member in a "Point" object (only a Feature can have a geometry member)`,
+		},
+		{
+			name: "coordinates-without-type",
+			err:  "found coordinates without a geometry type",
+		},
+		{
+			name:     "collection-geometry-and-coordinates",
+			err:      "found both geometry and coordinates members (a Feature must use geometry and a Geometry must use coordinates)",
+			features: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			file, openErr := os.Open("testdata/" + c.name + ".geojson")
+			require.NoError(t, openErr)
+
+			reader := geojson.NewFeatureReader(file)
+			for i := 0; i < c.features; i += 1 {
+				_, err := reader.Read()
+				require.NoError(t, err)
+			}
+
+			feature, err := reader.Read()
+			assert.Nil(t, feature)
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
+
+func TestFeatureReaderCollectionName(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "before features",
+			input: `{"type": "FeatureCollection", "name": "places", "features": [{"type": "Feature", "properties": {}, "geometry": null}]}`,
+		},
+		{
+			name:  "after features",
+			input: `{"type": "FeatureCollection", "features": [{"type": "Feature", "properties": {}, "geometry": null}], "crs": {}, "name": "places"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			reader := geojson.NewFeatureReader(strings.NewReader(c.input))
+			for {
+				_, err := reader.Read()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+			}
+			assert.Equal(t, "places", reader.CollectionName())
+		})
+	}
+}
+
+func TestFeatureReaderFeatureName(t *testing.T) {
+	input := `{"type": "Feature", "name": "not a collection", "properties": {}, "geometry": {"type": "Point", "coordinates": [1, 2]}}`
+	reader := geojson.NewFeatureReader(strings.NewReader(input))
+	_, err := reader.Read()
+	require.NoError(t, err)
+	assert.Equal(t, "", reader.CollectionName())
+}
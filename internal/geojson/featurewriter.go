@@ -0,0 +1,72 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/planetlabs/gpq/internal/geo"
+)
+
+// FeatureWriter writes features as a GeoJSON FeatureCollection without
+// buffering the whole collection.
+type FeatureWriter struct {
+	writer  io.Writer
+	writing bool
+	closed  bool
+}
+
+func NewFeatureWriter(writer io.Writer) (*FeatureWriter, error) {
+	return &FeatureWriter{writer: writer}, nil
+}
+
+func (w *FeatureWriter) Write(feature *geo.Feature) error {
+	if w.closed {
+		return errors.New("cannot write to a closed feature writer")
+	}
+	if !w.writing {
+		if _, err := w.writer.Write(compactFormat.prefix); err != nil {
+			return err
+		}
+		w.writing = true
+	} else {
+		if _, err := w.writer.Write(compactFormat.separator); err != nil {
+			return err
+		}
+	}
+
+	featureData, jsonErr := json.Marshal(feature)
+	if jsonErr != nil {
+		return jsonErr
+	}
+	_, err := w.writer.Write(featureData)
+	return err
+}
+
+// Close completes the feature collection (writing an empty collection if no
+// features were written) and closes the underlying writer if it is a closer.
+func (w *FeatureWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if !w.writing {
+		if _, err := w.writer.Write(compactFormat.prefix); err != nil {
+			return err
+		}
+	}
+	if _, err := w.writer.Write(compactFormat.featuresSuffix); err != nil {
+		return err
+	}
+	if _, err := w.writer.Write(compactFormat.suffix); err != nil {
+		return err
+	}
+	w.writing = false
+
+	closer, ok := w.writer.(io.Closer)
+	if ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package geojson_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/planetlabs/gpq/internal/geo"
+	"github.com/planetlabs/gpq/internal/geojson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureWriterEmpty(t *testing.T) {
+	output := &bytes.Buffer{}
+	writer, err := geojson.NewFeatureWriter(output)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	assert.JSONEq(t, `{"type": "FeatureCollection", "features": []}`, output.String())
+}
+
+func TestFeatureWriter(t *testing.T) {
+	output := &bytes.Buffer{}
+	writer, err := geojson.NewFeatureWriter(output)
+	require.NoError(t, err)
+
+	require.NoError(t, writer.Write(&geo.Feature{
+		Id:         "null-island",
+		Geometry:   orb.Point{0, 0},
+		Properties: map[string]any{"name": "Null Island"},
+	}))
+	require.NoError(t, writer.Write(&geo.Feature{
+		Properties: map[string]any{"name": "Nowhere"},
+	}))
+	require.NoError(t, writer.Close())
+
+	expected := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"id": "null-island",
+				"properties": {"name": "Null Island"},
+				"geometry": {"type": "Point", "coordinates": [0, 0]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "Nowhere"},
+				"geometry": null
+			}
+		]
+	}`
+	assert.JSONEq(t, expected, output.String())
+
+	assert.ErrorContains(t, writer.Write(&geo.Feature{}), "closed")
+	assert.NoError(t, writer.Close())
+}
+
+func TestFeatureWriterMany(t *testing.T) {
+	output := &bytes.Buffer{}
+	writer, err := geojson.NewFeatureWriter(output)
+	require.NoError(t, err)
+
+	numFeatures := 1000
+	for i := 0; i < numFeatures; i += 1 {
+		require.NoError(t, writer.Write(&geo.Feature{
+			Geometry:   orb.Point{float64(i), float64(-i)},
+			Properties: map[string]any{"name": fmt.Sprintf("feature-%d", i)},
+		}))
+	}
+	require.NoError(t, writer.Close())
+
+	reader := geojson.NewFeatureReader(output)
+	for i := 0; i < numFeatures; i += 1 {
+		feature, err := reader.Read()
+		require.NoError(t, err)
+		assert.Equal(t, orb.Point{float64(i), float64(-i)}, feature.Geometry)
+		assert.Equal(t, fmt.Sprintf("feature-%d", i), feature.Properties["name"])
+	}
+	_, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+}
@@ -1,10 +1,19 @@
 package geojson
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"slices"
 
+	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/apache/arrow/go/v16/parquet"
+	"github.com/apache/arrow/go/v16/parquet/metadata"
+	"github.com/apache/arrow/go/v16/parquet/pqarrow"
+	"github.com/paulmach/orb"
 	"github.com/planetlabs/gpq/internal/geo"
 	"github.com/planetlabs/gpq/internal/geoparquet"
 	"github.com/planetlabs/gpq/internal/pqutil"
@@ -12,6 +21,18 @@ import (
 
 const primaryColumn = "geometry"
 
+// IndexIdColumn is the name of the column used to store ids synthesized from
+// the feature index.
+const IndexIdColumn = "id"
+
+// CollectionNameKey is the file metadata key used to store the "name" member
+// of a FeatureCollection.
+const CollectionNameKey = "gpq:collection_name"
+
+// ForeignMembersColumn is the name of the JSON column used to store the
+// foreign members of features.
+const ForeignMembersColumn = "foreign_members"
+
 func GetDefaultMetadata() *geoparquet.Metadata {
 	return &geoparquet.Metadata{
 		Version:       geoparquet.Version,
@@ -25,18 +46,107 @@ func GetDefaultMetadata() *geoparquet.Metadata {
 	}
 }
 
-func FromParquet(reader parquet.ReaderAtSeeker, writer io.Writer) error {
+// FromParquetOptions includes options for converting GeoParquet to GeoJSON.
+type FromParquetOptions struct {
+	// CollectionBbox adds a top-level "bbox" member to the feature collection.
+	// The bounds come from the primary column metadata if available or are
+	// computed from the features.
+	CollectionBbox bool
+
+	// Flatten writes struct columns as properties with dotted names (e.g.
+	// "address.city") instead of nested objects.  Lists of structs are left
+	// nested.
+	Flatten bool
+
+	// Pretty writes indented JSON.  Features are still written as they are
+	// read, so the output is streamed either way.
+	Pretty bool
+
+	// IdColumn, if not empty, is the name of a string or number column to
+	// write as the feature "id" instead of as a property.
+	IdColumn string
+
+	// GeometryPrecision, if not nil, is the number of decimal places that
+	// coordinates are rounded to (zero rounds to integers).  A collection
+	// bbox is computed from the rounded coordinates.
+	GeometryPrecision *int
+
+	// WKTProperty, if not empty, is the name of a property that the primary
+	// geometry is also written to as a WKT string.
+	WKTProperty string
+
+	// RFC7946 rewinds polygon rings so that exterior rings are
+	// counterclockwise and interior rings are clockwise (the right-hand rule
+	// from RFC 7946).  By default, geometries are written as they are stored.
+	RFC7946 bool
+
+	// CollectionName, if not empty, is written as the "name" member of the
+	// feature collection.  By default, the name stored under CollectionNameKey
+	// in the file metadata is used.
+	CollectionName string
+
+	// Context, if not nil, stops reading with the context error when it is
+	// done.
+	Context context.Context
+}
+
+func FromParquet(reader parquet.ReaderAtSeeker, writer io.Writer, options *FromParquetOptions) error {
+	var ctx context.Context
+	if options != nil {
+		ctx = options.Context
+	}
 	recordReader, rrErr := geoparquet.NewRecordReader(&geoparquet.ReaderConfig{
-		Reader: reader,
+		Reader:  reader,
+		Context: ctx,
 	})
 	if rrErr != nil {
 		return rrErr
 	}
 	defer recordReader.Close()
 
+	return writeRecords(recordReader, writer, options)
+}
+
+// FromParquetDataset writes the features from a number of GeoParquet files
+// (e.g. the parts of a partitioned dataset) as a single feature collection.
+func FromParquetDataset(readers []parquet.ReaderAtSeeker, writer io.Writer, options *FromParquetOptions) error {
+	var ctx context.Context
+	if options != nil {
+		ctx = options.Context
+	}
+	datasetReader, drErr := geoparquet.NewDatasetReader(&geoparquet.DatasetConfig{
+		Readers: readers,
+		Context: ctx,
+	})
+	if drErr != nil {
+		return drErr
+	}
+	defer datasetReader.Close()
+
+	return writeRecords(datasetReader, writer, options)
+}
+
+type recordReader interface {
+	Read() (arrow.Record, error)
+	Metadata() *geoparquet.Metadata
+	KeyValueMetadata() metadata.KeyValueMetadata
+}
+
+func writeRecords(recordReader recordReader, writer io.Writer, options *FromParquetOptions) error {
 	geoMetadata := recordReader.Metadata()
 
-	jsonWriter, jsonErr := NewRecordWriter(writer, geoMetadata)
+	if options == nil {
+		options = &FromParquetOptions{}
+	}
+	if options.CollectionName == "" {
+		if name := recordReader.KeyValueMetadata().FindValue(CollectionNameKey); name != nil && *name != "" {
+			withName := *options
+			withName.CollectionName = *name
+			options = &withName
+		}
+	}
+
+	jsonWriter, jsonErr := NewRecordWriter(writer, geoMetadata, options)
 	if jsonErr != nil {
 		return jsonErr
 	}
@@ -58,13 +168,172 @@ func FromParquet(reader parquet.ReaderAtSeeker, writer io.Writer) error {
 }
 
 type ConvertOptions struct {
-	MinFeatures    int
-	MaxFeatures    int
-	Compression    string
-	RowGroupLength int
-	Metadata       string
+	MinFeatures int
+	MaxFeatures int
+
+	// MaxSampleBytes, if positive, limits the number of input bytes read while
+	// sampling features to build the schema.  Sampling stops when either the
+	// MinFeatures or the MaxSampleBytes limit is reached.  If the schema cannot
+	// be built from the features read within the limit, an error is returned.
+	MaxSampleBytes int
+
+	Compression      string
+	RowGroupLength   int
+	MaxRowGroupBytes int
+	Metadata         string
+	GeometryTypes    []string
+
+	// JSONProperties stores object properties as JSON-encoded string columns
+	// instead of structs, so that objects with varying shapes are preserved.
+	JSONProperties bool
+
+	// Edges, if not empty, is written as the "edges" for the geometry column.
+	Edges string
+
+	// DisableDictionary turns off dictionary encoding for all columns.
+	DisableDictionary bool
+
+	// DataPageSize, if positive, is the target size in bytes for data pages.
+	DataPageSize int
+
+	// PartitionCellSize, if positive, writes one row group per cell of a grid
+	// with this cell size (in coordinate units).  Features are assigned to the
+	// cell containing the center of their bounds.
+	PartitionCellSize float64
+
+	// Version, if not empty, is written as the metadata "version" instead of
+	// the default version.
+	Version string
+
+	// ColumnCompression maps column names to the compression codec to use for
+	// that column instead of the Compression value.
+	ColumnCompression map[string]string
+
+	// ForeignMembers stores the members of each Feature that are not defined by
+	// GeoJSON in a JSON column named by ForeignMembersColumn.  These are written
+	// back as members when converting to GeoJSON.  Reading them is slower, so
+	// this is off by default.
+	ForeignMembers bool
+
+	// KeepCollectionName stores the "name" member of a FeatureCollection in the
+	// file metadata (under CollectionNameKey).  It is written back as the
+	// collection name when converting to GeoJSON.
+	KeepCollectionName bool
+
+	// IdFromIndex writes the zero-based index of each feature to an integer
+	// column named by IndexIdColumn.  This can be used to give features ids when
+	// the input has none.  With FlattenCollections, the index is assigned after
+	// flattening, so each written row has a unique id.
+	IdFromIndex bool
+
+	// GeometryPrecision, if not nil, is the number of decimal places that
+	// coordinates are rounded to (zero rounds to integers).  The bounds in
+	// the metadata are computed from the rounded coordinates.
+	GeometryPrecision *int
+
+	// BoundsPrecision, if not nil, is the number of decimal places that the
+	// "bbox" values in the metadata are rounded to (outward, so that they still
+	// contain all of the geometries).
+	BoundsPrecision *int
+
+	// GeometryFirst makes the geometry the first column instead of sorting it
+	// with the property columns by name.
+	GeometryFirst bool
+
+	// Force2D allows features with 2D and 3D coordinates to be mixed.  Only the X
+	// and Y values are written, so the Z values are dropped.  Without this
+	// option, mixed dimensions are an error (ErrMixedDimensions).
+	Force2D bool
+
+	// NoMetadata writes plain Parquet without the "geo" metadata.  The
+	// geometry is still written as WKB.
+	NoMetadata bool
+
+	// FlattenCollections writes a feature for each member of a
+	// GeometryCollection (including the members of nested collections) with
+	// the properties of the original feature.  The "geometry_types" in the
+	// metadata list the member types.  A feature with an empty collection is
+	// written with a null geometry.
+	FlattenCollections bool
+
+	// SplitFeatures, if positive, is the maximum number of features written to
+	// each output.  When an output is full, it is closed and NextOutput is
+	// called for the next one.  Each output has its own bounds and geometry
+	// types in the metadata.
+	SplitFeatures int
+
+	// NextOutput returns the output for a part of split output.  The first part
+	// is written to the output passed to ToParquet, so this is called with part
+	// numbers starting at 2.  Outputs that implement io.Closer are closed after
+	// the part is written.
+	NextOutput func(part int) (io.Writer, error)
+
+	// Context, if not nil, stops the conversion with the context error when it
+	// is done.  It is checked before each feature is read.
+	Context context.Context
 }
 
+// flattenGeometryCollection returns a feature for each member of a feature's
+// GeometryCollection.  Other features are returned as is.
+func flattenGeometryCollection(feature *geo.Feature) []*geo.Feature {
+	collection, ok := feature.Geometry.(orb.Collection)
+	if !ok {
+		return []*geo.Feature{feature}
+	}
+	geometries := collectionMembers(collection, nil)
+	if len(geometries) == 0 {
+		geometries = append(geometries, nil)
+	}
+	features := make([]*geo.Feature, len(geometries))
+	for i, geometry := range geometries {
+		member := *feature
+		member.Geometry = geometry
+		member.Properties = maps.Clone(feature.Properties)
+		features[i] = &member
+	}
+	return features
+}
+
+func collectionMembers(collection orb.Collection, geometries []orb.Geometry) []orb.Geometry {
+	for _, geometry := range collection {
+		if nested, ok := geometry.(orb.Collection); ok {
+			geometries = collectionMembers(nested, geometries)
+			continue
+		}
+		geometries = append(geometries, geometry)
+	}
+	return geometries
+}
+
+// DefaultMaxRowGroupBytes is the target (uncompressed) row group size used to
+// pick a row group length when one is not provided.
+const DefaultMaxRowGroupBytes = 128 * 1024 * 1024
+
+// estimateRowGroupLength uses the average encoded size of the sampled features
+// to guess how many rows will fit in a row group of roughly maxBytes.
+func estimateRowGroupLength(sample []*geo.Feature, maxBytes int) (int, error) {
+	if len(sample) == 0 {
+		return 0, nil
+	}
+	total := 0
+	for _, feature := range sample {
+		data, err := json.Marshal(feature)
+		if err != nil {
+			return 0, fmt.Errorf("trouble estimating feature size: %w", err)
+		}
+		total += len(data)
+	}
+	average := total / len(sample)
+	if average == 0 {
+		return 0, nil
+	}
+	return max(1, maxBytes/average), nil
+}
+
+// ErrMixedDimensions is returned by ToParquet for input with both 2D and 3D
+// coordinates unless the Force2D option is set.
+var ErrMixedDimensions = errors.New("found both 2D and 3D coordinates")
+
 var defaultOptions = &ConvertOptions{
 	MinFeatures: 1,
 	MaxFeatures: 50,
@@ -75,12 +344,50 @@ func ToParquet(input io.Reader, output io.Writer, convertOptions *ConvertOptions
 	if convertOptions == nil {
 		convertOptions = defaultOptions
 	}
+	if err := geoparquet.ValidateGeometryTypes(convertOptions.GeometryTypes); err != nil {
+		return err
+	}
+	if err := geoparquet.ValidateEdges(convertOptions.Edges); err != nil {
+		return err
+	}
+	if err := geoparquet.ValidateVersion(convertOptions.Version); err != nil {
+		return err
+	}
+	if err := geoparquet.ValidateDataPageSize(convertOptions.DataPageSize); err != nil {
+		return err
+	}
+	if err := geoparquet.ValidatePartitionCellSize(convertOptions.PartitionCellSize); err != nil {
+		return err
+	}
+	if convertOptions.MaxRowGroupBytes < 0 {
+		return fmt.Errorf("max row group bytes must not be negative, got %d", convertOptions.MaxRowGroupBytes)
+	}
+	if convertOptions.SplitFeatures < 0 {
+		return fmt.Errorf("split features must not be negative, got %d", convertOptions.SplitFeatures)
+	}
+	if convertOptions.SplitFeatures > 0 && convertOptions.NextOutput == nil {
+		return errors.New("a function for the next output is required to split features")
+	}
+	geoMetadata := geoparquet.DefaultMetadata()
+	geoMetadata.SetEdges(convertOptions.Edges)
+	geoMetadata.SetVersion(convertOptions.Version)
 	reader := NewFeatureReader(input)
 	buffer := []*geo.Feature{}
 	builder := pqutil.NewArrowSchemaBuilder()
+	if convertOptions.JSONProperties {
+		builder.EncodeObjectsAsJSON()
+	}
+	if convertOptions.GeometryFirst {
+		builder.PlaceFirst(geoparquet.DefaultGeometryColumn)
+	}
+	if convertOptions.ForeignMembers {
+		reader.KeepForeignMembers()
+		builder.AddJSON(ForeignMembersColumn)
+	}
 	featuresRead := 0
+	rowIndex := int64(0)
+	dimensions := geo.CoordinateDimensions{}
 
-	var pqWriterProps *parquet.WriterProperties
 	var writerOptions []parquet.WriterProperty
 	if convertOptions.Compression != "" {
 		compression, err := pqutil.GetCompression(convertOptions.Compression)
@@ -89,18 +396,73 @@ func ToParquet(input io.Reader, output io.Writer, convertOptions *ConvertOptions
 		}
 		writerOptions = append(writerOptions, parquet.WithCompression(compression))
 	}
-	if convertOptions.RowGroupLength > 0 {
-		writerOptions = append(writerOptions, parquet.WithMaxRowGroupLength(int64(convertOptions.RowGroupLength)))
+	if convertOptions.DisableDictionary {
+		writerOptions = append(writerOptions, parquet.WithDictionaryDefault(false))
+	}
+	if convertOptions.DataPageSize > 0 {
+		writerOptions = append(writerOptions, parquet.WithDataPageSize(int64(convertOptions.DataPageSize)))
 	}
-	if len(writerOptions) > 0 {
-		pqWriterProps = parquet.NewWriterProperties(writerOptions...)
+	columnCompression, columnCompressionErr := pqutil.GetColumnCompression(convertOptions.ColumnCompression)
+	if columnCompressionErr != nil {
+		return columnCompressionErr
+	}
+
+	maxRowGroupBytes := convertOptions.MaxRowGroupBytes
+	if maxRowGroupBytes == 0 {
+		maxRowGroupBytes = DefaultMaxRowGroupBytes
 	}
 
 	var featureWriter *geoparquet.FeatureWriter
-	writeBuffered := func() error {
+	var writerConfig *geoparquet.WriterConfig
+	part := 1
+	partFeatures := 0
+
+	closeWriter := func() error {
+		if name := reader.CollectionName(); convertOptions.KeepCollectionName && name != "" {
+			if err := featureWriter.AppendKeyValueMetadata(CollectionNameKey, name); err != nil {
+				return err
+			}
+		}
+		return featureWriter.Close()
+	}
+
+	// writePart writes a feature, rolling over to the next output when the
+	// current one has SplitFeatures features
+	writePart := func(feature *geo.Feature) error {
+		if convertOptions.SplitFeatures > 0 && partFeatures >= convertOptions.SplitFeatures {
+			if err := closeWriter(); err != nil {
+				return err
+			}
+			part += 1
+			next, err := convertOptions.NextOutput(part)
+			if err != nil {
+				return fmt.Errorf("trouble getting the output for part %d: %w", part, err)
+			}
+			config := *writerConfig
+			config.Writer = next
+			fw, fwErr := geoparquet.NewFeatureWriter(&config)
+			if fwErr != nil {
+				return fwErr
+			}
+			featureWriter = fw
+			partFeatures = 0
+		}
+		partFeatures += 1
+		return featureWriter.Write(feature)
+	}
+
+	writeBuffered := func(sample []*geo.Feature) error {
 		if !builder.Ready() {
 			return fmt.Errorf("failed to create schema after reading %d features", len(buffer))
 		}
+		rowGroupLength := convertOptions.RowGroupLength
+		if rowGroupLength <= 0 {
+			length, err := estimateRowGroupLength(sample, maxRowGroupBytes)
+			if err != nil {
+				return err
+			}
+			rowGroupLength = length
+		}
 		if err := builder.AddGeometry(geoparquet.DefaultGeometryColumn, geoparquet.DefaultGeometryEncoding); err != nil {
 			return err
 		}
@@ -108,66 +470,140 @@ func ToParquet(input io.Reader, output io.Writer, convertOptions *ConvertOptions
 		if scErr != nil {
 			return scErr
 		}
-		fw, fwErr := geoparquet.NewFeatureWriter(&geoparquet.WriterConfig{
+		options := writerOptions
+		if rowGroupLength > 0 {
+			options = append(options, parquet.WithMaxRowGroupLength(int64(rowGroupLength)))
+		}
+		columnOptions, columnErr := pqutil.ArrowColumnCompressionProperties(sc, columnCompression)
+		if columnErr != nil {
+			return columnErr
+		}
+		options = append(options, columnOptions...)
+		var pqWriterProps *parquet.WriterProperties
+		if len(options) > 0 {
+			pqWriterProps = parquet.NewWriterProperties(options...)
+		}
+		var arrowWriterProps *pqarrow.ArrowWriterProperties
+		if slices.ContainsFunc(sc.Fields(), pqutil.IsJSONField) {
+			// store the Arrow schema so the JSON field metadata is written
+			props := pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema())
+			arrowWriterProps = &props
+		}
+		writerConfig = &geoparquet.WriterConfig{
 			Writer:             output,
+			Metadata:           geoMetadata,
 			ArrowSchema:        sc,
 			ParquetWriterProps: pqWriterProps,
-		})
+			ArrowWriterProps:   arrowWriterProps,
+			GeometryTypes:      convertOptions.GeometryTypes,
+			PartitionCellSize:  convertOptions.PartitionCellSize,
+			GeometryPrecision:  convertOptions.GeometryPrecision,
+			BoundsPrecision:    convertOptions.BoundsPrecision,
+			OmitMetadata:       convertOptions.NoMetadata,
+		}
+		fw, fwErr := geoparquet.NewFeatureWriter(writerConfig)
 		if fwErr != nil {
 			return fwErr
 		}
+		featureWriter = fw
 
 		for _, buffered := range buffer {
-			if err := fw.Write(buffered); err != nil {
+			if err := writePart(buffered); err != nil {
 				return err
 			}
 		}
-		featureWriter = fw
 		return nil
 	}
 
-	for {
-		feature, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-		featuresRead += 1
+	writeFeature := func(feature *geo.Feature) error {
 		if featureWriter == nil {
 			if err := builder.Add(feature.Properties); err != nil {
 				return err
 			}
 
+			sampleBytesReached := convertOptions.MaxSampleBytes > 0 && reader.Offset() >= int64(convertOptions.MaxSampleBytes)
 			if !builder.Ready() {
 				buffer = append(buffer, feature)
-				if len(buffer) > convertOptions.MaxFeatures {
-					return fmt.Errorf("failed to create parquet schema after reading %d features", convertOptions.MaxFeatures)
+				if len(buffer) <= convertOptions.MaxFeatures && !sampleBytesReached {
+					return nil
 				}
-				continue
+				// properties that were only null in the sample may have any
+				// type later
+				builder.UseJSONForUnknown()
+				return writeBuffered(buffer)
 			}
 
-			if len(buffer) < convertOptions.MinFeatures-1 {
+			if len(buffer) < convertOptions.MinFeatures-1 && !sampleBytesReached {
 				buffer = append(buffer, feature)
-				continue
+				return nil
 			}
 
-			if err := writeBuffered(); err != nil {
+			if err := writeBuffered(append(buffer, feature)); err != nil {
 				return err
 			}
 		}
-		if err := featureWriter.Write(feature); err != nil {
+		return writePart(feature)
+	}
+
+	for {
+		if convertOptions.Context != nil {
+			if err := convertOptions.Context.Err(); err != nil {
+				return err
+			}
+		}
+		feature, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
 			return err
 		}
+		featuresRead += 1
+		if convertOptions.IdFromIndex {
+			if _, ok := feature.Properties[IndexIdColumn]; ok {
+				return fmt.Errorf("the %q property conflicts with the column used for ids", IndexIdColumn)
+			}
+		}
+		if convertOptions.ForeignMembers {
+			if _, ok := feature.Properties[ForeignMembersColumn]; ok {
+				return fmt.Errorf("the %q property conflicts with the column used for foreign members", ForeignMembersColumn)
+			}
+			if feature.ForeignMembers != nil {
+				if feature.Properties == nil {
+					feature.Properties = map[string]any{}
+				}
+				feature.Properties[ForeignMembersColumn] = feature.ForeignMembers
+			}
+		}
+		dimensions.Add(feature.Dimensions)
+		if dimensions.Mixed() && !convertOptions.Force2D {
+			return fmt.Errorf("%w (at feature %d)", ErrMixedDimensions, featuresRead)
+		}
+		features := []*geo.Feature{feature}
+		if convertOptions.FlattenCollections {
+			features = flattenGeometryCollection(feature)
+		}
+		for _, row := range features {
+			if convertOptions.IdFromIndex {
+				if row.Properties == nil {
+					row.Properties = map[string]any{}
+				}
+				row.Properties[IndexIdColumn] = rowIndex
+			}
+			rowIndex += 1
+			if err := writeFeature(row); err != nil {
+				return err
+			}
+		}
 	}
 	if featuresRead > 0 {
 		if featureWriter == nil {
-			if err := writeBuffered(); err != nil {
+			builder.UseJSONForUnknown()
+			if err := writeBuffered(buffer); err != nil {
 				return err
 			}
 		}
-		return featureWriter.Close()
+		return closeWriter()
 	}
 	return nil
 }
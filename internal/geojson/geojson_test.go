@@ -17,10 +17,14 @@ package geojson_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/apache/arrow/go/v16/arrow/array"
 	"github.com/apache/arrow/go/v16/arrow/memory"
 	"github.com/apache/arrow/go/v16/parquet"
@@ -29,6 +33,7 @@ import (
 	"github.com/apache/arrow/go/v16/parquet/pqarrow"
 	"github.com/apache/arrow/go/v16/parquet/schema"
 	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/ewkb"
 	"github.com/paulmach/orb/encoding/wkb"
 	"github.com/planetlabs/gpq/internal/geo"
 	"github.com/planetlabs/gpq/internal/geojson"
@@ -44,7 +49,7 @@ func TestFromParquetv040(t *testing.T) {
 	require.NoError(t, openErr)
 
 	buffer := &bytes.Buffer{}
-	convertErr := geojson.FromParquet(reader, buffer)
+	convertErr := geojson.FromParquet(reader, buffer, nil)
 	assert.NoError(t, convertErr)
 
 	expected, err := os.ReadFile("testdata/example.geojson")
@@ -59,7 +64,7 @@ func TestFromParquetv100Beta1(t *testing.T) {
 	require.NoError(t, openErr)
 
 	buffer := &bytes.Buffer{}
-	convertErr := geojson.FromParquet(reader, buffer)
+	convertErr := geojson.FromParquet(reader, buffer, nil)
 	assert.NoError(t, convertErr)
 
 	expected, err := os.ReadFile("testdata/example.geojson")
@@ -96,7 +101,7 @@ func TestToParquet(t *testing.T) {
 	assert.Equal(t, int64(5), fileReader.NumRows())
 
 	geojsonBuffer := &bytes.Buffer{}
-	fromParquetErr := geojson.FromParquet(parquetInput, geojsonBuffer)
+	fromParquetErr := geojson.FromParquet(parquetInput, geojsonBuffer, nil)
 	require.NoError(t, fromParquetErr)
 
 	expected, err := os.ReadFile("testdata/example.geojson")
@@ -105,6 +110,260 @@ func TestToParquet(t *testing.T) {
 	assert.JSONEq(t, string(expected), geojsonBuffer.String())
 }
 
+func TestToParquetNoMetadata(t *testing.T) {
+	geojsonFile, openErr := os.Open("testdata/example.geojson")
+	require.NoError(t, openErr)
+
+	parquetBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.ToParquet(geojsonFile, parquetBuffer, &geojson.ConvertOptions{NoMetadata: true}))
+
+	fileReader, fileErr := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, fileErr)
+	defer fileReader.Close()
+
+	_, geoErr := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	assert.ErrorIs(t, geoErr, geoparquet.ErrNoMetadata)
+	assert.Equal(t, int64(5), fileReader.NumRows())
+
+	// the geometry column is still WKB, so the metadata can be added back
+	assert.Equal(t, []string{"geometry"}, geoparquet.DetectGeometryColumns(fileReader))
+
+	geoparquetBuffer := &bytes.Buffer{}
+	require.NoError(t, geoparquet.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), geoparquetBuffer, nil))
+
+	geojsonBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(geoparquetBuffer.Bytes()), geojsonBuffer, nil))
+
+	expected, err := os.ReadFile("testdata/example.geojson")
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expected), geojsonBuffer.String())
+}
+
+func TestToParquetSplitFeatures(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "properties": {"name": "a"}, "geometry": {"type": "Point", "coordinates": [1, 2]}},
+			{"type": "Feature", "properties": {"name": "b"}, "geometry": {"type": "Point", "coordinates": [3, 4]}},
+			{"type": "Feature", "properties": {"name": "c"}, "geometry": {"type": "LineString", "coordinates": [[10, 20], [30, 40]]}},
+			{"type": "Feature", "properties": {"name": "d"}, "geometry": {"type": "Point", "coordinates": [-5, -6]}},
+			{"type": "Feature", "properties": {"name": "e"}, "geometry": {"type": "Point", "coordinates": [7, 8]}}
+		]
+	}`
+
+	parts := []*bytes.Buffer{{}}
+	convertOptions := &geojson.ConvertOptions{
+		MinFeatures:   1,
+		MaxFeatures:   10,
+		SplitFeatures: 2,
+		NextOutput: func(part int) (io.Writer, error) {
+			assert.Equal(t, len(parts)+1, part)
+			output := &bytes.Buffer{}
+			parts = append(parts, output)
+			return output, nil
+		},
+	}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parts[0], convertOptions))
+	require.Len(t, parts, 3)
+
+	expected := []struct {
+		rows          int64
+		bounds        []float64
+		geometryTypes []string
+	}{
+		{rows: 2, bounds: []float64{1, 2, 3, 4}, geometryTypes: []string{"Point"}},
+		{rows: 2, bounds: []float64{-5, -6, 30, 40}, geometryTypes: []string{"LineString", "Point"}},
+		{rows: 1, bounds: []float64{7, 8, 7, 8}, geometryTypes: []string{"Point"}},
+	}
+
+	for i, part := range parts {
+		fileReader, fileErr := file.NewParquetReader(bytes.NewReader(part.Bytes()))
+		require.NoError(t, fileErr)
+
+		metadata, geoErr := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+		require.NoError(t, geoErr)
+
+		assert.Equal(t, expected[i].rows, fileReader.NumRows(), "part %d", i+1)
+		assert.Equal(t, expected[i].bounds, metadata.Columns["geometry"].Bounds, "part %d", i+1)
+		geometryTypes := metadata.Columns["geometry"].GetGeometryTypes()
+		slices.Sort(geometryTypes)
+		assert.Equal(t, expected[i].geometryTypes, geometryTypes, "part %d", i+1)
+		require.NoError(t, fileReader.Close())
+	}
+}
+
+func TestToParquetSplitFeaturesRequiresNextOutput(t *testing.T) {
+	input := `{"type": "Feature", "properties": {}, "geometry": {"type": "Point", "coordinates": [1, 2]}}`
+	err := geojson.ToParquet(strings.NewReader(input), &bytes.Buffer{}, &geojson.ConvertOptions{SplitFeatures: 10})
+	assert.ErrorContains(t, err, "next output")
+}
+
+func TestToParquetGeometryFirst(t *testing.T) {
+	geojsonFile, openErr := os.Open("testdata/example.geojson")
+	require.NoError(t, openErr)
+
+	parquetBuffer := &bytes.Buffer{}
+	toParquetErr := geojson.ToParquet(geojsonFile, parquetBuffer, &geojson.ConvertOptions{
+		GeometryFirst: true,
+	})
+	require.NoError(t, toParquetErr)
+
+	fileReader, fileErr := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, fileErr)
+	defer fileReader.Close()
+
+	root := fileReader.MetaData().Schema.Root()
+	names := make([]string, root.NumFields())
+	for i := range names {
+		names[i] = root.Field(i).Name()
+	}
+	assert.Equal(t, []string{"geometry", "continent", "gdp_md_est", "iso_a3", "name", "pop_est"}, names)
+}
+
+func TestToParquetGeometryPrecision(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "one"},
+				"geometry": {"type": "Point", "coordinates": [1.23456, -2.34567]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "two"},
+				"geometry": {"type": "LineString", "coordinates": [[-3.45678, 4.56789], [5.67891, 6.78912]]}
+			}
+		]
+	}`
+
+	precision := 2
+	parquetBuffer := &bytes.Buffer{}
+	toParquetErr := geojson.ToParquet(strings.NewReader(input), parquetBuffer, &geojson.ConvertOptions{
+		GeometryPrecision: &precision,
+	})
+	require.NoError(t, toParquetErr)
+
+	fileReader, fileErr := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, fileErr)
+	defer fileReader.Close()
+
+	metadata, geoErr := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	require.NoError(t, geoErr)
+	assert.Equal(t, []float64{-3.46, -2.35, 5.68, 6.79}, metadata.Columns["geometry"].Bounds)
+
+	output := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), output, nil))
+
+	collection := map[string]any{}
+	require.NoError(t, json.Unmarshal(output.Bytes(), &collection))
+	features := collection["features"].([]any)
+	require.Len(t, features, 2)
+	assert.Equal(t, []any{1.23, -2.35}, features[0].(map[string]any)["geometry"].(map[string]any)["coordinates"])
+	assert.Equal(t, []any{[]any{-3.46, 4.57}, []any{5.68, 6.79}}, features[1].(map[string]any)["geometry"].(map[string]any)["coordinates"])
+}
+
+func TestToParquetBoundsPrecision(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "one"},
+				"geometry": {"type": "Point", "coordinates": [1.23456, -2.34567]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "two"},
+				"geometry": {"type": "LineString", "coordinates": [[-3.45678, 4.56789], [5.67891, 6.78912]]}
+			}
+		]
+	}`
+
+	precision := 2
+	parquetBuffer := &bytes.Buffer{}
+	toParquetErr := geojson.ToParquet(strings.NewReader(input), parquetBuffer, &geojson.ConvertOptions{
+		BoundsPrecision: &precision,
+	})
+	require.NoError(t, toParquetErr)
+
+	fileReader, fileErr := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, fileErr)
+	defer fileReader.Close()
+
+	metadata, geoErr := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	require.NoError(t, geoErr)
+	assert.Equal(t, []float64{-3.46, -2.35, 5.68, 6.79}, metadata.Columns["geometry"].Bounds)
+
+	output := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), output, nil))
+
+	collection := map[string]any{}
+	require.NoError(t, json.Unmarshal(output.Bytes(), &collection))
+	features := collection["features"].([]any)
+	require.Len(t, features, 2)
+	assert.Equal(t, []any{1.23456, -2.34567}, features[0].(map[string]any)["geometry"].(map[string]any)["coordinates"])
+}
+
+func TestToParquetDeterministicColumnOrder(t *testing.T) {
+	inputs := []string{
+		`{
+			"type": "FeatureCollection",
+			"features": [
+				{
+					"type": "Feature",
+					"properties": {"zeta": 1, "alpha": "a", "mid": {"y": true, "b": 2}},
+					"geometry": {"type": "Point", "coordinates": [1, 2]}
+				},
+				{
+					"type": "Feature",
+					"properties": {"beta": 2.5, "alpha": "b", "zeta": 2, "mid": {"b": 3, "y": false}},
+					"geometry": {"type": "Point", "coordinates": [3, 4]}
+				}
+			]
+		}`,
+		`{
+			"type": "FeatureCollection",
+			"features": [
+				{
+					"type": "Feature",
+					"properties": {"mid": {"b": 3, "y": false}, "beta": 2.5, "zeta": 2, "alpha": "b"},
+					"geometry": {"type": "Point", "coordinates": [3, 4]}
+				},
+				{
+					"type": "Feature",
+					"properties": {"alpha": "a", "mid": {"b": 2, "y": true}, "zeta": 1},
+					"geometry": {"type": "Point", "coordinates": [1, 2]}
+				}
+			]
+		}`,
+	}
+
+	columnPaths := func(input string) []string {
+		output := &bytes.Buffer{}
+		options := &geojson.ConvertOptions{MinFeatures: 10, MaxFeatures: 50}
+		require.NoError(t, geojson.ToParquet(strings.NewReader(input), output, options))
+
+		fileReader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+		require.NoError(t, err)
+		defer fileReader.Close()
+
+		fileSchema := fileReader.MetaData().Schema
+		paths := make([]string, fileSchema.NumColumns())
+		for i := range paths {
+			paths[i] = fileSchema.Column(i).Path()
+		}
+		return paths
+	}
+
+	expected := []string{"alpha", "beta", "geometry", "mid.b", "mid.y", "zeta"}
+	for i := 0; i < 3; i += 1 {
+		for _, input := range inputs {
+			assert.Equal(t, expected, columnPaths(input))
+		}
+	}
+}
+
 func TestToParquetRowGroupLength3(t *testing.T) {
 	geojsonFile, openErr := os.Open("testdata/ten-points.geojson")
 	require.NoError(t, openErr)
@@ -128,17 +387,369 @@ func TestToParquetRowGroupLength5(t *testing.T) {
 	require.NoError(t, openErr)
 
 	parquetBuffer := &bytes.Buffer{}
-	toParquetErr := geojson.ToParquet(geojsonFile, parquetBuffer, &geojson.ConvertOptions{
-		RowGroupLength: 5,
-	})
-	assert.NoError(t, toParquetErr)
+	toParquetErr := geojson.ToParquet(geojsonFile, parquetBuffer, &geojson.ConvertOptions{
+		RowGroupLength: 5,
+	})
+	assert.NoError(t, toParquetErr)
+
+	parquetInput := bytes.NewReader(parquetBuffer.Bytes())
+	fileReader, fileErr := file.NewParquetReader(parquetInput)
+	require.NoError(t, fileErr)
+	defer fileReader.Close()
+
+	assert.Equal(t, 2, fileReader.NumRowGroups())
+}
+
+func TestToParquetDefaultRowGroupLength(t *testing.T) {
+	geojsonFile, openErr := os.Open("testdata/ten-points.geojson")
+	require.NoError(t, openErr)
+
+	parquetBuffer := &bytes.Buffer{}
+	toParquetErr := geojson.ToParquet(geojsonFile, parquetBuffer, nil)
+	assert.NoError(t, toParquetErr)
+
+	fileReader, fileErr := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, fileErr)
+	defer fileReader.Close()
+
+	assert.Equal(t, 1, fileReader.NumRowGroups())
+}
+
+func TestToParquetMaxRowGroupBytes(t *testing.T) {
+	geojsonFile, openErr := os.Open("testdata/ten-points.geojson")
+	require.NoError(t, openErr)
+
+	parquetBuffer := &bytes.Buffer{}
+	toParquetErr := geojson.ToParquet(geojsonFile, parquetBuffer, &geojson.ConvertOptions{
+		MinFeatures:      10,
+		MaxFeatures:      10,
+		MaxRowGroupBytes: 1,
+	})
+	assert.NoError(t, toParquetErr)
+
+	fileReader, fileErr := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, fileErr)
+	defer fileReader.Close()
+
+	assert.Equal(t, 10, fileReader.NumRowGroups())
+}
+
+func TestToParquetMaxRowGroupBytesNegative(t *testing.T) {
+	geojsonFile, openErr := os.Open("testdata/ten-points.geojson")
+	require.NoError(t, openErr)
+
+	err := geojson.ToParquet(geojsonFile, &bytes.Buffer{}, &geojson.ConvertOptions{
+		MaxRowGroupBytes: -1,
+	})
+	assert.ErrorContains(t, err, "max row group bytes must not be negative, got -1")
+}
+
+func TestToParquetRowGroupLengthOverridesMaxRowGroupBytes(t *testing.T) {
+	geojsonFile, openErr := os.Open("testdata/ten-points.geojson")
+	require.NoError(t, openErr)
+
+	parquetBuffer := &bytes.Buffer{}
+	toParquetErr := geojson.ToParquet(geojsonFile, parquetBuffer, &geojson.ConvertOptions{
+		MinFeatures:      10,
+		MaxFeatures:      10,
+		RowGroupLength:   5,
+		MaxRowGroupBytes: 1,
+	})
+	assert.NoError(t, toParquetErr)
+
+	fileReader, fileErr := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, fileErr)
+	defer fileReader.Close()
+
+	assert.Equal(t, 2, fileReader.NumRowGroups())
+}
+
+func TestToParquetMaxSampleBytes(t *testing.T) {
+	geojsonFile, openErr := os.Open("testdata/ten-points.geojson")
+	require.NoError(t, openErr)
+
+	parquetBuffer := &bytes.Buffer{}
+	toParquetErr := geojson.ToParquet(geojsonFile, parquetBuffer, &geojson.ConvertOptions{
+		MinFeatures:    10,
+		MaxFeatures:    10,
+		MaxSampleBytes: 1,
+	})
+	require.NoError(t, toParquetErr)
+
+	fileReader, fileErr := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, fileErr)
+	defer fileReader.Close()
+
+	assert.Equal(t, int64(10), fileReader.NumRows())
+}
+
+func TestToParquetMaxSampleBytesNotReady(t *testing.T) {
+	inputData, readErr := os.ReadFile("testdata/sparse-properties.geojson")
+	require.NoError(t, readErr)
+
+	parquetBuffer := &bytes.Buffer{}
+	toParquetErr := geojson.ToParquet(bytes.NewReader(inputData), parquetBuffer, &geojson.ConvertOptions{
+		MinFeatures:    10,
+		MaxFeatures:    10,
+		MaxSampleBytes: 1,
+	})
+	require.NoError(t, toParquetErr)
+
+	fileReader, fileErr := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, fileErr)
+	defer fileReader.Close()
+
+	// only the first feature is sampled, so the other properties are JSON
+	parquetSchema := fileReader.MetaData().Schema
+	assert.Equal(t, "String", parquetSchema.Column(parquetSchema.ColumnIndexByName("first")).LogicalType().String())
+
+	jsonBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), jsonBuffer, nil))
+	assert.JSONEq(t, string(inputData), jsonBuffer.String())
+}
+
+func TestToParquetNullFirst(t *testing.T) {
+	inputData, readErr := os.ReadFile("testdata/null-first.geojson")
+	require.NoError(t, readErr)
+
+	parquetBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.ToParquet(bytes.NewReader(inputData), parquetBuffer, nil))
+
+	recordReader, rrErr := geoparquet.NewRecordReader(&geoparquet.ReaderConfig{
+		Reader: bytes.NewReader(parquetBuffer.Bytes()),
+	})
+	require.NoError(t, rrErr)
+	defer recordReader.Close()
+
+	record, readErr := recordReader.Read()
+	require.NoError(t, readErr)
+	defer record.Release()
+
+	arrowSchema := record.Schema()
+	for _, name := range []string{"count", "label"} {
+		indices := arrowSchema.FieldIndices(name)
+		require.Len(t, indices, 1)
+		field := arrowSchema.Field(indices[0])
+		assert.True(t, pqutil.IsJSONField(field), name)
+		assert.True(t, field.Nullable, name)
+	}
+
+	jsonBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), jsonBuffer, nil))
+	assert.JSONEq(t, string(inputData), jsonBuffer.String())
+}
+
+func TestToParquetPartitionCellSize(t *testing.T) {
+	geojsonFile, openErr := os.Open("testdata/ten-points.geojson")
+	require.NoError(t, openErr)
+
+	parquetBuffer := &bytes.Buffer{}
+	toParquetErr := geojson.ToParquet(geojsonFile, parquetBuffer, &geojson.ConvertOptions{
+		PartitionCellSize: 3,
+	})
+	require.NoError(t, toParquetErr)
+
+	fileReader, fileErr := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, fileErr)
+	defer fileReader.Close()
+
+	require.Equal(t, 4, fileReader.NumRowGroups())
+	rowGroupLengths := []int64{}
+	for i := 0; i < fileReader.NumRowGroups(); i += 1 {
+		rowGroupLengths = append(rowGroupLengths, fileReader.RowGroup(i).NumRows())
+	}
+	assert.Equal(t, []int64{3, 3, 3, 1}, rowGroupLengths)
+
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0, 0, 9, 9}, metadata.Columns[metadata.PrimaryColumn].Bounds)
+}
+
+func TestToParquetInvalidPartitionCellSize(t *testing.T) {
+	geojsonFile, openErr := os.Open("testdata/ten-points.geojson")
+	require.NoError(t, openErr)
+
+	err := geojson.ToParquet(geojsonFile, &bytes.Buffer{}, &geojson.ConvertOptions{
+		PartitionCellSize: -1,
+	})
+	assert.ErrorContains(t, err, "partition cell size must be a positive number")
+}
+
+func TestToParquetMixedDimensions(t *testing.T) {
+	geojsonFile, openErr := os.Open("testdata/mixed-dimensions.geojson")
+	require.NoError(t, openErr)
+
+	err := geojson.ToParquet(geojsonFile, &bytes.Buffer{}, nil)
+	assert.ErrorIs(t, err, geojson.ErrMixedDimensions)
+	assert.ErrorContains(t, err, "found both 2D and 3D coordinates (at feature 2)")
+	assert.NotContains(t, err.Error(), "--force-2d")
+}
+
+func TestToParquetMixedDimensionsForce2D(t *testing.T) {
+	geojsonFile, openErr := os.Open("testdata/mixed-dimensions.geojson")
+	require.NoError(t, openErr)
+
+	parquetBuffer := &bytes.Buffer{}
+	toParquetErr := geojson.ToParquet(geojsonFile, parquetBuffer, &geojson.ConvertOptions{
+		Force2D: true,
+	})
+	require.NoError(t, toParquetErr)
+
+	fileReader, fileErr := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, fileErr)
+	defer fileReader.Close()
+
+	assert.Equal(t, int64(2), fileReader.NumRows())
+
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 2, 3, 4}, metadata.Columns[metadata.PrimaryColumn].Bounds)
+}
+
+func TestToParquetUniformZ(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "properties": {}, "geometry": {"type": "Point", "coordinates": [1, 2, 3]}},
+			{"type": "Feature", "properties": {}, "geometry": {"type": "LineString", "coordinates": [[1, 2, 3], [4, 5, 6]]}}
+		]
+	}`
+
+	err := geojson.ToParquet(strings.NewReader(input), &bytes.Buffer{}, nil)
+	assert.NoError(t, err)
+}
+
+func TestToParquetFlattenCollections(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "collection"},
+				"geometry": {
+					"type": "GeometryCollection",
+					"geometries": [
+						{"type": "Point", "coordinates": [1, 2]},
+						{
+							"type": "GeometryCollection",
+							"geometries": [{"type": "LineString", "coordinates": [[0, 0], [3, 4]]}]
+						}
+					]
+				}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "empty"},
+				"geometry": {"type": "GeometryCollection", "geometries": []}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "point"},
+				"geometry": {"type": "Point", "coordinates": [5, 6]}
+			}
+		]
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, &geojson.ConvertOptions{FlattenCollections: true}))
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, err)
+	defer fileReader.Close()
+
+	metadata, err := geoparquet.GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Point", "LineString"}, metadata.Columns[metadata.PrimaryColumn].GetGeometryTypes())
+
+	output := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), output, nil))
+
+	expected := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "collection"},
+				"geometry": {"type": "Point", "coordinates": [1, 2]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "collection"},
+				"geometry": {"type": "LineString", "coordinates": [[0, 0], [3, 4]]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "empty"},
+				"geometry": null
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "point"},
+				"geometry": {"type": "Point", "coordinates": [5, 6]}
+			}
+		]
+	}`
+	assert.JSONEq(t, expected, output.String())
+}
+
+func TestToParquetFlattenCollectionsIdFromIndex(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "collection"},
+				"geometry": {
+					"type": "GeometryCollection",
+					"geometries": [
+						{"type": "Point", "coordinates": [1, 2]},
+						{"type": "Point", "coordinates": [3, 4]}
+					]
+				}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "point"},
+				"geometry": {"type": "Point", "coordinates": [5, 6]}
+			}
+		]
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, &geojson.ConvertOptions{
+		FlattenCollections: true,
+		IdFromIndex:        true,
+	}))
 
-	parquetInput := bytes.NewReader(parquetBuffer.Bytes())
-	fileReader, fileErr := file.NewParquetReader(parquetInput)
-	require.NoError(t, fileErr)
-	defer fileReader.Close()
+	output := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), output, &geojson.FromParquetOptions{
+		IdColumn: geojson.IndexIdColumn,
+	}))
 
-	assert.Equal(t, 2, fileReader.NumRowGroups())
+	expected := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"id": 0,
+				"properties": {"name": "collection"},
+				"geometry": {"type": "Point", "coordinates": [1, 2]}
+			},
+			{
+				"type": "Feature",
+				"id": 1,
+				"properties": {"name": "collection"},
+				"geometry": {"type": "Point", "coordinates": [3, 4]}
+			},
+			{
+				"type": "Feature",
+				"id": 2,
+				"properties": {"name": "point"},
+				"geometry": {"type": "Point", "coordinates": [5, 6]}
+			}
+		]
+	}`
+	assert.JSONEq(t, expected, output.String())
 }
 
 func TestToParquetMismatchedTypes(t *testing.T) {
@@ -394,7 +1005,7 @@ func TestRoundTripRepeatedProps(t *testing.T) {
 	parquetInput := bytes.NewReader(parquetBuffer.Bytes())
 
 	jsonBuffer := &bytes.Buffer{}
-	convertErr := geojson.FromParquet(parquetInput, jsonBuffer)
+	convertErr := geojson.FromParquet(parquetInput, jsonBuffer, nil)
 	require.NoError(t, convertErr)
 
 	assert.JSONEq(t, string(inputData), jsonBuffer.String())
@@ -413,7 +1024,7 @@ func TestRoundTripNestedProps(t *testing.T) {
 	parquetInput := bytes.NewReader(parquetBuffer.Bytes())
 
 	jsonBuffer := &bytes.Buffer{}
-	convertErr := geojson.FromParquet(parquetInput, jsonBuffer)
+	convertErr := geojson.FromParquet(parquetInput, jsonBuffer, nil)
 	require.NoError(t, convertErr)
 
 	assert.JSONEq(t, string(inputData), jsonBuffer.String())
@@ -432,7 +1043,7 @@ func TestRoundTripNullGeometry(t *testing.T) {
 	parquetInput := bytes.NewReader(parquetBuffer.Bytes())
 
 	jsonBuffer := &bytes.Buffer{}
-	convertErr := geojson.FromParquet(parquetInput, jsonBuffer)
+	convertErr := geojson.FromParquet(parquetInput, jsonBuffer, nil)
 	require.NoError(t, convertErr)
 
 	assert.JSONEq(t, string(inputData), jsonBuffer.String())
@@ -451,12 +1062,242 @@ func TestRoundTripSparseProperties(t *testing.T) {
 	parquetInput := bytes.NewReader(parquetBuffer.Bytes())
 
 	jsonBuffer := &bytes.Buffer{}
-	convertErr := geojson.FromParquet(parquetInput, jsonBuffer)
+	convertErr := geojson.FromParquet(parquetInput, jsonBuffer, nil)
 	require.NoError(t, convertErr)
 
 	assert.JSONEq(t, string(inputData), jsonBuffer.String())
 }
 
+func TestRoundTripJSONProperties(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {
+					"name": "first",
+					"extra": {"answer": 42}
+				},
+				"geometry": {"type": "Point", "coordinates": [0, 0]}
+			},
+			{
+				"type": "Feature",
+				"properties": {
+					"name": "second",
+					"extra": {"tags": ["a", "b"], "nested": {"deep": [{"ok": true}]}}
+				},
+				"geometry": {"type": "Point", "coordinates": [1, 1]}
+			},
+			{
+				"type": "Feature",
+				"properties": {
+					"name": "third",
+					"extra": null
+				},
+				"geometry": {"type": "Point", "coordinates": [2, 2]}
+			}
+		]
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	convertOptions := &geojson.ConvertOptions{MinFeatures: 1, MaxFeatures: 10, JSONProperties: true}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, convertOptions))
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, err)
+	defer fileReader.Close()
+	extra := fileReader.MetaData().Schema.Column(fileReader.MetaData().Schema.ColumnIndexByName("extra"))
+	assert.Equal(t, parquet.Types.ByteArray, extra.PhysicalType())
+
+	jsonBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), jsonBuffer, nil))
+	assert.JSONEq(t, input, jsonBuffer.String())
+}
+
+func TestRoundTripForeignMembers(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"title": "first",
+				"when": {"start": "2024-01-01", "tags": ["a", "b"]},
+				"properties": {"name": "first"},
+				"geometry": {"type": "Point", "coordinates": [0, 0]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "second"},
+				"geometry": {"type": "Point", "coordinates": [1, 1]}
+			}
+		]
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	convertOptions := &geojson.ConvertOptions{MinFeatures: 1, MaxFeatures: 10, ForeignMembers: true}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, convertOptions))
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, err)
+	defer fileReader.Close()
+	assert.GreaterOrEqual(t, fileReader.MetaData().Schema.ColumnIndexByName(geojson.ForeignMembersColumn), 0)
+
+	jsonBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), jsonBuffer, nil))
+	assert.JSONEq(t, input, jsonBuffer.String())
+}
+
+func TestRoundTripForeignMembersSingleFeature(t *testing.T) {
+	input := `{
+		"type": "Feature",
+		"title": "only",
+		"properties": {"name": "only"},
+		"geometry": {"type": "Point", "coordinates": [1, 2]}
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	convertOptions := &geojson.ConvertOptions{MinFeatures: 1, MaxFeatures: 10, ForeignMembers: true}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, convertOptions))
+
+	jsonBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), jsonBuffer, nil))
+	expected := `{"type": "FeatureCollection", "features": [` + input + `]}`
+	assert.JSONEq(t, expected, jsonBuffer.String())
+}
+
+func TestToParquetWithoutForeignMembers(t *testing.T) {
+	input := `{
+		"type": "Feature",
+		"title": "dropped",
+		"properties": {"name": "only"},
+		"geometry": {"type": "Point", "coordinates": [1, 2]}
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	convertOptions := &geojson.ConvertOptions{MinFeatures: 1, MaxFeatures: 10}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, convertOptions))
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, err)
+	defer fileReader.Close()
+	assert.Equal(t, -1, fileReader.MetaData().Schema.ColumnIndexByName(geojson.ForeignMembersColumn))
+}
+
+func TestRoundTripIdFromIndex(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "first"},
+				"geometry": {"type": "Point", "coordinates": [0, 0]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "second"},
+				"geometry": {"type": "Point", "coordinates": [1, 1]}
+			}
+		]
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	convertOptions := &geojson.ConvertOptions{MinFeatures: 1, MaxFeatures: 10, IdFromIndex: true}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, convertOptions))
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, err)
+	defer fileReader.Close()
+	schema := fileReader.MetaData().Schema
+	id := schema.Column(schema.ColumnIndexByName(geojson.IndexIdColumn))
+	assert.Equal(t, parquet.Types.Int64, id.PhysicalType())
+
+	jsonBuffer := &bytes.Buffer{}
+	fromParquetOptions := &geojson.FromParquetOptions{IdColumn: geojson.IndexIdColumn}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), jsonBuffer, fromParquetOptions))
+
+	expected := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"id": 0,
+				"properties": {"name": "first"},
+				"geometry": {"type": "Point", "coordinates": [0, 0]}
+			},
+			{
+				"type": "Feature",
+				"id": 1,
+				"properties": {"name": "second"},
+				"geometry": {"type": "Point", "coordinates": [1, 1]}
+			}
+		]
+	}`
+	assert.JSONEq(t, expected, jsonBuffer.String())
+}
+
+func TestToParquetIdFromIndexConflict(t *testing.T) {
+	input := `{
+		"type": "Feature",
+		"properties": {"id": "taken"},
+		"geometry": {"type": "Point", "coordinates": [0, 0]}
+	}`
+
+	convertOptions := &geojson.ConvertOptions{MinFeatures: 1, MaxFeatures: 10, IdFromIndex: true}
+	err := geojson.ToParquet(strings.NewReader(input), &bytes.Buffer{}, convertOptions)
+	assert.EqualError(t, err, `the "id" property conflicts with the column used for ids`)
+}
+
+func TestRoundTripCollectionName(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"name": "places",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "first"},
+				"geometry": {"type": "Point", "coordinates": [0, 0]}
+			}
+		]
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	convertOptions := &geojson.ConvertOptions{MinFeatures: 1, MaxFeatures: 10, KeepCollectionName: true}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, convertOptions))
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, err)
+	defer fileReader.Close()
+	name := fileReader.MetaData().KeyValueMetadata().FindValue(geojson.CollectionNameKey)
+	require.NotNil(t, name)
+	assert.Equal(t, "places", *name)
+
+	jsonBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), jsonBuffer, nil))
+	assert.JSONEq(t, input, jsonBuffer.String())
+}
+
+func TestToParquetWithoutCollectionName(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"name": "places",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "first"},
+				"geometry": {"type": "Point", "coordinates": [0, 0]}
+			}
+		]
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, nil))
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, err)
+	defer fileReader.Close()
+	assert.Nil(t, fileReader.MetaData().KeyValueMetadata().FindValue(geojson.CollectionNameKey))
+}
+
 func makeGeoParquetReader[T any](rows []T, metadata *geoparquet.Metadata) (*bytes.Reader, error) {
 	data, err := json.Marshal(rows)
 	if err != nil {
@@ -507,22 +1348,118 @@ func TestWKT(t *testing.T) {
 	rows := []*Row{
 		{
 			Name:     "test-point",
-			Geometry: "POINT (1 2)",
-		},
-		{
-			Name:     "test-line",
-			Geometry: "LINESTRING (30 10, 10 30, 40 40)",
+			Geometry: "POINT (1 2)",
+		},
+		{
+			Name:     "test-line",
+			Geometry: "LINESTRING (30 10, 10 30, 40 40)",
+		},
+	}
+
+	metadata := geoparquet.DefaultMetadata()
+	metadata.Columns[metadata.PrimaryColumn].Encoding = geo.EncodingWKT
+
+	reader, readerErr := makeGeoParquetReader(rows, metadata)
+	require.NoError(t, readerErr)
+
+	output := &bytes.Buffer{}
+	convertErr := geojson.FromParquet(reader, output, nil)
+	require.NoError(t, convertErr)
+
+	expected := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {
+					"name": "test-point"
+				},
+				"geometry": {
+					"type": "Point",
+					"coordinates": [1, 2]
+				}
+			},
+			{
+				"type": "Feature",
+				"properties": {
+					"name": "test-line"
+				},
+				"geometry": {
+					"type": "LineString",
+					"coordinates": [[30, 10], [10, 30], [40, 40]]
+				}
+			}
+		]
+	}`
+
+	assert.JSONEq(t, expected, output.String())
+}
+
+func TestWKTNoEncoding(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry string `parquet:"name=geometry, logical=String" json:"geometry"`
+	}
+
+	rows := []*Row{
+		{
+			Name:     "test-point",
+			Geometry: "POINT (1 2)",
+		},
+	}
+
+	metadata := geoparquet.DefaultMetadata()
+	metadata.Columns[metadata.PrimaryColumn].Encoding = ""
+
+	reader, readerErr := makeGeoParquetReader(rows, metadata)
+	require.NoError(t, readerErr)
+
+	output := &bytes.Buffer{}
+	convertErr := geojson.FromParquet(reader, output, nil)
+	require.NoError(t, convertErr)
+
+	expected := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {
+					"name": "test-point"
+				},
+				"geometry": {
+					"type": "Point",
+					"coordinates": [1, 2]
+				}
+			}
+		]
+	}`
+
+	assert.JSONEq(t, expected, output.String())
+}
+
+func TestWKB(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry []byte `parquet:"name=geometry" json:"geometry"`
+	}
+
+	point, pointErr := wkb.Marshal(orb.Point{1, 2})
+	require.NoError(t, pointErr)
+
+	rows := []*Row{
+		{
+			Name:     "test-point",
+			Geometry: point,
 		},
 	}
 
 	metadata := geoparquet.DefaultMetadata()
-	metadata.Columns[metadata.PrimaryColumn].Encoding = geo.EncodingWKT
 
 	reader, readerErr := makeGeoParquetReader(rows, metadata)
 	require.NoError(t, readerErr)
 
 	output := &bytes.Buffer{}
-	convertErr := geojson.FromParquet(reader, output)
+	convertErr := geojson.FromParquet(reader, output, nil)
 	require.NoError(t, convertErr)
 
 	expected := `{
@@ -537,16 +1474,6 @@ func TestWKT(t *testing.T) {
 					"type": "Point",
 					"coordinates": [1, 2]
 				}
-			},
-			{
-				"type": "Feature",
-				"properties": {
-					"name": "test-line"
-				},
-				"geometry": {
-					"type": "LineString",
-					"coordinates": [[30, 10], [10, 30], [40, 40]]
-				}
 			}
 		]
 	}`
@@ -554,27 +1481,29 @@ func TestWKT(t *testing.T) {
 	assert.JSONEq(t, expected, output.String())
 }
 
-func TestWKTNoEncoding(t *testing.T) {
+func TestEWKB(t *testing.T) {
 	type Row struct {
 		Name     string `parquet:"name=name, logical=String" json:"name"`
-		Geometry string `parquet:"name=geometry, logical=String" json:"geometry"`
+		Geometry []byte `parquet:"name=geometry" json:"geometry"`
 	}
 
+	point, pointErr := ewkb.Marshal(orb.Point{1, 2}, 4326)
+	require.NoError(t, pointErr)
+
 	rows := []*Row{
 		{
 			Name:     "test-point",
-			Geometry: "POINT (1 2)",
+			Geometry: point,
 		},
 	}
 
 	metadata := geoparquet.DefaultMetadata()
-	metadata.Columns[metadata.PrimaryColumn].Encoding = ""
 
 	reader, readerErr := makeGeoParquetReader(rows, metadata)
 	require.NoError(t, readerErr)
 
 	output := &bytes.Buffer{}
-	convertErr := geojson.FromParquet(reader, output)
+	convertErr := geojson.FromParquet(reader, output, nil)
 	require.NoError(t, convertErr)
 
 	expected := `{
@@ -596,7 +1525,7 @@ func TestWKTNoEncoding(t *testing.T) {
 	assert.JSONEq(t, expected, output.String())
 }
 
-func TestWKB(t *testing.T) {
+func TestWKBNoEncoding(t *testing.T) {
 	type Row struct {
 		Name     string `parquet:"name=name, logical=String" json:"name"`
 		Geometry []byte `parquet:"name=geometry" json:"geometry"`
@@ -613,12 +1542,13 @@ func TestWKB(t *testing.T) {
 	}
 
 	metadata := geoparquet.DefaultMetadata()
+	metadata.Columns[metadata.PrimaryColumn].Encoding = ""
 
 	reader, readerErr := makeGeoParquetReader(rows, metadata)
 	require.NoError(t, readerErr)
 
 	output := &bytes.Buffer{}
-	convertErr := geojson.FromParquet(reader, output)
+	convertErr := geojson.FromParquet(reader, output, nil)
 	require.NoError(t, convertErr)
 
 	expected := `{
@@ -640,30 +1570,39 @@ func TestWKB(t *testing.T) {
 	assert.JSONEq(t, expected, output.String())
 }
 
-func TestWKBNoEncoding(t *testing.T) {
-	type Row struct {
-		Name     string `parquet:"name=name, logical=String" json:"name"`
-		Geometry []byte `parquet:"name=geometry" json:"geometry"`
-	}
-
+func TestWKBFixedLenByteArray(t *testing.T) {
 	point, pointErr := wkb.Marshal(orb.Point{1, 2})
 	require.NoError(t, pointErr)
 
-	rows := []*Row{
-		{
-			Name:     "test-point",
-			Geometry: point,
-		},
-	}
+	arrowSchema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "geometry", Type: &arrow.FixedSizeBinaryType{ByteWidth: len(point)}, Nullable: true},
+	}, nil)
 
-	metadata := geoparquet.DefaultMetadata()
-	metadata.Columns[metadata.PrimaryColumn].Encoding = ""
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, arrowSchema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).Append("test-point")
+	builder.Field(1).(*array.FixedSizeBinaryBuilder).Append(point)
+	record := builder.NewRecord()
+	defer record.Release()
 
-	reader, readerErr := makeGeoParquetReader(rows, metadata)
-	require.NoError(t, readerErr)
+	parquetBuffer := &bytes.Buffer{}
+	recordWriter, writerErr := geoparquet.NewRecordWriter(&geoparquet.WriterConfig{
+		Writer:      parquetBuffer,
+		ArrowSchema: arrowSchema,
+	})
+	require.NoError(t, writerErr)
+	require.NoError(t, recordWriter.Write(record))
+	require.NoError(t, recordWriter.Close())
+
+	fileReader, fileErr := file.NewParquetReader(bytes.NewReader(parquetBuffer.Bytes()))
+	require.NoError(t, fileErr)
+	col, ok := pqutil.LookupPrimitiveNode(fileReader.MetaData().Schema, "geometry")
+	require.True(t, ok)
+	assert.Equal(t, parquet.Types.FixedLenByteArray, col.PhysicalType())
 
 	output := &bytes.Buffer{}
-	convertErr := geojson.FromParquet(reader, output)
+	convertErr := geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), output, nil)
 	require.NoError(t, convertErr)
 
 	expected := `{
@@ -774,3 +1713,402 @@ func TestCodecInvalid(t *testing.T) {
 	toParquetErr := geojson.ToParquet(geojsonFile, parquetBuffer, convertOptions)
 	assert.EqualError(t, toParquetErr, "invalid compression codec invalid")
 }
+
+func TestFromParquetCollectionBboxFromMetadata(t *testing.T) {
+	reader, openErr := os.Open("../testdata/cases/example-v0.4.0.parquet")
+	require.NoError(t, openErr)
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(reader, buffer, &geojson.FromParquetOptions{CollectionBbox: true}))
+
+	collection := map[string]any{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &collection))
+	assert.Equal(t, []any{-180.0, -90.0, 180.0, 83.6451}, collection["bbox"])
+	assert.Len(t, collection["features"], 5)
+}
+
+func TestFromParquetCollectionBboxComputed(t *testing.T) {
+	arrowSchema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "geometry", Type: arrow.BinaryTypes.Binary, Nullable: true},
+	}, nil)
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, arrowSchema)
+	defer builder.Release()
+
+	points := []orb.Point{{1, 2}, {-3, 4}, {5, -6}}
+	for i, point := range points {
+		data, err := wkb.Marshal(point)
+		require.NoError(t, err)
+		builder.Field(0).(*array.StringBuilder).Append(fmt.Sprintf("point-%d", i))
+		builder.Field(1).(*array.BinaryBuilder).Append(data)
+	}
+	builder.Field(0).(*array.StringBuilder).Append("null-geometry")
+	builder.Field(1).(*array.BinaryBuilder).AppendNull()
+	record := builder.NewRecord()
+	defer record.Release()
+
+	parquetBuffer := &bytes.Buffer{}
+	recordWriter, writerErr := geoparquet.NewRecordWriter(&geoparquet.WriterConfig{
+		Writer:      parquetBuffer,
+		ArrowSchema: arrowSchema,
+	})
+	require.NoError(t, writerErr)
+	require.NoError(t, recordWriter.Write(record))
+	require.NoError(t, recordWriter.Close())
+
+	output := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), output, &geojson.FromParquetOptions{CollectionBbox: true}))
+
+	collection := map[string]any{}
+	require.NoError(t, json.Unmarshal(output.Bytes(), &collection))
+	assert.Equal(t, []any{-3.0, -6.0, 5.0, 4.0}, collection["bbox"])
+	assert.Len(t, collection["features"], 4)
+}
+
+func TestFromParquetGeometryPrecision(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "one"},
+				"geometry": {"type": "Point", "coordinates": [1.23456, -2.34567]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "two"},
+				"geometry": {"type": "Point", "coordinates": [-3.45678, 4.56789]}
+			}
+		]
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, nil))
+
+	precision := 1
+	output := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), output, &geojson.FromParquetOptions{
+		CollectionBbox:    true,
+		GeometryPrecision: &precision,
+	}))
+
+	collection := map[string]any{}
+	require.NoError(t, json.Unmarshal(output.Bytes(), &collection))
+	assert.Equal(t, []any{-3.5, -2.3, 1.2, 4.6}, collection["bbox"])
+	features := collection["features"].([]any)
+	require.Len(t, features, 2)
+	assert.Equal(t, []any{1.2, -2.3}, features[0].(map[string]any)["geometry"].(map[string]any)["coordinates"])
+	assert.Equal(t, []any{-3.5, 4.6}, features[1].(map[string]any)["geometry"].(map[string]any)["coordinates"])
+}
+
+func TestFromParquetRFC7946(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "clockwise"},
+				"geometry": {
+					"type": "Polygon",
+					"coordinates": [
+						[[0, 0], [0, 10], [10, 10], [10, 0], [0, 0]],
+						[[2, 2], [4, 2], [4, 4], [2, 4], [2, 2]]
+					]
+				}
+			}
+		]
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, nil))
+
+	getCoordinates := func(options *geojson.FromParquetOptions) any {
+		output := &bytes.Buffer{}
+		require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), output, options))
+
+		collection := map[string]any{}
+		require.NoError(t, json.Unmarshal(output.Bytes(), &collection))
+		features := collection["features"].([]any)
+		require.Len(t, features, 1)
+		return features[0].(map[string]any)["geometry"].(map[string]any)["coordinates"]
+	}
+
+	asStored := []any{
+		[]any{[]any{0.0, 0.0}, []any{0.0, 10.0}, []any{10.0, 10.0}, []any{10.0, 0.0}, []any{0.0, 0.0}},
+		[]any{[]any{2.0, 2.0}, []any{4.0, 2.0}, []any{4.0, 4.0}, []any{2.0, 4.0}, []any{2.0, 2.0}},
+	}
+	assert.Equal(t, asStored, getCoordinates(nil))
+
+	rewound := []any{
+		[]any{[]any{0.0, 0.0}, []any{10.0, 0.0}, []any{10.0, 10.0}, []any{0.0, 10.0}, []any{0.0, 0.0}},
+		[]any{[]any{2.0, 2.0}, []any{2.0, 4.0}, []any{4.0, 4.0}, []any{4.0, 2.0}, []any{2.0, 2.0}},
+	}
+	assert.Equal(t, rewound, getCoordinates(&geojson.FromParquetOptions{RFC7946: true}))
+}
+
+func TestFromParquetHexWKB(t *testing.T) {
+	reader, openErr := os.Open("../testdata/cases/example-hex-wkb.parquet")
+	require.NoError(t, openErr)
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(reader, buffer, nil))
+
+	expected := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "Null Island"},
+				"geometry": {"type": "Point", "coordinates": [0, 0]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "Portland"},
+				"geometry": {"type": "Point", "coordinates": [-122.68, 45.52]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "Amsterdam"},
+				"geometry": {"type": "Point", "coordinates": [4.9, 52.37]}
+			}
+		]
+	}`
+	assert.JSONEq(t, expected, buffer.String())
+}
+
+func TestFromParquetWithoutCollectionBbox(t *testing.T) {
+	reader, openErr := os.Open("../testdata/cases/example-v0.4.0.parquet")
+	require.NoError(t, openErr)
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(reader, buffer, nil))
+
+	collection := map[string]any{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &collection))
+	assert.NotContains(t, collection, "bbox")
+}
+
+func TestFromParquetFlatten(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {
+					"name": "Null Island",
+					"address": {
+						"city": "Nowhere",
+						"location": {"ocean": "Atlantic"}
+					},
+					"visits": [{"year": 2020}]
+				},
+				"geometry": {
+					"type": "Point",
+					"coordinates": [0, 0]
+				}
+			}
+		]
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, nil))
+
+	output := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), output, &geojson.FromParquetOptions{Flatten: true}))
+
+	expected := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {
+					"name": "Null Island",
+					"address.city": "Nowhere",
+					"address.location.ocean": "Atlantic",
+					"visits": [{"year": 2020}]
+				},
+				"geometry": {
+					"type": "Point",
+					"coordinates": [0, 0]
+				}
+			}
+		]
+	}`
+
+	assert.JSONEq(t, expected, output.String())
+}
+
+func TestFromParquetPretty(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "Null Island"},
+				"geometry": {"type": "Point", "coordinates": [0, 0]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "Somewhere"},
+				"geometry": {"type": "Point", "coordinates": [1, 2]}
+			}
+		]
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, nil))
+
+	compact := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), compact, &geojson.FromParquetOptions{CollectionBbox: true}))
+	assert.NotContains(t, compact.String(), "\n")
+
+	pretty := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), pretty, &geojson.FromParquetOptions{CollectionBbox: true, Pretty: true}))
+
+	assert.True(t, json.Valid(pretty.Bytes()))
+	assert.JSONEq(t, compact.String(), pretty.String())
+	assert.True(t, strings.HasPrefix(pretty.String(), "{\n  \"type\": \"FeatureCollection\",\n  \"features\": [\n    {\n      \""))
+	assert.Contains(t, pretty.String(), "\n    },\n    {\n")
+	assert.True(t, strings.HasSuffix(pretty.String(), "\n  ],\n  \"bbox\": [0,0,1,2]\n}\n"))
+}
+
+func TestFromParquetIdColumn(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"fid": 1, "name": "Null Island", "tags": ["a"]},
+				"geometry": {"type": "Point", "coordinates": [0, 0]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"fid": 2, "name": "Somewhere", "tags": ["b"]},
+				"geometry": {"type": "Point", "coordinates": [1, 2]}
+			}
+		]
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, nil))
+
+	output := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), output, &geojson.FromParquetOptions{IdColumn: "fid"}))
+
+	expected := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"id": 1,
+				"properties": {"name": "Null Island", "tags": ["a"]},
+				"geometry": {"type": "Point", "coordinates": [0, 0]}
+			},
+			{
+				"type": "Feature",
+				"id": 2,
+				"properties": {"name": "Somewhere", "tags": ["b"]},
+				"geometry": {"type": "Point", "coordinates": [1, 2]}
+			}
+		]
+	}`
+	assert.JSONEq(t, expected, output.String())
+
+	for name, message := range map[string]string{
+		"missing":  `id column "missing" not found`,
+		"geometry": `id column "geometry" cannot be a geometry column`,
+		"tags":     `id column "tags" must be a string or number column`,
+	} {
+		err := geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), &bytes.Buffer{}, &geojson.FromParquetOptions{IdColumn: name})
+		assert.ErrorContains(t, err, message)
+	}
+}
+
+func TestFromParquetWKTProperty(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "Null Island"},
+				"geometry": {"type": "Point", "coordinates": [0, 0]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "Nowhere"},
+				"geometry": null
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "Somewhere"},
+				"geometry": {"type": "LineString", "coordinates": [[1, 2], [3, 4]]}
+			}
+		]
+	}`
+
+	parquetBuffer := &bytes.Buffer{}
+	require.NoError(t, geojson.ToParquet(strings.NewReader(input), parquetBuffer, nil))
+
+	output := &bytes.Buffer{}
+	require.NoError(t, geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), output, &geojson.FromParquetOptions{WKTProperty: "wkt"}))
+
+	expected := `{
+		"type": "FeatureCollection",
+		"features": [
+			{
+				"type": "Feature",
+				"properties": {"name": "Null Island", "wkt": "POINT(0 0)"},
+				"geometry": {"type": "Point", "coordinates": [0, 0]}
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "Nowhere", "wkt": null},
+				"geometry": null
+			},
+			{
+				"type": "Feature",
+				"properties": {"name": "Somewhere", "wkt": "LINESTRING(1 2,3 4)"},
+				"geometry": {"type": "LineString", "coordinates": [[1, 2], [3, 4]]}
+			}
+		]
+	}`
+	assert.JSONEq(t, expected, output.String())
+
+	err := geojson.FromParquet(bytes.NewReader(parquetBuffer.Bytes()), &bytes.Buffer{}, &geojson.FromParquetOptions{WKTProperty: "name"})
+	assert.EqualError(t, err, `WKT property "name" conflicts with an existing property`)
+}
+
+func TestToParquetStableMetadata(t *testing.T) {
+	input := `{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "properties": {"name": "a"}, "geometry": {"type": "Point", "coordinates": [0, 0]}},
+			{"type": "Feature", "properties": {"name": "b"}, "geometry": {"type": "LineString", "coordinates": [[0, 0], [1, 1]]}},
+			{"type": "Feature", "properties": {"name": "c"}, "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}},
+			{"type": "Feature", "properties": {"name": "d"}, "geometry": {"type": "MultiPoint", "coordinates": [[0, 0], [1, 1]]}},
+			{"type": "Feature", "properties": {"name": "e"}, "geometry": {"type": "MultiLineString", "coordinates": [[[0, 0], [1, 1]]]}},
+			{"type": "Feature", "properties": {"name": "f"}, "geometry": {"type": "MultiPolygon", "coordinates": [[[[0, 0], [1, 0], [1, 1], [0, 0]]]]}}
+		]
+	}`
+
+	var expected string
+	for i := 0; i < 10; i += 1 {
+		output := &bytes.Buffer{}
+		require.NoError(t, geojson.ToParquet(strings.NewReader(input), output, nil))
+
+		fileReader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+		require.NoError(t, err)
+		value, err := geoparquet.GetMetadataValue(fileReader.MetaData().KeyValueMetadata())
+		require.NoError(t, err)
+		require.NoError(t, fileReader.Close())
+
+		if i == 0 {
+			expected = value
+			continue
+		}
+		assert.Equal(t, expected, value)
+	}
+
+	assert.Contains(t, expected, `"geometry_types":["Point","LineString","Polygon","MultiPoint","MultiLineString","MultiPolygon"]`)
+}
@@ -2,81 +2,207 @@ package geojson
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 
 	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/wkt"
 	orbjson "github.com/paulmach/orb/geojson"
 	"github.com/planetlabs/gpq/internal/geo"
 	"github.com/planetlabs/gpq/internal/geoparquet"
+	"github.com/planetlabs/gpq/internal/pqutil"
 )
 
 type RecordWriter struct {
 	geoMetadata *geoparquet.Metadata
 	writer      io.Writer
 	writing     bool
+	options     *FromParquetOptions
+	stats       *geo.GeometryStats
+	hasBounds   bool
+	checkedId   bool
 }
 
-func NewRecordWriter(writer io.Writer, geoMetadata *geoparquet.Metadata) (*RecordWriter, error) {
-	w := &RecordWriter{writer: writer, geoMetadata: geoMetadata}
+func NewRecordWriter(writer io.Writer, geoMetadata *geoparquet.Metadata, options *FromParquetOptions) (*RecordWriter, error) {
+	if options == nil {
+		options = &FromParquetOptions{}
+	}
+	w := &RecordWriter{
+		writer:      writer,
+		geoMetadata: geoMetadata,
+		options:     options,
+		stats:       geo.NewGeometryStats(false),
+	}
 	return w, nil
 }
 
-var (
-	featureCollectionPrefix = []byte(`{"type":"FeatureCollection","features":[`)
-	arraySeparator          = []byte(",")
-	featureCollectionSuffix = []byte("]}")
-)
+// collectionFormat describes how a streamed feature collection is laid out.
+type collectionFormat struct {
+	prefix          []byte
+	separator       []byte
+	featuresSuffix  []byte
+	bboxPrefix      []byte
+	namePrefix      []byte
+	suffix          []byte
+	featureIndent   string
+	featureIndented bool
+}
+
+var compactFormat = &collectionFormat{
+	prefix:         []byte(`{"type":"FeatureCollection","features":[`),
+	separator:      []byte(","),
+	featuresSuffix: []byte("]"),
+	bboxPrefix:     []byte(`,"bbox":`),
+	namePrefix:     []byte(`,"name":`),
+	suffix:         []byte("}"),
+}
+
+var prettyFormat = &collectionFormat{
+	prefix:          []byte("{\n  \"type\": \"FeatureCollection\",\n  \"features\": [\n    "),
+	separator:       []byte(",\n    "),
+	featuresSuffix:  []byte("\n  ]"),
+	bboxPrefix:      []byte(",\n  \"bbox\": "),
+	namePrefix:      []byte(",\n  \"name\": "),
+	suffix:          []byte("\n}\n"),
+	featureIndent:   "    ",
+	featureIndented: true,
+}
+
+func (f *collectionFormat) marshal(value any) ([]byte, error) {
+	if f.featureIndented {
+		return json.MarshalIndent(value, f.featureIndent, "  ")
+	}
+	return json.Marshal(value)
+}
+
+func (w *RecordWriter) format() *collectionFormat {
+	if w.options.Pretty {
+		return prettyFormat
+	}
+	return compactFormat
+}
+
+// validateIdColumn checks that the configured id column is a string or number
+// column.
+func (w *RecordWriter) validateIdColumn(schema *arrow.Schema) error {
+	if w.checkedId || w.options.IdColumn == "" {
+		return nil
+	}
+	name := w.options.IdColumn
+	indices := schema.FieldIndices(name)
+	if len(indices) == 0 {
+		return fmt.Errorf("id column %q not found", name)
+	}
+	if _, ok := w.geoMetadata.Columns[name]; ok {
+		return fmt.Errorf("id column %q cannot be a geometry column", name)
+	}
+	field := schema.Field(indices[0])
+	typeId := field.Type.ID()
+	if !arrow.IsInteger(typeId) && !arrow.IsFloating(typeId) && typeId != arrow.STRING && typeId != arrow.LARGE_STRING {
+		return fmt.Errorf("id column %q must be a string or number column, got %s", name, field.Type)
+	}
+	w.checkedId = true
+	return nil
+}
 
 func (w *RecordWriter) Write(record arrow.Record) error {
-	if !w.writing {
-		if _, err := w.writer.Write(featureCollectionPrefix); err != nil {
-			return err
-		}
-		w.writing = true
-	} else {
-		if _, err := w.writer.Write(arraySeparator); err != nil {
-			return err
-		}
+	format := w.format()
+	schema := record.Schema()
+	if err := w.validateIdColumn(schema); err != nil {
+		return err
 	}
+
 	arr := array.RecordToStructArray(record)
 	defer arr.Release()
 
-	schema := record.Schema()
 	for rowNum := 0; rowNum < arr.Len(); rowNum += 1 {
-		if rowNum > 0 {
-			if _, err := w.writer.Write(arraySeparator); err != nil {
+		if !w.writing {
+			if _, err := w.writer.Write(format.prefix); err != nil {
+				return err
+			}
+			w.writing = true
+		} else {
+			if _, err := w.writer.Write(format.separator); err != nil {
 				return err
 			}
 		}
 
 		var geometry *orbjson.Geometry
+		var id any
+		var foreignMembers map[string]any
 		properties := map[string]any{}
 		for fieldNum := 0; fieldNum < arr.NumField(); fieldNum += 1 {
 			value := arr.Field(fieldNum).GetOneForMarshal(rowNum)
 			name := schema.Field(fieldNum).Name
+			if name == w.options.IdColumn {
+				id = value
+				continue
+			}
 			if geomColumn, ok := w.geoMetadata.Columns[name]; ok {
 				g, decodeErr := geo.DecodeGeometry(value, geomColumn.Encoding)
 				if decodeErr != nil {
 					return decodeErr
 				}
+				if g != nil && w.options.GeometryPrecision != nil {
+					g = orbjson.NewGeometry(geo.RoundGeometry(g.Geometry(), *w.options.GeometryPrecision))
+				}
+				if g != nil && w.options.RFC7946 {
+					g = orbjson.NewGeometry(geo.Rewind(g.Geometry()))
+				}
 				if name == w.geoMetadata.PrimaryColumn {
 					geometry = g
+					if w.options.CollectionBbox && g != nil {
+						w.addBounds(g.Geometry())
+					}
 					continue
 				}
 				properties[name] = g
 				continue
 			}
+			if structArr, ok := arr.Field(fieldNum).(*array.Struct); ok && w.options.Flatten {
+				flattenStruct(properties, name, structArr, rowNum, false)
+				continue
+			}
+			if pqutil.IsJSONField(schema.Field(fieldNum)) {
+				decoded, err := decodeJSONProperty(name, value)
+				if err != nil {
+					return err
+				}
+				if name == ForeignMembersColumn {
+					foreignMembers, _ = decoded.(map[string]any)
+					continue
+				}
+				properties[name] = decoded
+				continue
+			}
 			properties[name] = value
 		}
 
-		feature := map[string]any{
-			"type":       "Feature",
-			"properties": properties,
-			"geometry":   geometry,
+		if name := w.options.WKTProperty; name != "" {
+			if _, exists := properties[name]; exists {
+				return fmt.Errorf("WKT property %q conflicts with an existing property", name)
+			}
+			if geometry != nil {
+				properties[name] = wkt.MarshalString(geometry.Geometry())
+			} else {
+				properties[name] = nil
+			}
+		}
+
+		feature := map[string]any{}
+		for key, value := range foreignMembers {
+			feature[key] = value
+		}
+		feature["type"] = "Feature"
+		feature["properties"] = properties
+		feature["geometry"] = geometry
+		if id != nil {
+			feature["id"] = id
 		}
 
-		featureData, jsonErr := json.Marshal(feature)
+		featureData, jsonErr := format.marshal(feature)
 		if jsonErr != nil {
 			return jsonErr
 		}
@@ -88,9 +214,90 @@ func (w *RecordWriter) Write(record arrow.Record) error {
 	return nil
 }
 
+// decodeJSONProperty parses the value of a JSON-encoded string column.
+func decodeJSONProperty(name string, value any) (any, error) {
+	str, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	var decoded any
+	if err := json.Unmarshal([]byte(str), &decoded); err != nil {
+		return nil, fmt.Errorf("trouble decoding JSON property %q: %w", name, err)
+	}
+	return decoded, nil
+}
+
+// flattenStruct adds the struct fields as properties with dotted names.  Lists
+// of structs are left nested.
+func flattenStruct(properties map[string]any, prefix string, arr *array.Struct, rowNum int, null bool) {
+	null = null || arr.IsNull(rowNum)
+	structType := arr.DataType().(*arrow.StructType)
+	for i := 0; i < arr.NumField(); i += 1 {
+		name := prefix + "." + structType.Field(i).Name
+		field := arr.Field(i)
+		if child, ok := field.(*array.Struct); ok {
+			flattenStruct(properties, name, child, rowNum, null)
+			continue
+		}
+		if null {
+			properties[name] = nil
+			continue
+		}
+		properties[name] = field.GetOneForMarshal(rowNum)
+	}
+}
+
+func (w *RecordWriter) addBounds(geometry orb.Geometry) {
+	if geometry == nil || geo.IsEmpty(geometry) {
+		return
+	}
+	bounds := geometry.Bound()
+	w.stats.AddBounds(&bounds)
+	w.hasBounds = true
+}
+
+// collectionBbox returns the bounds from the primary column metadata if
+// available or the bounds of the geometries written so far.
+func (w *RecordWriter) collectionBbox() []float64 {
+	// the metadata bounds are not used if the coordinates were rounded
+	primary, ok := w.geoMetadata.Columns[w.geoMetadata.PrimaryColumn]
+	if ok && len(primary.Bounds) >= 4 && w.options.GeometryPrecision == nil {
+		return primary.Bounds
+	}
+	if !w.hasBounds {
+		return nil
+	}
+	bounds := w.stats.Bounds()
+	return []float64{bounds.Left(), bounds.Bottom(), bounds.Right(), bounds.Top()}
+}
+
 func (w *RecordWriter) Close() error {
+	format := w.format()
 	if w.writing {
-		if _, err := w.writer.Write(featureCollectionSuffix); err != nil {
+		if _, err := w.writer.Write(format.featuresSuffix); err != nil {
+			return err
+		}
+		if w.options.CollectionBbox {
+			if bbox := w.collectionBbox(); bbox != nil {
+				bboxData, jsonErr := json.Marshal(bbox)
+				if jsonErr != nil {
+					return jsonErr
+				}
+				if _, err := w.writer.Write(append(append([]byte{}, format.bboxPrefix...), bboxData...)); err != nil {
+					return err
+				}
+			}
+		}
+		if w.options.CollectionName != "" {
+			nameData, jsonErr := json.Marshal(w.options.CollectionName)
+			if jsonErr != nil {
+				return jsonErr
+			}
+			if _, err := w.writer.Write(append(append([]byte{}, format.namePrefix...), nameData...)); err != nil {
+				return err
+			}
+		}
+		if _, err := w.writer.Write(format.suffix); err != nil {
 			return err
 		}
 		w.writing = false
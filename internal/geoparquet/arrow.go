@@ -0,0 +1,248 @@
+package geoparquet
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/ipc"
+	"github.com/apache/arrow/go/v16/parquet"
+	"github.com/apache/arrow/go/v16/parquet/metadata"
+	"github.com/planetlabs/gpq/internal/geo"
+	"github.com/planetlabs/gpq/internal/pqutil"
+)
+
+type ipcReader interface {
+	Schema() *arrow.Schema
+	Read() (arrow.Record, error)
+}
+
+// newIPCReader reads the Arrow IPC file format (Feather v2) and falls back to
+// the IPC stream format.
+func newIPCReader(input ipc.ReadAtSeeker) (ipcReader, func(), error) {
+	fileReader, fileErr := ipc.NewFileReader(input)
+	if fileErr == nil {
+		return fileReader, func() { _ = fileReader.Close() }, nil
+	}
+
+	if _, err := input.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+	streamReader, streamErr := ipc.NewReader(input)
+	if streamErr != nil {
+		return nil, nil, fmt.Errorf("trouble reading Arrow IPC input: %w", fileErr)
+	}
+	return streamReader, streamReader.Release, nil
+}
+
+func getArrowMetadata(arrowSchema *arrow.Schema, convertOptions *ConvertOptions) (*Metadata, error) {
+	var geoMetadata *Metadata
+	if value, ok := arrowSchema.Metadata().GetValue(MetadataKey); ok {
+		m, err := GetMetadata(metadata.KeyValueMetadata{{Key: MetadataKey, Value: &value}})
+		if err != nil {
+			return nil, err
+		}
+		geoMetadata = m
+	} else {
+		primaryColumn := DefaultGeometryColumn
+		if convertOptions.InputPrimaryColumn != "" {
+			primaryColumn = convertOptions.InputPrimaryColumn
+		}
+		geoMetadata = &Metadata{
+			Version:       Version,
+			PrimaryColumn: primaryColumn,
+			Columns: map[string]*GeometryColumn{
+				primaryColumn: getDefaultGeometryColumn(),
+			},
+		}
+	}
+	if convertOptions.InputPrimaryColumn != "" && geoMetadata.PrimaryColumn != convertOptions.InputPrimaryColumn {
+		geoMetadata.PrimaryColumn = convertOptions.InputPrimaryColumn
+	}
+	return geoMetadata, nil
+}
+
+// withoutGeoMetadata returns a schema without any "geo" metadata so that it is
+// not written twice.
+func withoutGeoMetadata(arrowSchema *arrow.Schema) *arrow.Schema {
+	schemaMetadata := arrowSchema.Metadata()
+	if schemaMetadata.FindKey(MetadataKey) < 0 {
+		return arrowSchema
+	}
+	keys := []string{}
+	values := []string{}
+	for i, key := range schemaMetadata.Keys() {
+		if key == MetadataKey {
+			continue
+		}
+		keys = append(keys, key)
+		values = append(values, schemaMetadata.Values()[i])
+	}
+	newMetadata := arrow.NewMetadata(keys, values)
+	return arrow.NewSchema(arrowSchema.Fields(), &newMetadata)
+}
+
+// getWriterOptions returns the Parquet writer properties for the compression,
+// row group length, and encoding options.
+func getWriterOptions(convertOptions *ConvertOptions, arrowSchema *arrow.Schema) ([]parquet.WriterProperty, error) {
+	var writerOptions []parquet.WriterProperty
+	if convertOptions.Compression != "" {
+		compression, err := pqutil.GetCompression(convertOptions.Compression)
+		if err != nil {
+			return nil, err
+		}
+		writerOptions = append(writerOptions, parquet.WithCompression(compression))
+	}
+	if convertOptions.RowGroupLength > 0 {
+		writerOptions = append(writerOptions, parquet.WithMaxRowGroupLength(int64(convertOptions.RowGroupLength)))
+	}
+	if convertOptions.DisableDictionary {
+		writerOptions = append(writerOptions, parquet.WithDictionaryDefault(false))
+	}
+	if convertOptions.DataPageSize > 0 {
+		writerOptions = append(writerOptions, parquet.WithDataPageSize(int64(convertOptions.DataPageSize)))
+	}
+	columnCompression, err := pqutil.GetColumnCompression(convertOptions.ColumnCompression)
+	if err != nil {
+		return nil, err
+	}
+	columnOptions, err := pqutil.ArrowColumnCompressionProperties(arrowSchema, columnCompression)
+	if err != nil {
+		return nil, err
+	}
+	return append(writerOptions, columnOptions...), nil
+}
+
+// FromArrow converts Arrow IPC (Feather v2) input with WKB encoded geometry
+// columns to GeoParquet.
+func FromArrow(input ipc.ReadAtSeeker, output io.Writer, convertOptions *ConvertOptions) error {
+	if convertOptions == nil {
+		convertOptions = &ConvertOptions{}
+	}
+	if err := ValidateGeometryTypes(convertOptions.GeometryTypes); err != nil {
+		return err
+	}
+	if err := ValidateEdges(convertOptions.Edges); err != nil {
+		return err
+	}
+	if err := ValidateVersion(convertOptions.Version); err != nil {
+		return err
+	}
+	if err := ValidateDataPageSize(convertOptions.DataPageSize); err != nil {
+		return err
+	}
+
+	reader, closeReader, readerErr := newIPCReader(input)
+	if readerErr != nil {
+		return readerErr
+	}
+	defer closeReader()
+
+	inputSchema := reader.Schema()
+	geoMetadata, metadataErr := getArrowMetadata(inputSchema, convertOptions)
+	if metadataErr != nil {
+		return metadataErr
+	}
+	for name, geomColumn := range geoMetadata.Columns {
+		indices := inputSchema.FieldIndices(name)
+		if len(indices) == 0 {
+			return fmt.Errorf("expected a geometry column named %q, use the --input-primary-column to supply a different primary geometry", name)
+		}
+		if !strings.EqualFold(geomColumn.Encoding, geo.EncodingWKB) {
+			return fmt.Errorf("unsupported encoding %q for column %q, only WKB is supported for Arrow input", geomColumn.Encoding, name)
+		}
+		switch inputSchema.Field(indices[0]).Type.ID() {
+		case arrow.BINARY, arrow.LARGE_BINARY, arrow.FIXED_SIZE_BINARY:
+		default:
+			return fmt.Errorf("expected a binary column for %q, got %s", name, inputSchema.Field(indices[0]).Type)
+		}
+	}
+
+	writerOptions, optionsErr := getWriterOptions(convertOptions, inputSchema)
+	if optionsErr != nil {
+		return optionsErr
+	}
+
+	outputSchema := withoutGeoMetadata(inputSchema)
+	recordWriter, writerErr := NewRecordWriter(&WriterConfig{
+		Writer:             output,
+		Metadata:           geoMetadata,
+		ArrowSchema:        outputSchema,
+		ParquetWriterProps: parquet.NewWriterProperties(writerOptions...),
+		BoundsPrecision:    convertOptions.BoundsPrecision,
+	})
+	if writerErr != nil {
+		return writerErr
+	}
+
+	datasetInfo := geo.NewDatasetStats(false)
+	for {
+		if convertOptions.Context != nil {
+			if err := convertOptions.Context.Err(); err != nil {
+				return err
+			}
+		}
+		record, readErr := reader.Read()
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+
+		for fieldNum, field := range outputSchema.Fields() {
+			if _, ok := geoMetadata.Columns[field.Name]; !ok {
+				continue
+			}
+			values := record.Column(fieldNum)
+			for rowNum := 0; rowNum < values.Len(); rowNum += 1 {
+				if values.IsNull(rowNum) {
+					continue
+				}
+				geometry, err := geo.DecodeGeometry(values.GetOneForMarshal(rowNum), geo.EncodingWKB)
+				if err != nil {
+					return fmt.Errorf("trouble decoding geometry in %q: %w", field.Name, err)
+				}
+				if geometry == nil {
+					continue
+				}
+				if !datasetInfo.HasCollection(field.Name) {
+					datasetInfo.AddCollection(field.Name)
+				}
+				if !geo.IsEmpty(geometry.Geometry()) {
+					bounds := geometry.Geometry().Bound()
+					datasetInfo.AddBounds(field.Name, &bounds)
+				}
+				datasetInfo.AddTypes(field.Name, []string{geometry.Geometry().GeoJSONType()})
+			}
+		}
+
+		if err := recordWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	for name, geomColumn := range geoMetadata.Columns {
+		if !datasetInfo.HasCollection(name) {
+			continue
+		}
+		if geomColumn.Bounds == nil {
+			bounds := datasetInfo.Bounds(name)
+			geomColumn.Bounds = []float64{
+				bounds.Left(), bounds.Bottom(), bounds.Right(), bounds.Top(),
+			}
+		}
+		if len(geomColumn.GetGeometryTypes()) == 0 {
+			geomColumn.GeometryTypes = datasetInfo.Types(name)
+		}
+	}
+
+	if convertOptions.GeometryTypes != nil {
+		geoMetadata.Columns[geoMetadata.PrimaryColumn].GeometryTypes = convertOptions.GeometryTypes
+	}
+	geoMetadata.SetEdges(convertOptions.Edges)
+	geoMetadata.SetVersion(convertOptions.Version)
+
+	return recordWriter.Close()
+}
@@ -0,0 +1,327 @@
+package geoparquet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/parquet"
+	"github.com/apache/arrow/go/v16/parquet/file"
+	"github.com/apache/arrow/go/v16/parquet/metadata"
+	"github.com/apache/arrow/go/v16/parquet/pqarrow"
+	"github.com/apache/arrow/go/v16/parquet/schema"
+)
+
+type DatasetConfig struct {
+	BatchSize int
+	Readers   []parquet.ReaderAtSeeker
+	Context   context.Context
+}
+
+// DatasetReader reads the records from a number of GeoParquet files (e.g. the
+// part files in a directory) in sequence as if they were a single file.  All
+// parts must have the same schema and compatible geo metadata.
+type DatasetReader struct {
+	batchSize    int
+	ctx          context.Context
+	fileReaders  []*file.Reader
+	metadata     *Metadata
+	current      int
+	recordReader *RecordReader
+}
+
+func NewDatasetReader(config *DatasetConfig) (*DatasetReader, error) {
+	if len(config.Readers) == 0 {
+		return nil, errors.New("config must include at least one reader")
+	}
+
+	ctx := config.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	reader := &DatasetReader{
+		batchSize: config.BatchSize,
+		ctx:       ctx,
+	}
+
+	for i, input := range config.Readers {
+		fileReader, err := file.NewParquetReader(input)
+		if err != nil {
+			_ = reader.Close()
+			return nil, fmt.Errorf("trouble reading part %d: %w", i, err)
+		}
+		reader.fileReaders = append(reader.fileReaders, fileReader)
+
+		partMetadata, err := GetMetadata(fileReader.MetaData().KeyValueMetadata())
+		if err != nil {
+			_ = reader.Close()
+			return nil, fmt.Errorf("trouble reading metadata from part %d: %w", i, err)
+		}
+
+		if i == 0 {
+			reader.metadata = partMetadata
+			continue
+		}
+
+		if !fileReader.MetaData().Schema.Equals(reader.fileReaders[0].MetaData().Schema) {
+			_ = reader.Close()
+			return nil, fmt.Errorf("the schema of part %d does not match the schema of the first part", i)
+		}
+
+		merged, err := mergeMetadata(reader.metadata, partMetadata)
+		if err != nil {
+			_ = reader.Close()
+			return nil, fmt.Errorf("the metadata of part %d is not compatible with the other parts: %w", i, err)
+		}
+		reader.metadata = merged
+	}
+
+	return reader, nil
+}
+
+// mergeMetadata combines the metadata from two parts of a dataset.  The geometry
+// columns must have the same encoding, CRS, edges, and orientation.  The bounds
+// and geometry types are combined.
+func mergeMetadata(a *Metadata, b *Metadata) (*Metadata, error) {
+	if a.PrimaryColumn != b.PrimaryColumn {
+		return nil, fmt.Errorf("primary column %q does not match %q", b.PrimaryColumn, a.PrimaryColumn)
+	}
+	if len(a.Columns) != len(b.Columns) {
+		return nil, fmt.Errorf("found %d geometry columns, expected %d", len(b.Columns), len(a.Columns))
+	}
+
+	merged := a.Clone()
+	for name, colA := range a.Columns {
+		colB, ok := b.Columns[name]
+		if !ok {
+			return nil, fmt.Errorf("missing geometry column %q", name)
+		}
+		if colA.Encoding != colB.Encoding {
+			return nil, fmt.Errorf("geometry column %q has encoding %q, expected %q", name, colB.Encoding, colA.Encoding)
+		}
+		if colA.Edges != colB.Edges {
+			return nil, fmt.Errorf("geometry column %q has edges %q, expected %q", name, colB.Edges, colA.Edges)
+		}
+		if colA.Orientation != colB.Orientation {
+			return nil, fmt.Errorf("geometry column %q has orientation %q, expected %q", name, colB.Orientation, colA.Orientation)
+		}
+		if !sameCRS(colA.CRS, colB.CRS) {
+			return nil, fmt.Errorf("geometry column %q has a different crs", name)
+		}
+
+		col := merged.Columns[name]
+		col.GeometryType = nil
+		col.GeometryTypes = mergeGeometryTypes(colA.GetGeometryTypes(), colB.GetGeometryTypes())
+		col.Bounds = mergeBounds(colA.Bounds, colB.Bounds)
+	}
+
+	return merged, nil
+}
+
+func sameCRS(a *Proj, b *Proj) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && string(aData) == string(bData)
+}
+
+// mergeGeometryTypes returns the union of two lists of geometry types.  An
+// empty list means any type is allowed, so the result is empty if either is.
+func mergeGeometryTypes(a []string, b []string) []string {
+	if len(a) == 0 || len(b) == 0 {
+		return []string{}
+	}
+	types := slices.Clone(a)
+	for _, geometryType := range b {
+		if !slices.Contains(types, geometryType) {
+			types = append(types, geometryType)
+		}
+	}
+	slices.SortStableFunc(types, compareGeometryTypes)
+	return types
+}
+
+// mergeBounds returns the union of two bounding boxes.  If either is missing
+// or they have a different number of dimensions, nil is returned.
+func mergeBounds(a []float64, b []float64) []float64 {
+	if len(a) == 0 || len(a) != len(b) || len(a)%2 != 0 {
+		return nil
+	}
+	dims := len(a) / 2
+	bounds := make([]float64, len(a))
+	for i := 0; i < dims; i += 1 {
+		bounds[i] = min(a[i], b[i])
+		bounds[i+dims] = max(a[i+dims], b[i+dims])
+	}
+	return bounds
+}
+
+func (r *DatasetReader) Read() (arrow.Record, error) {
+	for r.current < len(r.fileReaders) {
+		if r.recordReader == nil {
+			recordReader, err := NewRecordReader(&ReaderConfig{
+				BatchSize: r.batchSize,
+				File:      r.fileReaders[r.current],
+				Context:   r.ctx,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("trouble reading part %d: %w", r.current, err)
+			}
+			r.recordReader = recordReader
+		}
+
+		record, err := r.recordReader.Read()
+		if err != io.EOF {
+			return record, err
+		}
+
+		// the record reader closes the file reader
+		closeErr := r.recordReader.Close()
+		r.recordReader = nil
+		r.current += 1
+		if closeErr != nil {
+			return nil, closeErr
+		}
+	}
+	return nil, io.EOF
+}
+
+// Metadata returns the combined geo metadata for all parts.
+func (r *DatasetReader) Metadata() *Metadata {
+	return r.metadata
+}
+
+// KeyValueMetadata returns the key-value metadata from the first part.
+func (r *DatasetReader) KeyValueMetadata() metadata.KeyValueMetadata {
+	return r.fileReaders[0].MetaData().KeyValueMetadata()
+}
+
+// Schema returns the schema shared by all parts.
+func (r *DatasetReader) Schema() *schema.Schema {
+	return r.fileReaders[0].MetaData().Schema
+}
+
+// NumRows returns the total number of rows in all parts.
+func (r *DatasetReader) NumRows() int64 {
+	total := int64(0)
+	for _, fileReader := range r.fileReaders {
+		total += fileReader.NumRows()
+	}
+	return total
+}
+
+// NumRowGroups returns the total number of row groups in all parts.
+func (r *DatasetReader) NumRowGroups() int {
+	total := 0
+	for _, fileReader := range r.fileReaders {
+		total += fileReader.NumRowGroups()
+	}
+	return total
+}
+
+// Parts returns the file readers for all parts.  The metadata for each part
+// remains available after the part has been read.
+func (r *DatasetReader) Parts() []*file.Reader {
+	return r.fileReaders
+}
+
+func (r *DatasetReader) Close() error {
+	var errs []error
+	if r.recordReader != nil {
+		// the record reader closes the file reader for the current part
+		errs = append(errs, r.recordReader.Close())
+		r.recordReader = nil
+		r.current += 1
+	}
+	for ; r.current < len(r.fileReaders); r.current += 1 {
+		errs = append(errs, r.fileReaders[r.current].Close())
+	}
+	return errors.Join(errs...)
+}
+
+// FromDataset writes the records from a number of GeoParquet files (e.g. the
+// parts of a partitioned dataset) to a single GeoParquet file.  The output
+// metadata combines the metadata from all parts.
+func FromDataset(inputs []parquet.ReaderAtSeeker, output io.Writer, convertOptions *ConvertOptions) error {
+	if convertOptions == nil {
+		convertOptions = &ConvertOptions{}
+	}
+	if len(convertOptions.Rename) > 0 {
+		return errors.New("renaming columns is not supported when reading a dataset")
+	}
+	if convertOptions.AddCentroid != "" {
+		return errors.New("adding a centroid column is not supported when reading a dataset")
+	}
+	if err := ValidateGeometryTypes(convertOptions.GeometryTypes); err != nil {
+		return err
+	}
+	if err := ValidateEdges(convertOptions.Edges); err != nil {
+		return err
+	}
+	if err := ValidateVersion(convertOptions.Version); err != nil {
+		return err
+	}
+	if err := ValidateDataPageSize(convertOptions.DataPageSize); err != nil {
+		return err
+	}
+
+	datasetReader, readerErr := NewDatasetReader(&DatasetConfig{
+		Readers: inputs,
+		Context: convertOptions.Context,
+	})
+	if readerErr != nil {
+		return readerErr
+	}
+	defer datasetReader.Close()
+
+	firstPart := datasetReader.Parts()[0]
+	arrowSchema, schemaErr := pqarrow.FromParquet(datasetReader.Schema(), &pqarrow.ArrowReadProperties{}, firstPart.MetaData().KeyValueMetadata())
+	if schemaErr != nil {
+		return schemaErr
+	}
+
+	writerOptions, optionsErr := getWriterOptions(convertOptions, arrowSchema)
+	if optionsErr != nil {
+		return optionsErr
+	}
+
+	geoMetadata := datasetReader.Metadata().Clone()
+	geoMetadata.SetEdges(convertOptions.Edges)
+	geoMetadata.SetVersion(convertOptions.Version)
+	if convertOptions.GeometryTypes != nil {
+		geoMetadata.Columns[geoMetadata.PrimaryColumn].GeometryTypes = convertOptions.GeometryTypes
+	}
+
+	recordWriter, writerErr := NewRecordWriter(&WriterConfig{
+		Writer:             output,
+		Metadata:           geoMetadata,
+		ArrowSchema:        withoutGeoMetadata(arrowSchema),
+		ParquetWriterProps: parquet.NewWriterProperties(writerOptions...),
+		BoundsPrecision:    convertOptions.BoundsPrecision,
+	})
+	if writerErr != nil {
+		return writerErr
+	}
+
+	for {
+		record, readErr := datasetReader.Read()
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+		if err := recordWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return recordWriter.Close()
+}
@@ -0,0 +1,240 @@
+package geoparquet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow/go/v16/parquet"
+	"github.com/apache/arrow/go/v16/parquet/file"
+	"github.com/apache/arrow/go/v16/parquet/schema"
+	"github.com/planetlabs/gpq/internal/geo"
+	"github.com/planetlabs/gpq/internal/pqutil"
+)
+
+// DetectSampleSize is the number of values read from each column when
+// detecting geometry columns.
+const DetectSampleSize = 10
+
+// DetectGeometryColumns returns the names of the top-level columns that look
+// like they contain geometries.  This is useful for Parquet files without
+// "geo" metadata.  Values are sampled from the first non-empty row group.  A
+// BYTE_ARRAY or FIXED_LEN_BYTE_ARRAY column is included if all of the sampled
+// values can be decoded as WKB.  A string column is included if all of the
+// sampled values can be decoded as WKT.  Columns with only null values in the
+// sample are not included.
+func DetectGeometryColumns(fileReader *file.Reader) []string {
+	rowGroup := firstRowGroup(fileReader)
+	if rowGroup == nil {
+		return []string{}
+	}
+
+	root := fileReader.MetaData().Schema.Root()
+	names := []string{}
+	for fieldNum := 0; fieldNum < root.NumFields(); fieldNum += 1 {
+		field := root.Field(fieldNum)
+		if result, _ := detectGeometry(fileReader, rowGroup, field); result == detectedGeometry {
+			names = append(names, field.Name())
+		}
+	}
+	return names
+}
+
+// NotGeometryColumnError is returned by CheckGeometryColumn when a column does
+// not look like it contains geometries.
+type NotGeometryColumnError struct {
+	// Column is the name of the column that was checked.
+	Column string
+
+	// Candidates are the names of other columns that look like geometries.
+	Candidates []string
+}
+
+func (e *NotGeometryColumnError) Error() string {
+	message := fmt.Sprintf("the %q column does not contain WKB or WKT geometries and the input has no %q metadata", e.Column, MetadataKey)
+	if len(e.Candidates) > 0 {
+		return fmt.Sprintf("%s (possible columns: %s)", message, strings.Join(e.Candidates, ", "))
+	}
+	return fmt.Sprintf("%s, and no other columns look like geometries (the input does not appear to be spatial)", message)
+}
+
+// CheckGeometryColumn returns an error if a top-level column in a Parquet file
+// without "geo" metadata does not look like it contains geometries.  Columns
+// that could contain geometries but have no values to sample (e.g. in a file
+// without rows) are not an error.  The returned *NotGeometryColumnError names
+// any other columns that look like geometries.
+func CheckGeometryColumn(fileReader *file.Reader, name string) error {
+	root := fileReader.MetaData().Schema.Root()
+	fieldIndex := root.FieldIndexByName(name)
+	if fieldIndex < 0 {
+		return fmt.Errorf("expected a geometry column named %q", name)
+	}
+
+	field := root.Field(fieldIndex)
+	result := detectNotGeometry
+	if detectEncoding(field) != "" {
+		result = detectNoValues
+		if rowGroup := firstRowGroup(fileReader); rowGroup != nil {
+			result, _ = detectGeometry(fileReader, rowGroup, field)
+		}
+	}
+	if result != detectNotGeometry {
+		return nil
+	}
+
+	return &NotGeometryColumnError{Column: name, Candidates: DetectGeometryColumns(fileReader)}
+}
+
+// EncodingHexWKB is returned by DetectEncoding for string columns with
+// hex-encoded WKB.  This is not a GeoParquet encoding (WKB values must be
+// binary), but the values can be read as geometries.
+const EncodingHexWKB = "hex WKB"
+
+// DetectEncoding samples the values of a top-level column and returns the
+// encoding that they look like: geo.EncodingWKB for binary values,
+// EncodingHexWKB for strings with hex-encoded WKB, and geo.EncodingWKT for
+// strings with WKT.  An empty string is returned if the column cannot hold
+// geometries, has no values to sample, or if the values cannot be decoded.
+func DetectEncoding(fileReader *file.Reader, name string) string {
+	root := fileReader.MetaData().Schema.Root()
+	fieldIndex := root.FieldIndexByName(name)
+	if fieldIndex < 0 {
+		return ""
+	}
+	rowGroup := firstRowGroup(fileReader)
+	if rowGroup == nil {
+		return ""
+	}
+	result, encoding := detectGeometry(fileReader, rowGroup, root.Field(fieldIndex))
+	if result != detectedGeometry {
+		return ""
+	}
+	return encoding
+}
+
+func allHexWKB(values [][]byte) bool {
+	for _, value := range values {
+		if !geo.IsHexWKB(string(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+type detectResult int
+
+const (
+	detectNotGeometry detectResult = iota
+	detectNoValues
+	detectedGeometry
+)
+
+// firstRowGroup returns the first row group with rows or nil if there are none.
+func firstRowGroup(fileReader *file.Reader) *file.RowGroupReader {
+	for i := 0; i < fileReader.NumRowGroups(); i += 1 {
+		if fileReader.MetaData().RowGroup(i).NumRows() > 0 {
+			return fileReader.RowGroup(i)
+		}
+	}
+	return nil
+}
+
+// detectGeometry samples the values of a top-level field to determine if it
+// contains geometries.  For detected geometries, the encoding of the sampled
+// values is also returned.
+func detectGeometry(fileReader *file.Reader, rowGroup *file.RowGroupReader, field schema.Node) (detectResult, string) {
+	encoding := detectEncoding(field)
+	if encoding == "" {
+		return detectNotGeometry, ""
+	}
+	colIndex := fileReader.MetaData().Schema.ColumnIndexByName(field.Name())
+	if colIndex < 0 {
+		return detectNotGeometry, ""
+	}
+	values := sampleValues(rowGroup, colIndex)
+	if len(values) == 0 {
+		return detectNoValues, ""
+	}
+	if !allGeometries(values, encoding) {
+		return detectNotGeometry, ""
+	}
+	if encoding == geo.EncodingWKT && allHexWKB(values) {
+		return detectedGeometry, EncodingHexWKB
+	}
+	return detectedGeometry, encoding
+}
+
+// detectEncoding returns the encoding to try for a column or an empty string if
+// the column cannot contain geometries.
+func detectEncoding(field schema.Node) string {
+	if field.RepetitionType() == parquet.Repetitions.Repeated {
+		return ""
+	}
+	primitive, ok := field.(*schema.PrimitiveNode)
+	if !ok {
+		return ""
+	}
+	switch primitive.PhysicalType() {
+	case parquet.Types.ByteArray:
+		if primitive.LogicalType() == pqutil.ParquetStringType {
+			return geo.EncodingWKT
+		}
+		return geo.EncodingWKB
+	case parquet.Types.FixedLenByteArray:
+		return geo.EncodingWKB
+	}
+	return ""
+}
+
+// sampleValues reads up to DetectSampleSize non-null values from a column,
+// skipping empty values.
+func sampleValues(rowGroup *file.RowGroupReader, colIndex int) [][]byte {
+	columnReader, err := rowGroup.Column(colIndex)
+	if err != nil {
+		return nil
+	}
+	defLevels := make([]int16, DetectSampleSize)
+
+	switch reader := columnReader.(type) {
+	case *file.ByteArrayColumnChunkReader:
+		values := make([]parquet.ByteArray, DetectSampleSize)
+		_, numValues, err := reader.ReadBatch(DetectSampleSize, values, defLevels, nil)
+		if err != nil {
+			return nil
+		}
+		sample := make([][]byte, 0, numValues)
+		for i := 0; i < numValues; i += 1 {
+			if len(values[i]) > 0 {
+				sample = append(sample, values[i])
+			}
+		}
+		return sample
+	case *file.FixedLenByteArrayColumnChunkReader:
+		values := make([]parquet.FixedLenByteArray, DetectSampleSize)
+		_, numValues, err := reader.ReadBatch(DetectSampleSize, values, defLevels, nil)
+		if err != nil {
+			return nil
+		}
+		sample := make([][]byte, 0, numValues)
+		for i := 0; i < numValues; i += 1 {
+			if len(values[i]) > 0 {
+				sample = append(sample, values[i])
+			}
+		}
+		return sample
+	}
+	return nil
+}
+
+func allGeometries(values [][]byte, encoding string) bool {
+	for _, value := range values {
+		var data any = value
+		if encoding == geo.EncodingWKT {
+			data = string(value)
+		}
+		geometry, err := geo.DecodeGeometry(data, encoding)
+		if err != nil || geometry == nil {
+			return false
+		}
+	}
+	return true
+}
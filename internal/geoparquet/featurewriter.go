@@ -1,18 +1,23 @@
 package geoparquet
 
 import (
+	"cmp"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
 
 	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
 	"github.com/apache/arrow/go/v16/parquet"
 	"github.com/apache/arrow/go/v16/parquet/pqarrow"
 	"github.com/paulmach/orb"
 	"github.com/paulmach/orb/encoding/wkb"
 	"github.com/paulmach/orb/encoding/wkt"
 	"github.com/planetlabs/gpq/internal/geo"
+	"github.com/planetlabs/gpq/internal/pqutil"
 )
 
 type FeatureWriter struct {
@@ -23,6 +28,23 @@ type FeatureWriter struct {
 	recordBuilder      *array.RecordBuilder
 	geometryTypeLookup map[string]map[string]bool
 	boundsLookup       map[string]*orb.Bound
+	geometryTypes      []string
+	partitionCellSize  float64
+	precision          *int
+	boundsPrecision    *int
+	allocator          memory.Allocator
+	partitions         map[gridCell]*array.RecordBuilder
+	maxBufferedBytes   int64
+	builderMemory      *countingAllocator
+	newRowGroup        bool
+	omitMetadata       bool
+}
+
+// gridCell identifies a cell in the grid used to partition features.
+type gridCell struct {
+	column int64
+	row    int64
+	empty  bool
 }
 
 func NewFeatureWriter(config *WriterConfig) (*FeatureWriter, error) {
@@ -49,37 +71,152 @@ func NewFeatureWriter(config *WriterConfig) (*FeatureWriter, error) {
 	if config.Writer == nil {
 		return nil, errors.New("writer is required")
 	}
+
+	if err := ValidateGeometryTypes(config.GeometryTypes); err != nil {
+		return nil, err
+	}
+
+	if err := ValidatePartitionCellSize(config.PartitionCellSize); err != nil {
+		return nil, err
+	}
+
+	if err := geo.ValidatePrecision(config.GeometryPrecision); err != nil {
+		return nil, err
+	}
+
+	if err := geo.ValidatePrecision(config.BoundsPrecision); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateMaxBufferedBytes(config.MaxBufferedBytes); err != nil {
+		return nil, err
+	}
+
 	fileWriter, fileErr := pqarrow.NewFileWriter(config.ArrowSchema, config.Writer, parquetProps, *arrowProps)
 	if fileErr != nil {
 		return nil, fileErr
 	}
 
+	builderMemory := newCountingAllocator(parquetProps.Allocator())
+
 	writer := &FeatureWriter{
 		geoMetadata:        geoMetadata,
 		fileWriter:         fileWriter,
 		maxRowGroupLength:  parquetProps.MaxRowGroupLength(),
 		bufferedLength:     0,
-		recordBuilder:      array.NewRecordBuilder(parquetProps.Allocator(), config.ArrowSchema),
+		recordBuilder:      array.NewRecordBuilder(builderMemory, config.ArrowSchema),
 		geometryTypeLookup: map[string]map[string]bool{},
 		boundsLookup:       map[string]*orb.Bound{},
+		geometryTypes:      config.GeometryTypes,
+		partitionCellSize:  config.PartitionCellSize,
+		precision:          config.GeometryPrecision,
+		boundsPrecision:    config.BoundsPrecision,
+		allocator:          parquetProps.Allocator(),
+		partitions:         map[gridCell]*array.RecordBuilder{},
+		maxBufferedBytes:   config.MaxBufferedBytes,
+		builderMemory:      builderMemory,
+		omitMetadata:       config.OmitMetadata,
 	}
 
 	return writer, nil
 }
 
+// ValidatePartitionCellSize returns an error if the grid cell size used to
+// partition features is negative.  A size of zero disables partitioning.
+func ValidatePartitionCellSize(size float64) error {
+	if size < 0 || math.IsNaN(size) || math.IsInf(size, 0) {
+		return fmt.Errorf("partition cell size must be a positive number, got %v", size)
+	}
+	return nil
+}
+
 func (w *FeatureWriter) Write(feature *geo.Feature) error {
-	arrowSchema := w.recordBuilder.Schema()
+	if w.partitionCellSize > 0 {
+		return w.writePartitioned(feature)
+	}
+
+	if err := w.appendFeature(feature, w.recordBuilder); err != nil {
+		return err
+	}
+	w.bufferedLength += 1
+	if w.bufferedLength >= w.maxRowGroupLength {
+		return w.writeBuffered()
+	}
+	if w.maxBufferedBytes > 0 && w.builderMemory.Allocated() >= w.maxBufferedBytes {
+		if err := w.writeBuffered(); err != nil {
+			return err
+		}
+		// end the row group on the next write so that an empty one is not
+		// added if this was the last feature
+		w.newRowGroup = true
+	}
+	return nil
+}
+
+func (w *FeatureWriter) appendFeature(feature *geo.Feature, recordBuilder *array.RecordBuilder) error {
+	arrowSchema := recordBuilder.Schema()
 	numFields := arrowSchema.NumFields()
 	for i := 0; i < numFields; i++ {
 		field := arrowSchema.Field(i)
-		builder := w.recordBuilder.Field(i)
+		builder := recordBuilder.Field(i)
 		if err := w.append(feature, field, builder); err != nil {
 			return err
 		}
 	}
-	w.bufferedLength += 1
-	if w.bufferedLength >= w.maxRowGroupLength {
-		return w.writeBuffered()
+	return nil
+}
+
+// writePartitioned buffers the feature with others in the same grid cell.  The
+// cell is determined by the center of the feature's bounds.  Each cell is written
+// as its own row group when the writer is closed.
+func (w *FeatureWriter) writePartitioned(feature *geo.Feature) error {
+	cell := gridCell{empty: true}
+	if feature.Geometry != nil {
+		center := feature.Geometry.Bound().Center()
+		cell = gridCell{
+			column: int64(math.Floor(center.X() / w.partitionCellSize)),
+			row:    int64(math.Floor(center.Y() / w.partitionCellSize)),
+		}
+	}
+
+	recordBuilder, ok := w.partitions[cell]
+	if !ok {
+		recordBuilder = array.NewRecordBuilder(w.allocator, w.recordBuilder.Schema())
+		w.partitions[cell] = recordBuilder
+	}
+	return w.appendFeature(feature, recordBuilder)
+}
+
+// writePartitions writes one or more row groups per grid cell.  Cells are written
+// in row-major order, with features lacking a geometry written last.
+func (w *FeatureWriter) writePartitions() error {
+	cells := make([]gridCell, 0, len(w.partitions))
+	for cell := range w.partitions {
+		cells = append(cells, cell)
+	}
+	slices.SortFunc(cells, func(a, b gridCell) int {
+		if a.empty != b.empty {
+			if a.empty {
+				return 1
+			}
+			return -1
+		}
+		if a.row != b.row {
+			return cmp.Compare(a.row, b.row)
+		}
+		return cmp.Compare(a.column, b.column)
+	})
+
+	for _, cell := range cells {
+		recordBuilder := w.partitions[cell]
+		record := recordBuilder.NewRecord()
+		recordBuilder.Release()
+		w.fileWriter.NewBufferedRowGroup()
+		err := w.fileWriter.WriteBuffered(record)
+		record.Release()
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -87,6 +224,10 @@ func (w *FeatureWriter) Write(feature *geo.Feature) error {
 func (w *FeatureWriter) writeBuffered() error {
 	record := w.recordBuilder.NewRecord()
 	defer record.Release()
+	if w.newRowGroup {
+		w.fileWriter.NewBufferedRowGroup()
+		w.newRowGroup = false
+	}
 	if err := w.fileWriter.WriteBuffered(record); err != nil {
 		return err
 	}
@@ -109,9 +250,27 @@ func (w *FeatureWriter) append(feature *geo.Feature, field arrow.Field, builder
 		return nil
 	}
 
+	if pqutil.IsJSONField(field) {
+		return appendJSON(name, value, builder)
+	}
+
 	return w.appendValue(name, value, builder)
 }
 
+// appendJSON appends the JSON encoding of any value to a JSON string field.
+func appendJSON(name string, value any, builder array.Builder) error {
+	b, ok := builder.(*array.StringBuilder)
+	if !ok {
+		return fmt.Errorf("expected JSON field %q to have a string builder, got %#v", name, builder)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("trouble encoding %q as JSON: %w", name, err)
+	}
+	b.Append(string(data))
+	return nil
+}
+
 func (w *FeatureWriter) appendValue(name string, value any, builder array.Builder) error {
 	switch b := builder.(type) {
 	case *array.BooleanBuilder:
@@ -132,6 +291,12 @@ func (w *FeatureWriter) appendValue(name string, value any, builder array.Builde
 			return fmt.Errorf("expected %q to be a float64, got %v", name, value)
 		}
 		b.Append(v)
+	case *array.Int64Builder:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("expected %q to be an int64, got %v", name, value)
+		}
+		b.Append(v)
 	case *array.ListBuilder:
 		b.Append(true)
 		valueBuilder := b.ValueBuilder()
@@ -247,16 +412,22 @@ func (w *FeatureWriter) appendGeometry(feature *geo.Feature, field arrow.Field,
 		return nil
 	}
 
+	if w.precision != nil {
+		geometry = geo.RoundGeometry(geometry, *w.precision)
+	}
+
 	if w.geometryTypeLookup[name] == nil {
 		w.geometryTypeLookup[name] = map[string]bool{}
 	}
 	w.geometryTypeLookup[name][geometry.GeoJSONType()] = true
 
-	bounds := geometry.Bound()
-	if w.boundsLookup[name] != nil {
-		bounds = bounds.Union(*w.boundsLookup[name])
+	if !geo.IsEmpty(geometry) {
+		bounds := geometry.Bound()
+		if w.boundsLookup[name] != nil {
+			bounds = bounds.Union(*w.boundsLookup[name])
+		}
+		w.boundsLookup[name] = &bounds
 	}
-	w.boundsLookup[name] = &bounds
 
 	switch geomColumn.Encoding {
 	case geo.EncodingWKB:
@@ -274,6 +445,12 @@ func (w *FeatureWriter) appendGeometry(feature *geo.Feature, field arrow.Field,
 	}
 }
 
+// AppendKeyValueMetadata adds a key-value pair to the file metadata.  It can be
+// called any time before Close.
+func (w *FeatureWriter) AppendKeyValueMetadata(key string, value string) error {
+	return w.fileWriter.AppendKeyValueMetadata(key, value)
+}
+
 func (w *FeatureWriter) Close() error {
 	defer w.recordBuilder.Release()
 	if w.bufferedLength > 0 {
@@ -281,6 +458,13 @@ func (w *FeatureWriter) Close() error {
 			return err
 		}
 	}
+	if err := w.writePartitions(); err != nil {
+		return err
+	}
+
+	if w.omitMetadata {
+		return w.fileWriter.Close()
+	}
 
 	geoMetadata := w.geoMetadata.Clone()
 	for name, bounds := range w.boundsLookup {
@@ -305,8 +489,17 @@ func (w *FeatureWriter) Close() error {
 		}
 		geoMetadata.Columns[name].GeometryTypes = geometryTypes
 	}
+	if w.geometryTypes != nil {
+		if geoMetadata.Columns[geoMetadata.PrimaryColumn] == nil {
+			geoMetadata.Columns[geoMetadata.PrimaryColumn] = getDefaultGeometryColumn()
+		}
+		geoMetadata.Columns[geoMetadata.PrimaryColumn].GeometryTypes = w.geometryTypes
+	}
 
-	data, err := json.Marshal(geoMetadata)
+	if w.boundsPrecision != nil {
+		geoMetadata.RoundBounds(*w.boundsPrecision)
+	}
+	data, err := geoMetadata.MarshalStable("")
 	if err != nil {
 		return fmt.Errorf("failed to encode %s file metadata", MetadataKey)
 	}
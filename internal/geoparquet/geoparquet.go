@@ -1,10 +1,11 @@
 package geoparquet
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 
 	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/apache/arrow/go/v16/arrow/array"
@@ -12,10 +13,10 @@ import (
 	"github.com/apache/arrow/go/v16/parquet"
 	"github.com/apache/arrow/go/v16/parquet/compress"
 	"github.com/apache/arrow/go/v16/parquet/file"
-	"github.com/apache/arrow/go/v16/parquet/pqarrow"
 	"github.com/apache/arrow/go/v16/parquet/schema"
+	"github.com/paulmach/orb"
 	"github.com/paulmach/orb/encoding/wkb"
-	"github.com/paulmach/orb/encoding/wkt"
+	"github.com/paulmach/orb/planar"
 	"github.com/planetlabs/gpq/internal/geo"
 	"github.com/planetlabs/gpq/internal/pqutil"
 )
@@ -24,6 +25,117 @@ type ConvertOptions struct {
 	InputPrimaryColumn string
 	Compression        string
 	RowGroupLength     int
+
+	// GeometryTypes, if not nil, is written as the "geometry_types" for the
+	// primary column instead of the types found in the data.
+	GeometryTypes []string
+
+	// Rename maps input column names to output column names.  Geometry column
+	// names in the metadata are updated to match.
+	Rename map[string]string
+
+	// Edges, if not empty, is written as the "edges" for all geometry columns.
+	Edges string
+
+	// DisableDictionary turns off dictionary encoding for all columns.
+	DisableDictionary bool
+
+	// DataPageSize, if positive, is the target size in bytes for data pages.
+	DataPageSize int
+
+	// Version, if not empty, is written as the metadata "version" instead of
+	// the version from the input (or the default Version).
+	Version string
+
+	// ColumnCompression maps column names to the compression codec to use for
+	// that column instead of the Compression value.
+	ColumnCompression map[string]string
+
+	// AddCentroid, if not empty, is the name of a WKB geometry column added
+	// with the centroid of the primary geometry for each row.
+	AddCentroid string
+
+	// BoundsPrecision, if not nil, is the number of decimal places that the
+	// "bbox" values in the metadata are rounded to (outward, so that they still
+	// contain all of the geometries).
+	BoundsPrecision *int
+
+	// DropGeometry removes the primary geometry column and omits the "geo"
+	// metadata, so the output is plain Parquet (not GeoParquet).  Other
+	// columns are written as they are.
+	DropGeometry bool
+
+	// RecomputeMetadata replaces the "bbox" and "geometry_types" of WKB
+	// geometry columns with values computed from the geometries.  Column values
+	// are written as they are.  A " Z" suffix on a type in the input metadata is
+	// kept if the type is still found, since decoded geometries only have X and
+	// Y.
+	RecomputeMetadata bool
+
+	// ReadAhead reads the next row group while the current one is written.  See
+	// the pqutil.TransformConfig option of the same name.
+	ReadAhead bool
+
+	// CopyColumnChunks copies the column chunks from the input without decoding
+	// them when the conversion only changes the metadata (e.g. the geometry
+	// types, edges, or version).  Conversions that change the data are written
+	// as usual.
+	CopyColumnChunks bool
+
+	// Context, if not nil, stops the conversion with the context error when it
+	// is done.
+	Context context.Context
+}
+
+// ValidateDataPageSize returns an error if the data page size is negative.
+func ValidateDataPageSize(size int) error {
+	if size < 0 {
+		return fmt.Errorf("data page size must not be negative, got %d", size)
+	}
+	return nil
+}
+
+// validateRename checks that renamed columns exist and that the output names
+// are unique.
+func validateRename(root *schema.GroupNode, rename map[string]string) error {
+	for oldName := range rename {
+		if root.FieldIndexByName(oldName) < 0 {
+			return fmt.Errorf("cannot rename %q, the input has no column with that name", oldName)
+		}
+	}
+	names := map[string]bool{}
+	for fieldNum := 0; fieldNum < root.NumFields(); fieldNum += 1 {
+		name := root.Field(fieldNum).Name()
+		if newName, ok := rename[name]; ok {
+			name = newName
+		}
+		if names[name] {
+			return fmt.Errorf("renaming would result in more than one column named %q", name)
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+// renameMetadata updates geometry column names in the metadata.
+func renameMetadata(metadata *Metadata, rename map[string]string) {
+	if newName, ok := rename[metadata.PrimaryColumn]; ok {
+		metadata.PrimaryColumn = newName
+	}
+	columns := make(map[string]*GeometryColumn, len(metadata.Columns))
+	for name, column := range metadata.Columns {
+		if newName, ok := rename[name]; ok {
+			name = newName
+		}
+		columns[name] = column
+	}
+	metadata.Columns = columns
+}
+
+// hasMetadata returns true if the file has "geo" metadata that can be parsed.
+func hasMetadata(fileReader *file.Reader) bool {
+	_, err := GetMetadata(fileReader.MetaData().KeyValueMetadata())
+	return err == nil
 }
 
 func getMetadata(fileReader *file.Reader, convertOptions *ConvertOptions) *Metadata {
@@ -51,6 +163,21 @@ func FromParquet(input parquet.ReaderAtSeeker, output io.Writer, convertOptions
 	if convertOptions == nil {
 		convertOptions = &ConvertOptions{}
 	}
+	if err := ValidateGeometryTypes(convertOptions.GeometryTypes); err != nil {
+		return err
+	}
+	if err := ValidateEdges(convertOptions.Edges); err != nil {
+		return err
+	}
+	if err := ValidateVersion(convertOptions.Version); err != nil {
+		return err
+	}
+	if err := geo.ValidatePrecision(convertOptions.BoundsPrecision); err != nil {
+		return err
+	}
+	if err := ValidateDataPageSize(convertOptions.DataPageSize); err != nil {
+		return err
+	}
 
 	var compression *compress.Compression
 	if convertOptions.Compression != "" {
@@ -61,12 +188,66 @@ func FromParquet(input parquet.ReaderAtSeeker, output io.Writer, convertOptions
 		compression = &c
 	}
 
+	columnCompression, columnCompressionErr := pqutil.GetColumnCompression(convertOptions.ColumnCompression)
+	if columnCompressionErr != nil {
+		return columnCompressionErr
+	}
+
 	datasetInfo := geo.NewDatasetStats(true)
+	centroidInfo := geo.NewGeometryStats(false)
+	centroid := &pqutil.ComputedColumn{
+		Compute: func(outputField *arrow.Field, chunked *arrow.Chunked) (*arrow.Chunked, error) {
+			return computeCentroids(chunked, centroidInfo)
+		},
+	}
+
+	var nested *nestedColumn
+	nestedInfo := geo.NewGeometryStats(false)
+	nestedComputed := &pqutil.ComputedColumn{
+		Compute: func(outputField *arrow.Field, chunked *arrow.Chunked) (*arrow.Chunked, error) {
+			extracted, err := nested.extract(chunked, nestedInfo)
+			if err != nil || !convertOptions.RecomputeMetadata || nested.Encoding != geo.EncodingWKB {
+				return extracted, err
+			}
+			if err := addGeometryStats(extracted, nestedInfo); err != nil {
+				extracted.Release()
+				return nil, fmt.Errorf("trouble recomputing metadata for %q: %w", nested.Path, err)
+			}
+			return extracted, nil
+		},
+	}
+
+	// stats for the WKB geometry columns when recomputing metadata
+	recomputeInfo := geo.NewDatasetStats(true)
+
+	// the computed columns depend on the input schema, so they are set when
+	// the schema is transformed
+	var config *pqutil.TransformConfig
+
 	transformSchema := func(fileReader *file.Reader) (*schema.Schema, error) {
 		inputSchema := fileReader.MetaData().Schema
 		inputRoot := inputSchema.Root()
 		metadata := getMetadata(fileReader, convertOptions)
+		nestedCol, nestedErr := getNestedColumn(inputSchema, metadata.PrimaryColumn)
+		if nestedErr != nil {
+			return nil, nestedErr
+		}
+		nested = nestedCol
+		if nested == nil && !hasMetadata(fileReader) && inputRoot.FieldIndexByName(metadata.PrimaryColumn) >= 0 {
+			if err := CheckGeometryColumn(fileReader, metadata.PrimaryColumn); err != nil {
+				return nil, err
+			}
+		}
+		if convertOptions.DropGeometry {
+			return dropGeometrySchema(inputSchema, metadata, nested, convertOptions, config)
+		}
+		if nested != nil && convertOptions.AddCentroid != "" {
+			return nil, fmt.Errorf("cannot add a centroid column for the nested geometry column %q", nested.Path)
+		}
 		for geomColName := range metadata.Columns {
+			if nested != nil && geomColName == nested.Path {
+				continue
+			}
 			if inputRoot.FieldIndexByName(geomColName) < 0 {
 				message := fmt.Sprintf(
 					"expected a geometry column named %q,"+
@@ -86,26 +267,94 @@ func FromParquet(input parquet.ReaderAtSeeker, output io.Writer, convertOptions
 				datasetInfo.AddCollection(name)
 			}
 		}
+		if convertOptions.RecomputeMetadata {
+			for name, geometryCol := range metadata.Columns {
+				if nested != nil && name == nested.Path {
+					continue
+				}
+				if datasetInfo.HasCollection(name) || geometryCol.Encoding != geo.EncodingWKB {
+					continue
+				}
+				recomputeInfo.AddCollection(name)
+			}
+		}
+
+		if err := validateRename(inputRoot, convertOptions.Rename); err != nil {
+			return nil, err
+		}
+
+		if convertOptions.AddCentroid != "" {
+			primaryIndex := inputRoot.FieldIndexByName(metadata.PrimaryColumn)
+			if primaryIndex < 0 {
+				return nil, fmt.Errorf("expected a primary geometry column named %q", metadata.PrimaryColumn)
+			}
+			centroid.Source = primaryIndex
+		}
 
-		if datasetInfo.NumCollections() == 0 {
+		if datasetInfo.NumCollections() == 0 && len(convertOptions.Rename) == 0 && convertOptions.AddCentroid == "" && nested == nil {
+			if recomputeInfo.NumCollections() == 0 {
+				// the columns are written as they are, so they can be copied
+				config.TransformColumn = nil
+			}
 			return inputSchema, nil
 		}
 
 		numFields := inputRoot.NumFields()
-		fields := make([]schema.Node, numFields)
+		fields := make([]schema.Node, numFields, numFields+1)
 		for fieldNum := 0; fieldNum < numFields; fieldNum += 1 {
 			inputField := inputRoot.Field(fieldNum)
+			name := inputField.Name()
+			if newName, ok := convertOptions.Rename[name]; ok {
+				name = newName
+			}
 			if !datasetInfo.HasCollection(inputField.Name()) {
-				fields[fieldNum] = inputField
+				if name == inputField.Name() {
+					fields[fieldNum] = inputField
+					continue
+				}
+				outputField, err := pqutil.RenameNode(inputField, name)
+				if err != nil {
+					return nil, err
+				}
+				fields[fieldNum] = outputField
 				continue
 			}
-			outputField, err := schema.NewPrimitiveNode(inputField.Name(), inputField.RepetitionType(), parquet.Types.ByteArray, -1, -1)
+			outputField, err := schema.NewPrimitiveNode(name, inputField.RepetitionType(), parquet.Types.ByteArray, -1, -1)
 			if err != nil {
 				return nil, err
 			}
 			fields[fieldNum] = outputField
 		}
 
+		if nested != nil {
+			for _, field := range fields {
+				if field.Name() == nested.Name {
+					return nil, fmt.Errorf("cannot write the nested geometry column %q as %q, a column with that name already exists", nested.Path, nested.Name)
+				}
+			}
+			nestedField, err := schema.NewPrimitiveNode(nested.Name, parquet.Repetitions.Optional, parquet.Types.ByteArray, -1, -1)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, nestedField)
+			nestedComputed.Source = inputRoot.FieldIndexByName(nested.source())
+			config.ComputedColumns = append(config.ComputedColumns, nestedComputed)
+		}
+
+		if convertOptions.AddCentroid != "" {
+			for _, field := range fields {
+				if field.Name() == convertOptions.AddCentroid {
+					return nil, fmt.Errorf("cannot add a centroid column named %q, a column with that name already exists", convertOptions.AddCentroid)
+				}
+			}
+			centroidField, err := schema.NewPrimitiveNode(convertOptions.AddCentroid, parquet.Repetitions.Optional, parquet.Types.ByteArray, -1, -1)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, centroidField)
+			config.ComputedColumns = append(config.ComputedColumns, centroid)
+		}
+
 		outputRoot, err := schema.NewGroupNode(inputRoot.Name(), inputRoot.RepetitionType(), fields, -1)
 		if err != nil {
 			return nil, err
@@ -114,6 +363,15 @@ func FromParquet(input parquet.ReaderAtSeeker, output io.Writer, convertOptions
 	}
 
 	transformColumn := func(inputField *arrow.Field, outputField *arrow.Field, chunked *arrow.Chunked) (*arrow.Chunked, error) {
+		if recomputeInfo.HasCollection(inputField.Name) {
+			columnInfo := geo.NewGeometryStats(false)
+			if err := addGeometryStats(chunked, columnInfo); err != nil {
+				return nil, fmt.Errorf("trouble recomputing metadata for %q: %w", inputField.Name, err)
+			}
+			recomputeInfo.AddBounds(inputField.Name, columnInfo.Bounds())
+			recomputeInfo.AddTypes(inputField.Name, columnInfo.Types())
+			return chunked, nil
+		}
 		if !datasetInfo.HasCollection(inputField.Name) {
 			return chunked, nil
 		}
@@ -134,17 +392,20 @@ func FromParquet(input parquet.ReaderAtSeeker, output io.Writer, convertOptions
 					continue
 				}
 				str := stringArray.Value(rowNum)
-				geometry, wktErr := wkt.Unmarshal(str)
-				if wktErr != nil {
-					return nil, wktErr
+				decoded, decodeErr := geo.DecodeGeometry(str, geo.EncodingWKT)
+				if decodeErr != nil {
+					return nil, decodeErr
 				}
+				geometry := decoded.Coordinates
 				value, wkbErr := wkb.Marshal(geometry)
 				if wkbErr != nil {
 					return nil, wkbErr
 				}
 				collectionInfo.AddType(geometry.GeoJSONType())
-				bounds := geometry.Bound()
-				collectionInfo.AddBounds(&bounds)
+				if !geo.IsEmpty(geometry) {
+					bounds := geometry.Bound()
+					collectionInfo.AddBounds(&bounds)
+				}
 				builder.Append(value)
 			}
 			transformed[i] = builder.NewArray()
@@ -155,19 +416,58 @@ func FromParquet(input parquet.ReaderAtSeeker, output io.Writer, convertOptions
 		return arrow.NewChunked(builder.Type(), transformed), nil
 	}
 
-	beforeClose := func(fileReader *file.Reader, fileWriter *pqarrow.FileWriter) error {
+	beforeClose := func(fileReader *file.Reader, fileWriter pqutil.MetadataWriter) error {
+		if convertOptions.DropGeometry {
+			return nil
+		}
 		metadata := getMetadata(fileReader, convertOptions)
+		if nested != nil {
+			if metadata.Columns[nested.Path] == nil {
+				metadata.Columns[nested.Path] = getDefaultGeometryColumn()
+			}
+			renameMetadata(metadata, map[string]string{nested.Path: nested.Name})
+			if nested.Encoding == geo.EncodingWKT || convertOptions.RecomputeMetadata {
+				nestedCol := metadata.Columns[nested.Name]
+				nestedCol.Bounds = metadataBounds(nestedInfo.Bounds())
+				nestedCol.GeometryTypes = recomputedTypes(nestedInfo.Types(), nestedCol.GetGeometryTypes())
+			}
+		}
 		for name, geometryCol := range metadata.Columns {
-			if !datasetInfo.HasCollection(name) {
+			if !recomputeInfo.HasCollection(name) {
 				continue
 			}
-			bounds := datasetInfo.Bounds(name)
-			geometryCol.Bounds = []float64{
-				bounds.Left(), bounds.Bottom(), bounds.Right(), bounds.Top(),
+			geometryCol.Bounds = metadataBounds(recomputeInfo.Bounds(name))
+			geometryCol.GeometryTypes = recomputedTypes(recomputeInfo.Types(name), geometryCol.GetGeometryTypes())
+		}
+		for name, geometryCol := range metadata.Columns {
+			if !datasetInfo.HasCollection(name) {
+				continue
 			}
+			geometryCol.Bounds = metadataBounds(datasetInfo.Bounds(name))
 			geometryCol.GeometryTypes = datasetInfo.Types(name)
 		}
-		encodedMetadata, jsonErr := json.Marshal(metadata)
+		if convertOptions.GeometryTypes != nil {
+			if metadata.Columns[metadata.PrimaryColumn] == nil {
+				metadata.Columns[metadata.PrimaryColumn] = getDefaultGeometryColumn()
+			}
+			metadata.Columns[metadata.PrimaryColumn].GeometryTypes = convertOptions.GeometryTypes
+		}
+		if convertOptions.AddCentroid != "" {
+			centroidCol := getDefaultGeometryColumn()
+			centroidCol.GeometryTypes = centroidInfo.Types()
+			if primaryCol, ok := metadata.Columns[metadata.PrimaryColumn]; ok {
+				centroidCol.CRS = primaryCol.CRS
+			}
+			centroidCol.Bounds = metadataBounds(centroidInfo.Bounds())
+			metadata.Columns[convertOptions.AddCentroid] = centroidCol
+		}
+		metadata.SetEdges(convertOptions.Edges)
+		metadata.SetVersion(convertOptions.Version)
+		renameMetadata(metadata, convertOptions.Rename)
+		if convertOptions.BoundsPrecision != nil {
+			metadata.RoundBounds(*convertOptions.BoundsPrecision)
+		}
+		encodedMetadata, jsonErr := metadata.MarshalStable("")
 		if jsonErr != nil {
 			return fmt.Errorf("trouble encoding %q metadata: %w", MetadataKey, jsonErr)
 		}
@@ -177,15 +477,148 @@ func FromParquet(input parquet.ReaderAtSeeker, output io.Writer, convertOptions
 		return nil
 	}
 
-	config := &pqutil.TransformConfig{
-		Reader:          input,
-		Writer:          output,
-		TransformSchema: transformSchema,
-		TransformColumn: transformColumn,
-		BeforeClose:     beforeClose,
-		Compression:     compression,
-		RowGroupLength:  convertOptions.RowGroupLength,
+	config = &pqutil.TransformConfig{
+		Reader:            input,
+		Writer:            output,
+		TransformSchema:   transformSchema,
+		TransformColumn:   transformColumn,
+		BeforeClose:       beforeClose,
+		Compression:       compression,
+		RowGroupLength:    convertOptions.RowGroupLength,
+		DisableDictionary: convertOptions.DisableDictionary,
+		DataPageSize:      int64(convertOptions.DataPageSize),
+		ColumnCompression: columnCompression,
+		Context:           convertOptions.Context,
+		ReadAhead:         convertOptions.ReadAhead,
+		CopyColumnChunks:  convertOptions.CopyColumnChunks,
 	}
 
 	return pqutil.TransformByColumn(config)
 }
+
+// dropGeometrySchema returns the output schema without the primary geometry
+// column and configures the transform to skip it.
+func dropGeometrySchema(inputSchema *schema.Schema, metadata *Metadata, nested *nestedColumn, convertOptions *ConvertOptions, config *pqutil.TransformConfig) (*schema.Schema, error) {
+	if nested != nil {
+		return nil, fmt.Errorf("cannot drop the nested geometry column %q", nested.Path)
+	}
+	if convertOptions.AddCentroid != "" {
+		return nil, errors.New("cannot add a centroid column when dropping the geometry column")
+	}
+	inputRoot := inputSchema.Root()
+	primaryIndex := inputRoot.FieldIndexByName(metadata.PrimaryColumn)
+	if primaryIndex < 0 {
+		return nil, fmt.Errorf("expected a primary geometry column named %q", metadata.PrimaryColumn)
+	}
+	if err := validateRename(inputRoot, convertOptions.Rename); err != nil {
+		return nil, err
+	}
+
+	fields := make([]schema.Node, 0, inputRoot.NumFields()-1)
+	for fieldNum := 0; fieldNum < inputRoot.NumFields(); fieldNum += 1 {
+		if fieldNum == primaryIndex {
+			continue
+		}
+		field := inputRoot.Field(fieldNum)
+		if newName, ok := convertOptions.Rename[field.Name()]; ok {
+			renamed, err := pqutil.RenameNode(field, newName)
+			if err != nil {
+				return nil, err
+			}
+			field = renamed
+		}
+		fields = append(fields, field)
+	}
+	config.DropColumns = []string{metadata.PrimaryColumn}
+
+	outputRoot, err := schema.NewGroupNode(inputRoot.Name(), inputRoot.RepetitionType(), fields, -1)
+	if err != nil {
+		return nil, err
+	}
+	return schema.NewSchema(outputRoot), nil
+}
+
+// addGeometryStats adds the bounds and types of the WKB geometries in a column
+// to the stats.
+func addGeometryStats(chunked *arrow.Chunked, stats *geo.GeometryStats) error {
+	for _, arr := range chunked.Chunks() {
+		for rowNum := 0; rowNum < arr.Len(); rowNum += 1 {
+			if arr.IsNull(rowNum) {
+				continue
+			}
+			geometry, err := geo.DecodeGeometry(arr.GetOneForMarshal(rowNum), geo.EncodingWKB)
+			if err != nil {
+				return fmt.Errorf("trouble decoding geometry for row %d: %w", rowNum, err)
+			}
+			if geometry == nil {
+				continue
+			}
+			stats.AddType(geometry.Coordinates.GeoJSONType())
+			if !geo.IsEmpty(geometry.Coordinates) {
+				bounds := geometry.Coordinates.Bound()
+				stats.AddBounds(&bounds)
+			}
+		}
+	}
+	return nil
+}
+
+// recomputedTypes returns the geometry types found in the data, keeping the
+// " Z" suffix for types that were declared that way in the input metadata.
+func recomputedTypes(found []string, previous []string) []string {
+	types := make([]string, len(found))
+	for i, geometryType := range found {
+		types[i] = geometryType
+		if slices.Contains(previous, geometryType+" Z") {
+			types[i] = geometryType + " Z"
+		}
+	}
+	return types
+}
+
+// metadataBounds returns the "bbox" value for bounds accumulated from the
+// geometries in a column.  If no bounds were added (e.g. all geometries are
+// null or empty), nil is returned.
+func metadataBounds(bounds *orb.Bound) []float64 {
+	if bounds == nil || bounds.Min[0] > bounds.Max[0] || bounds.Min[1] > bounds.Max[1] {
+		return nil
+	}
+	return []float64{bounds.Left(), bounds.Bottom(), bounds.Right(), bounds.Top()}
+}
+
+// computeCentroids returns WKB encoded centroids for the geometries in a column.
+// Null and empty geometries have a null centroid.
+func computeCentroids(chunked *arrow.Chunked, stats *geo.GeometryStats) (*arrow.Chunked, error) {
+	chunks := chunked.Chunks()
+	computed := make([]arrow.Array, len(chunks))
+	builder := array.NewBinaryBuilder(memory.DefaultAllocator, arrow.BinaryTypes.Binary)
+	defer builder.Release()
+
+	for i, arr := range chunks {
+		for rowNum := 0; rowNum < arr.Len(); rowNum += 1 {
+			if arr.IsNull(rowNum) {
+				builder.AppendNull()
+				continue
+			}
+			geometry, err := geo.DecodeGeometry(arr.GetOneForMarshal(rowNum), "")
+			if err != nil {
+				return nil, fmt.Errorf("trouble decoding geometry for row %d: %w", rowNum, err)
+			}
+			if geometry == nil || geo.IsEmpty(geometry.Coordinates) {
+				builder.AppendNull()
+				continue
+			}
+			point, _ := planar.CentroidArea(geometry.Coordinates)
+			value, err := wkb.Marshal(point)
+			if err != nil {
+				return nil, err
+			}
+			stats.AddType(point.GeoJSONType())
+			bounds := point.Bound()
+			stats.AddBounds(&bounds)
+			builder.Append(value)
+		}
+		computed[i] = builder.NewArray()
+	}
+	return arrow.NewChunked(builder.Type(), computed), nil
+}
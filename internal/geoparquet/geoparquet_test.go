@@ -17,17 +17,29 @@ package geoparquet_test
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"math"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/ipc"
 	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/apache/arrow/go/v16/parquet"
 	"github.com/apache/arrow/go/v16/parquet/file"
+	"github.com/apache/arrow/go/v16/parquet/metadata"
 	"github.com/apache/arrow/go/v16/parquet/pqarrow"
 	"github.com/paulmach/orb"
 	"github.com/paulmach/orb/encoding/wkb"
 	"github.com/planetlabs/gpq/internal/geo"
 	"github.com/planetlabs/gpq/internal/geoparquet"
+	"github.com/planetlabs/gpq/internal/pqutil"
 	"github.com/planetlabs/gpq/internal/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -57,6 +69,91 @@ func TestGetMetadataV040(t *testing.T) {
 	assert.Equal(t, "planar", col.Edges)
 	assert.Equal(t, []float64{-180, -90, 180, 83.6451}, col.Bounds)
 	assert.Equal(t, []string{"Polygon", "MultiPolygon"}, col.GetGeometryTypes())
+	assert.Nil(t, col.GeometryType)
+	assert.Equal(t, []any{"Polygon", "MultiPolygon"}, col.GeometryTypes)
+}
+
+func TestGetMetadataSingularGeometryType(t *testing.T) {
+	value := `{"version": "0.4.0", "primary_column": "geometry", "columns": {"geometry": {"encoding": "WKB", "geometry_type": "Point"}}}`
+	geoMetadata, err := geoparquet.GetMetadata(metadata.KeyValueMetadata{{Key: geoparquet.MetadataKey, Value: &value}})
+	require.NoError(t, err)
+
+	col := geoMetadata.Columns["geometry"]
+	require.NotNil(t, col)
+	assert.Nil(t, col.GeometryType)
+	assert.Equal(t, []any{"Point"}, col.GeometryTypes)
+	assert.Equal(t, []string{"Point"}, col.GetGeometryTypes())
+}
+
+func TestGetMetadataStringCRS(t *testing.T) {
+	cases := []struct {
+		crs  string
+		name string
+	}{
+		{crs: `EPSG:4326`, name: "EPSG:4326"},
+		{crs: `GEOGCRS[\"WGS 84\",DATUM[\"World Geodetic System 1984\",ELLIPSOID[\"WGS 84\",6378137,298.257223563]],CS[ellipsoidal,2],AXIS[\"latitude\",north],AXIS[\"longitude\",east],UNIT[\"degree\",0.0174532925199433],ID[\"EPSG\",4326]]`, name: "WGS 84"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			value := `{"version": "1.0.0", "primary_column": "geometry", "columns": {"geometry": {"encoding": "WKB", "geometry_types": [], "crs": "` + c.crs + `"}}}`
+			geoMetadata, err := geoparquet.GetMetadata(metadata.KeyValueMetadata{{Key: geoparquet.MetadataKey, Value: &value}})
+			require.NoError(t, err)
+
+			col := geoMetadata.Columns["geometry"]
+			require.NotNil(t, col.CRS)
+			assert.Nil(t, col.CRS.Id)
+			assert.Equal(t, c.name, col.CRS.String())
+
+			data, err := json.Marshal(col)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), `"crs":"`+c.crs+`"`)
+		})
+	}
+}
+
+func TestGeometryColumnMarshalNilTypes(t *testing.T) {
+	for _, types := range []any{nil, []string(nil), []any(nil)} {
+		data, err := json.Marshal(&geoparquet.GeometryColumn{Encoding: "WKB", GeometryTypes: types})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"encoding": "WKB", "geometry_types": []}`, string(data))
+	}
+
+	value := `{"version": "0.4.0", "primary_column": "geometry", "columns": {"geometry": {"encoding": "WKB"}}}`
+	geoMetadata, err := geoparquet.GetMetadata(metadata.KeyValueMetadata{{Key: geoparquet.MetadataKey, Value: &value}})
+	require.NoError(t, err)
+
+	data, err := geoMetadata.MarshalStable("")
+	require.NoError(t, err)
+	assert.Equal(t, `{"version":"0.4.0","primary_column":"geometry","columns":{"geometry":{"encoding":"WKB","geometry_types":[]}}}`, string(data))
+}
+
+func TestMetadataMarshalStable(t *testing.T) {
+	metadata := &geoparquet.Metadata{
+		Version:       geoparquet.Version,
+		PrimaryColumn: "geometry",
+		Columns: map[string]*geoparquet.GeometryColumn{
+			"geometry": {
+				Encoding:      geo.EncodingWKB,
+				GeometryTypes: []string{"MultiPolygon", "Point", "Polygon Z", "Polygon"},
+			},
+			"another": {
+				Encoding:      geo.EncodingWKB,
+				GeometryTypes: []string{},
+			},
+		},
+	}
+
+	data, err := metadata.MarshalStable("")
+	require.NoError(t, err)
+	assert.Equal(t, `{"version":"1.0.0","primary_column":"geometry","columns":{"another":{"encoding":"WKB","geometry_types":[]},"geometry":{"encoding":"WKB","geometry_types":["Point","Polygon","MultiPolygon","Polygon Z"]}}}`, string(data))
+
+	indented, err := metadata.MarshalStable("  ")
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(indented))
+	assert.Contains(t, string(indented), "\n  \"version\": \"1.0.0\",\n")
+
+	assert.Equal(t, []string{"MultiPolygon", "Point", "Polygon Z", "Polygon"}, metadata.Columns["geometry"].GeometryTypes)
 }
 
 func TestGetMetadataV100Beta1(t *testing.T) {
@@ -126,6 +223,360 @@ func TestRecordReaderV040(t *testing.T) {
 	assert.Equal(t, 5, numRows)
 }
 
+func TestRecordReaderCanceled(t *testing.T) {
+	fixturePath := "../testdata/cases/example-v0.4.0.parquet"
+	input, openErr := os.Open(fixturePath)
+	require.NoError(t, openErr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reader, err := geoparquet.NewRecordReader(&geoparquet.ReaderConfig{
+		Reader:    input,
+		BatchSize: 1,
+		Context:   ctx,
+	})
+	require.NoError(t, err)
+	defer reader.Close()
+
+	record, readErr := reader.Read()
+	require.NoError(t, readErr)
+	assert.Equal(t, int64(1), record.NumRows())
+
+	cancel()
+	_, readErr = reader.Read()
+	assert.ErrorIs(t, readErr, context.Canceled)
+}
+
+func TestRecordReaderMetadataOnly(t *testing.T) {
+	fixturePath := "../testdata/cases/example-v1.0.0.parquet"
+	input, openErr := os.Open(fixturePath)
+	require.NoError(t, openErr)
+
+	reader, err := geoparquet.NewRecordReader(&geoparquet.ReaderConfig{
+		Reader:       input,
+		MetadataOnly: true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "geometry", reader.Metadata().PrimaryColumn)
+	assert.Equal(t, 6, reader.Schema().NumColumns())
+
+	_, readErr := reader.Read()
+	assert.ErrorIs(t, readErr, geoparquet.ErrMetadataOnly)
+
+	assert.NoError(t, reader.Close())
+}
+
+func TestRecordReaderParsedMetadata(t *testing.T) {
+	fixturePath := "../testdata/cases/example-v1.0.0.parquet"
+	input, openErr := os.Open(fixturePath)
+	require.NoError(t, openErr)
+
+	fileReader, fileErr := file.NewParquetReader(input)
+	require.NoError(t, fileErr)
+
+	value, valueErr := geoparquet.GetMetadataValue(fileReader.MetaData().KeyValueMetadata())
+	require.NoError(t, valueErr)
+	metadata, parseErr := geoparquet.ParseMetadata(value)
+	require.NoError(t, parseErr)
+
+	reader, err := geoparquet.NewRecordReader(&geoparquet.ReaderConfig{
+		File:     fileReader,
+		Metadata: metadata,
+	})
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.Same(t, metadata, reader.Metadata())
+
+	record, readErr := reader.Read()
+	require.NoError(t, readErr)
+	assert.Equal(t, int64(5), record.NumRows())
+}
+
+func TestReadFeatureAt(t *testing.T) {
+	input, openErr := os.ReadFile("../testdata/cases/example-v1.0.0.parquet")
+	require.NoError(t, openErr)
+
+	// write two rows per row group so that later rows are in other groups
+	multiple := &bytes.Buffer{}
+	require.NoError(t, geoparquet.FromParquet(bytes.NewReader(input), multiple, &geoparquet.ConvertOptions{RowGroupLength: 2}))
+
+	multipleReader, err := file.NewParquetReader(bytes.NewReader(multiple.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, 3, multipleReader.NumRowGroups())
+
+	singleReader, err := file.NewParquetReader(bytes.NewReader(input))
+	require.NoError(t, err)
+
+	expected := []string{"Fiji", "Tanzania", "W. Sahara", "Canada", "United States of America"}
+	for _, fileReader := range []*file.Reader{singleReader, multipleReader} {
+		for index, name := range expected {
+			feature, err := geoparquet.ReadFeatureAt(fileReader, int64(index))
+			require.NoError(t, err)
+			assert.Equal(t, name, feature.Properties["name"])
+			assert.NotNil(t, feature.Geometry)
+			assert.NotContains(t, feature.Properties, "geometry")
+		}
+	}
+
+	feature, err := geoparquet.ReadFeatureAt(multipleReader, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "MultiPolygon", feature.Geometry.GeoJSONType())
+}
+
+func TestReadFeatureAtOutOfRange(t *testing.T) {
+	input, openErr := os.ReadFile("../testdata/cases/example-v1.0.0.parquet")
+	require.NoError(t, openErr)
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(input))
+	require.NoError(t, err)
+
+	_, err = geoparquet.ReadFeatureAt(fileReader, 5)
+	assert.ErrorContains(t, err, "feature index 5 is out of range, the file has 5 rows")
+
+	_, err = geoparquet.ReadFeatureAt(fileReader, -1)
+	assert.ErrorContains(t, err, "feature index -1 is out of range")
+}
+
+func TestRecordReaderBbox(t *testing.T) {
+	cases := []struct {
+		name      string
+		bbox      *orb.Bound
+		batchSize int
+		expected  []string
+	}{
+		{
+			name:      "contiguous rows in small batches",
+			bbox:      &orb.Bound{Min: orb.Point{-20, -15}, Max: orb.Point{45, 30}},
+			batchSize: 2,
+			expected:  []string{"Tanzania", "W. Sahara"},
+		},
+		{
+			name:     "separate rows in one batch",
+			bbox:     &orb.Bound{Min: orb.Point{-20, -20}, Max: orb.Point{-5, 30}},
+			expected: []string{"Fiji", "W. Sahara"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assertBboxNames(t, c.bbox, c.batchSize, c.expected)
+		})
+	}
+}
+
+func assertBboxNames(t *testing.T, bbox *orb.Bound, batchSize int, expected []string) {
+	fixturePath := "../testdata/cases/example-v1.0.0.parquet"
+	input, openErr := os.Open(fixturePath)
+	require.NoError(t, openErr)
+
+	reader, err := geoparquet.NewRecordReader(&geoparquet.ReaderConfig{
+		Reader:    input,
+		BatchSize: batchSize,
+		Bbox:      bbox,
+	})
+	require.NoError(t, err)
+	defer reader.Close()
+
+	names := []string{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.Greater(t, record.NumRows(), int64(0))
+		nameIndex := record.Schema().FieldIndices("name")[0]
+		geometryIndex := record.Schema().FieldIndices("geometry")[0]
+		for i := 0; i < int(record.NumRows()); i += 1 {
+			names = append(names, record.Column(nameIndex).ValueStr(i))
+			geometry, err := geo.DecodeGeometry(record.Column(geometryIndex).GetOneForMarshal(i), geo.EncodingWKB)
+			require.NoError(t, err)
+			assert.True(t, geometry.Geometry().Bound().Intersects(*bbox))
+		}
+	}
+
+	assert.Equal(t, expected, names)
+}
+
+func TestRecordReaderBboxOutsideBounds(t *testing.T) {
+	fixturePath := "../testdata/cases/example-v1.0.0.parquet"
+	input, openErr := os.Open(fixturePath)
+	require.NoError(t, openErr)
+
+	reader, err := geoparquet.NewRecordReader(&geoparquet.ReaderConfig{
+		Reader: input,
+		Bbox:   &orb.Bound{Min: orb.Point{0, -80}, Max: orb.Point{10, -70}},
+	})
+	require.NoError(t, err)
+	defer reader.Close()
+
+	_, readErr := reader.Read()
+	assert.Equal(t, io.EOF, readErr)
+}
+
+func TestRecordReaderBboxCovering(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String},
+		{Name: "geometry", Type: arrow.BinaryTypes.String},
+		{Name: "bbox", Type: arrow.StructOf(
+			arrow.Field{Name: "xmin", Type: arrow.PrimitiveTypes.Float64},
+			arrow.Field{Name: "ymin", Type: arrow.PrimitiveTypes.Float64},
+			arrow.Field{Name: "xmax", Type: arrow.PrimitiveTypes.Float64},
+			arrow.Field{Name: "ymax", Type: arrow.PrimitiveTypes.Float64},
+		)},
+	}, nil)
+
+	// the geometry in the second row group is invalid, so reading fails
+	// unless the row group is skipped based on the covering statistics
+	rows := `[
+		{"name": "a", "geometry": "POINT (1 1)", "bbox": {"xmin": 1, "ymin": 1, "xmax": 1, "ymax": 1}},
+		{"name": "b", "geometry": "POINT (2 2)", "bbox": {"xmin": 2, "ymin": 2, "xmax": 2, "ymax": 2}},
+		{"name": "c", "geometry": "not a geometry", "bbox": {"xmin": 50, "ymin": 50, "xmax": 50, "ymax": 50}},
+		{"name": "d", "geometry": "POINT (51 51)", "bbox": {"xmin": 51, "ymin": 51, "xmax": 51, "ymax": 51}}
+	]`
+	record, _, err := array.RecordFromJSON(memory.DefaultAllocator, schema, strings.NewReader(rows))
+	require.NoError(t, err)
+	defer record.Release()
+
+	metadata := `{
+		"version": "1.1.0",
+		"primary_column": "geometry",
+		"columns": {
+			"geometry": {
+				"encoding": "WKT",
+				"geometry_types": ["Point"],
+				"covering": {
+					"bbox": {
+						"xmin": ["bbox", "xmin"],
+						"ymin": ["bbox", "ymin"],
+						"xmax": ["bbox", "xmax"],
+						"ymax": ["bbox", "ymax"]
+					}
+				}
+			}
+		}
+	}`
+
+	output := &bytes.Buffer{}
+	writerProperties := parquet.NewWriterProperties(parquet.WithMaxRowGroupLength(2))
+	writer, err := pqarrow.NewFileWriter(schema, output, writerProperties, pqarrow.DefaultWriterProps())
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(record))
+	require.NoError(t, writer.AppendKeyValueMetadata(geoparquet.MetadataKey, metadata))
+	require.NoError(t, writer.Close())
+
+	readNames := func(bbox *orb.Bound) ([]string, error) {
+		reader, err := geoparquet.NewRecordReader(&geoparquet.ReaderConfig{
+			Reader: bytes.NewReader(output.Bytes()),
+			Bbox:   bbox,
+		})
+		require.NoError(t, err)
+		defer reader.Close()
+
+		names := []string{}
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return names, nil
+			}
+			if err != nil {
+				return names, err
+			}
+			nameIndex := record.Schema().FieldIndices("name")[0]
+			for i := 0; i < int(record.NumRows()); i += 1 {
+				names = append(names, record.Column(nameIndex).ValueStr(i))
+			}
+		}
+	}
+
+	names, err := readNames(&orb.Bound{Min: orb.Point{0, 0}, Max: orb.Point{1.5, 1.5}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, names)
+
+	names, err = readNames(&orb.Bound{Min: orb.Point{100, 100}, Max: orb.Point{101, 101}})
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	_, err = readNames(&orb.Bound{Min: orb.Point{50.5, 50.5}, Max: orb.Point{52, 52}})
+	assert.ErrorContains(t, err, "trouble decoding geometry")
+}
+
+func TestRecordReaderBboxAntimeridian(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String},
+		{Name: "geometry", Type: arrow.BinaryTypes.String},
+		{Name: "bbox", Type: arrow.StructOf(
+			arrow.Field{Name: "xmin", Type: arrow.PrimitiveTypes.Float64},
+			arrow.Field{Name: "ymin", Type: arrow.PrimitiveTypes.Float64},
+			arrow.Field{Name: "xmax", Type: arrow.PrimitiveTypes.Float64},
+			arrow.Field{Name: "ymax", Type: arrow.PrimitiveTypes.Float64},
+		)},
+	}, nil)
+
+	// the first row group has a row that crosses the antimeridian, so its
+	// xmax statistic is west of boxes near 180
+	rows := `[
+		{"name": "a", "geometry": "MULTIPOINT ((178 0), (-178 0))", "bbox": {"xmin": 178, "ymin": 0, "xmax": -178, "ymax": 0}},
+		{"name": "b", "geometry": "POINT (-179 1)", "bbox": {"xmin": -179, "ymin": 1, "xmax": -179, "ymax": 1}},
+		{"name": "c", "geometry": "POINT (0 50)", "bbox": {"xmin": 0, "ymin": 50, "xmax": 0, "ymax": 50}}
+	]`
+	record, _, err := array.RecordFromJSON(memory.DefaultAllocator, schema, strings.NewReader(rows))
+	require.NoError(t, err)
+	defer record.Release()
+
+	metadata := `{
+		"version": "1.1.0",
+		"primary_column": "geometry",
+		"columns": {
+			"geometry": {
+				"encoding": "WKT",
+				"geometry_types": ["Point", "MultiPoint"],
+				"bbox": [178, 0, 0, 50],
+				"covering": {
+					"bbox": {
+						"xmin": ["bbox", "xmin"],
+						"ymin": ["bbox", "ymin"],
+						"xmax": ["bbox", "xmax"],
+						"ymax": ["bbox", "ymax"]
+					}
+				}
+			}
+		}
+	}`
+
+	output := &bytes.Buffer{}
+	writerProperties := parquet.NewWriterProperties(parquet.WithMaxRowGroupLength(2))
+	writer, err := pqarrow.NewFileWriter(schema, output, writerProperties, pqarrow.DefaultWriterProps())
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(record))
+	require.NoError(t, writer.AppendKeyValueMetadata(geoparquet.MetadataKey, metadata))
+	require.NoError(t, writer.Close())
+
+	reader, err := geoparquet.NewRecordReader(&geoparquet.ReaderConfig{
+		Reader: bytes.NewReader(output.Bytes()),
+		Bbox:   &orb.Bound{Min: orb.Point{177, -1}, Max: orb.Point{179, 1}},
+	})
+	require.NoError(t, err)
+	defer reader.Close()
+
+	names := []string{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		nameIndex := record.Schema().FieldIndices("name")[0]
+		for i := 0; i < int(record.NumRows()); i += 1 {
+			names = append(names, record.Column(nameIndex).ValueStr(i))
+		}
+	}
+	assert.Equal(t, []string{"a"}, names)
+}
+
 func TestRowReaderV100Beta1(t *testing.T) {
 	fixturePath := "../testdata/cases/example-v1.0.0-beta.1.parquet"
 	input, openErr := os.Open(fixturePath)
@@ -212,6 +663,138 @@ func TestFromParquetWithoutDefaultGeometryColumn(t *testing.T) {
 	require.ErrorContains(t, convertErr, "expected a geometry column named \"geometry\"")
 }
 
+func TestFromParquetNonSpatial(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry []byte `parquet:"name=geometry" json:"geometry"`
+	}
+
+	rows := []*Row{
+		{
+			Name:     "not-a-geometry",
+			Geometry: []byte("not a geometry"),
+		},
+	}
+
+	err := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), &bytes.Buffer{}, nil)
+	assert.ErrorContains(t, err, `the "geometry" column does not contain WKB or WKT geometries`)
+	assert.ErrorContains(t, err, "the input does not appear to be spatial")
+}
+
+func TestFromParquetNonSpatialPrimaryColumn(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry int32  `parquet:"name=geometry" json:"geometry"`
+		Geom     []byte `parquet:"name=geom" json:"geom"`
+	}
+
+	rows := []*Row{
+		{
+			Name:     "test-point",
+			Geometry: 42,
+			Geom:     toWKB(t, orb.Point{1, 2}),
+		},
+	}
+
+	err := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), &bytes.Buffer{}, nil)
+	assert.ErrorContains(t, err, `the "geometry" column does not contain WKB or WKT geometries`)
+	assert.ErrorContains(t, err, "possible columns: geom")
+	assert.NotContains(t, err.Error(), "--input-primary-column")
+
+	notGeometry := &geoparquet.NotGeometryColumnError{}
+	require.ErrorAs(t, err, &notGeometry)
+	assert.Equal(t, "geometry", notGeometry.Column)
+	assert.Equal(t, []string{"geom"}, notGeometry.Candidates)
+}
+
+func TestFromParquetNoPrimaryColumnSkipsCheck(t *testing.T) {
+	type Row struct {
+		Name  string `parquet:"name=name, logical=String" json:"name"`
+		Other []byte `parquet:"name=other" json:"other"`
+	}
+
+	rows := []*Row{
+		{
+			Name:  "not-a-geometry",
+			Other: []byte("not a geometry"),
+		},
+	}
+
+	err := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), &bytes.Buffer{}, nil)
+	assert.ErrorContains(t, err, `expected a geometry column named "geometry"`)
+
+	notGeometry := &geoparquet.NotGeometryColumnError{}
+	assert.False(t, errors.As(err, &notGeometry))
+}
+
+func TestMetadataRoundBounds(t *testing.T) {
+	metadata := &geoparquet.Metadata{
+		Version:       geoparquet.Version,
+		PrimaryColumn: "geometry",
+		Columns: map[string]*geoparquet.GeometryColumn{
+			"geometry": {Encoding: "WKB", Bounds: []float64{-180, -84.71338, 180, 83.23324000000001}},
+			"other":    {Encoding: "WKB", Bounds: []float64{1.001, 2.009, 3.5, 4.001, 5.009, 6.5}},
+			"none":     {Encoding: "WKB"},
+		},
+	}
+
+	metadata.RoundBounds(2)
+	assert.Equal(t, []float64{-180, -84.72, 180, 83.24}, metadata.Columns["geometry"].Bounds)
+	assert.Equal(t, []float64{1, 2, 3.5, 4.01, 5.01, 6.5}, metadata.Columns["other"].Bounds)
+	assert.Empty(t, metadata.Columns["none"].Bounds)
+
+	metadata.RoundBounds(0)
+	assert.Equal(t, []float64{-180, -85, 180, 84}, metadata.Columns["geometry"].Bounds)
+	assert.Equal(t, []float64{1, 2, 3, 5, 6, 7}, metadata.Columns["other"].Bounds)
+}
+
+func TestMetadataFromMap(t *testing.T) {
+	cases := []string{
+		`{"version": "1.0.0", "primary_column": "geometry", "columns": {"geometry": {"encoding": "WKB", "geometry_types": ["Point"]}}}`,
+		`{"version": "1.0.0", "primary_column": "geom", "columns": {"geom": {"encoding": "WKB", "geometry_type": "Polygon", "bbox": [1, 2, 3, 4], "epoch": 2020.5}}}`,
+		`{"version": "1.1.0", "primary_column": "geometry", "columns": {"geometry": {"encoding": "WKT", "geometry_types": [], "edges": "spherical", "orientation": "counterclockwise", "crs": null}}}`,
+		`{"version": "1.1.0", "primary_column": "geometry", "columns": {"geometry": {"encoding": "WKB", "geometry_types": [], "crs": "EPSG:4326"}}}`,
+		`{"version": "1.1.0", "primary_column": "geometry", "columns": {"geometry": {"encoding": "WKB", "geometry_types": [], "crs": {"name": "NAD83", "id": {"authority": "EPSG", "code": 4269}}}}}`,
+	}
+
+	for _, value := range cases {
+		t.Run(value, func(t *testing.T) {
+			expected, err := geoparquet.ParseMetadata(value)
+			require.NoError(t, err)
+
+			decoded := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(value), &decoded))
+			actual, err := geoparquet.MetadataFromMap(decoded)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestMetadataFromMapInvalid(t *testing.T) {
+	cases := map[string]string{
+		`{"version": 1}`:                                     `"version" must be a string`,
+		`{"columns": []}`:                                    `"columns" must be an object`,
+		`{"columns": {"geometry": "WKB"}}`:                   `column "geometry" must be an object`,
+		`{"columns": {"geometry": {"encoding": 1}}}`:         `"encoding" must be a string`,
+		`{"columns": {"geometry": {"bbox": [1, "2"]}}}`:      `"bbox" must be an array of numbers`,
+		`{"columns": {"geometry": {"crs": 4326}}}`:           `"crs" must be an object or a string`,
+		`{"columns": {"geometry": {"crs": {"name": true}}}}`: `crs: "name" must be a string`,
+	}
+
+	for value, message := range cases {
+		t.Run(value, func(t *testing.T) {
+			decoded := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(value), &decoded))
+			_, err := geoparquet.MetadataFromMap(decoded)
+			assert.ErrorContains(t, err, message)
+
+			_, err = geoparquet.ParseMetadata(value)
+			assert.Error(t, err)
+		})
+	}
+}
+
 func TestMetadataClone(t *testing.T) {
 	metadata := geoparquet.DefaultMetadata()
 	clone := metadata.Clone()
@@ -280,51 +863,808 @@ func TestFromParquetWithWKT(t *testing.T) {
 	assert.Equal(t, int64(2), reader.NumRows())
 }
 
-func TestFromParquetWithAltPrimaryColumn(t *testing.T) {
+func TestFromParquetCopyColumnChunks(t *testing.T) {
+	input, openErr := os.ReadFile("../testdata/cases/example-v1.0.0.parquet")
+	require.NoError(t, openErr)
+
+	output := &bytes.Buffer{}
+	require.NoError(t, geoparquet.FromParquet(bytes.NewReader(input), output, &geoparquet.ConvertOptions{
+		Edges:            geoparquet.EdgesSpherical,
+		CopyColumnChunks: true,
+	}))
+
+	inputReader, err := file.NewParquetReader(bytes.NewReader(input))
+	require.NoError(t, err)
+	defer inputReader.Close()
+
+	outputReader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer outputReader.Close()
+
+	// the column chunks are copied byte for byte
+	footerOffset := len(input) - inputReader.MetaData().Size() - 8
+	assert.Equal(t, input[:footerOffset], output.Bytes()[:footerOffset])
+	assert.Equal(t, inputReader.NumRows(), outputReader.NumRows())
+
+	metadata, err := geoparquet.GetMetadata(outputReader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, geoparquet.EdgesSpherical, metadata.Columns[metadata.PrimaryColumn].Edges)
+}
+
+func TestFromParquetCopyColumnChunksWithWKT(t *testing.T) {
 	type Row struct {
-		Name string `parquet:"name=name, logical=String" json:"name"`
-		Geo  []byte `parquet:"name=geo" json:"geo"`
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry string `parquet:"name=geometry, logical=String" json:"geometry"`
 	}
 
 	rows := []*Row{
-		{
-			Name: "test-point-1",
-			Geo:  toWKB(t, orb.Point{1, 2}),
-		},
-		{
-			Name: "test-point-2",
-			Geo:  toWKB(t, orb.Point{3, 4}),
-		},
+		{Name: "test-point-1", Geometry: "POINT (1 2)"},
+		{Name: "test-point-2", Geometry: "POINT (3 4)"},
 	}
 
-	input := test.ParquetFromStructs(t, rows)
-
-	primaryColumn := "geo"
-
 	output := &bytes.Buffer{}
-	convertErr := geoparquet.FromParquet(input, output, &geoparquet.ConvertOptions{InputPrimaryColumn: primaryColumn})
-	require.NoError(t, convertErr)
+	require.NoError(t, geoparquet.FromParquet(test.ParquetFromStructs(t, rows), output, &geoparquet.ConvertOptions{
+		CopyColumnChunks: true,
+	}))
 
-	geoparquetInput := bytes.NewReader(output.Bytes())
-	reader, err := file.NewParquetReader(geoparquetInput)
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
 	require.NoError(t, err)
 	defer reader.Close()
 
+	// the WKT column is converted, so the data is not copied
+	geometryIndex := reader.MetaData().Schema.ColumnIndexByName("geometry")
+	assert.NotEqual(t, pqutil.ParquetStringType, reader.MetaData().Schema.Column(geometryIndex).LogicalType())
+
 	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
 	require.NoError(t, err)
-
-	assert.Equal(t, primaryColumn, metadata.PrimaryColumn)
-	assert.Len(t, metadata.Columns, 1)
-	primaryColumnMetadata := metadata.Columns[metadata.PrimaryColumn]
-	assert.Equal(t, geo.EncodingWKB, primaryColumnMetadata.Encoding)
-
-	assert.Equal(t, int64(2), reader.NumRows())
+	assert.Equal(t, []float64{1, 2, 3, 4}, metadata.Columns[metadata.PrimaryColumn].Bounds)
 }
 
-func TestFromParquetWithAltPrimaryColumnWKT(t *testing.T) {
+func TestFromParquetWithHexWKB(t *testing.T) {
 	type Row struct {
-		Name string `parquet:"name=name, logical=String" json:"name"`
-		Geo  string `parquet:"name=geo, logical=String" json:"geo"`
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry string `parquet:"name=geometry, logical=String" json:"geometry"`
+	}
+
+	rows := []*Row{
+		{
+			Name:     "test-point-1",
+			Geometry: hex.EncodeToString(toWKB(t, orb.Point{1, 2})),
+		},
+		{
+			Name:     "test-point-2",
+			Geometry: "POINT (3 4)",
+		},
+	}
+
+	output := &bytes.Buffer{}
+	require.NoError(t, geoparquet.FromParquet(test.ParquetFromStructs(t, rows), output, nil))
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	primaryColumnMetadata := metadata.Columns[metadata.PrimaryColumn]
+	assert.Equal(t, geo.EncodingWKB, primaryColumnMetadata.Encoding)
+	assert.Equal(t, []string{"Point"}, primaryColumnMetadata.GetGeometryTypes())
+	assert.Equal(t, []float64{1, 2, 3, 4}, primaryColumnMetadata.Bounds)
+}
+
+func TestFromParquetWithGeometryTypes(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry string `parquet:"name=geometry, logical=String" json:"geometry"`
+	}
+
+	rows := []*Row{
+		{
+			Name:     "test-polygon",
+			Geometry: "POLYGON ((0 0, 1 0, 1 1, 0 1, 0 0))",
+		},
+	}
+
+	input := test.ParquetFromStructs(t, rows)
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(input, output, &geoparquet.ConvertOptions{
+		GeometryTypes: []string{"Polygon", "MultiPolygon"},
+	})
+	require.NoError(t, convertErr)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+
+	primaryColumnMetadata := metadata.Columns[metadata.PrimaryColumn]
+	assert.Equal(t, []string{"Polygon", "MultiPolygon"}, primaryColumnMetadata.GetGeometryTypes())
+	assert.Equal(t, []float64{0, 0, 1, 1}, primaryColumnMetadata.Bounds)
+}
+
+func TestFromParquetWithInvalidGeometryTypes(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry string `parquet:"name=geometry, logical=String" json:"geometry"`
+	}
+
+	rows := []*Row{{Name: "test-point", Geometry: "POINT (1 2)"}}
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), output, &geoparquet.ConvertOptions{
+		GeometryTypes: []string{"Polygon", "Triangle"},
+	})
+	assert.EqualError(t, convertErr, `unsupported geometry type "Triangle"`)
+}
+
+func TestFromParquetWithEdges(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry string `parquet:"name=geometry, logical=String" json:"geometry"`
+	}
+
+	rows := []*Row{{Name: "test-point", Geometry: "POINT (1 2)"}}
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), output, &geoparquet.ConvertOptions{
+		Edges: geoparquet.EdgesSpherical,
+	})
+	require.NoError(t, convertErr)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, geoparquet.EdgesSpherical, metadata.Columns[metadata.PrimaryColumn].Edges)
+}
+
+func TestFromParquetWithInvalidEdges(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry string `parquet:"name=geometry, logical=String" json:"geometry"`
+	}
+
+	rows := []*Row{{Name: "test-point", Geometry: "POINT (1 2)"}}
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), output, &geoparquet.ConvertOptions{
+		Edges: "curved",
+	})
+	assert.EqualError(t, convertErr, `unsupported edges "curved", expected "planar" or "spherical"`)
+}
+
+func TestFromParquetWithVersion(t *testing.T) {
+	output := &bytes.Buffer{}
+	input, openErr := os.Open("../testdata/cases/example-v1.0.0.parquet")
+	require.NoError(t, openErr)
+	defer input.Close()
+
+	convertErr := geoparquet.FromParquet(input, output, &geoparquet.ConvertOptions{
+		Version: "1.1.0",
+	})
+	require.NoError(t, convertErr)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", metadata.Version)
+}
+
+func TestFromParquetWithInvalidVersion(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry string `parquet:"name=geometry, logical=String" json:"geometry"`
+	}
+
+	rows := []*Row{{Name: "test-point", Geometry: "POINT (1 2)"}}
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), output, &geoparquet.ConvertOptions{
+		Version: "2.0.0",
+	})
+	assert.EqualError(t, convertErr, `unsupported version "2.0.0", expected one of 1.0.0-beta.1, 1.0.0, 1.1.0`)
+}
+
+func TestFromParquetWithAltPrimaryColumn(t *testing.T) {
+	type Row struct {
+		Name string `parquet:"name=name, logical=String" json:"name"`
+		Geo  []byte `parquet:"name=geo" json:"geo"`
+	}
+
+	rows := []*Row{
+		{
+			Name: "test-point-1",
+			Geo:  toWKB(t, orb.Point{1, 2}),
+		},
+		{
+			Name: "test-point-2",
+			Geo:  toWKB(t, orb.Point{3, 4}),
+		},
+	}
+
+	input := test.ParquetFromStructs(t, rows)
+
+	primaryColumn := "geo"
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(input, output, &geoparquet.ConvertOptions{InputPrimaryColumn: primaryColumn})
+	require.NoError(t, convertErr)
+
+	geoparquetInput := bytes.NewReader(output.Bytes())
+	reader, err := file.NewParquetReader(geoparquetInput)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+
+	assert.Equal(t, primaryColumn, metadata.PrimaryColumn)
+	assert.Len(t, metadata.Columns, 1)
+	primaryColumnMetadata := metadata.Columns[metadata.PrimaryColumn]
+	assert.Equal(t, geo.EncodingWKB, primaryColumnMetadata.Encoding)
+
+	assert.Equal(t, int64(2), reader.NumRows())
+}
+
+func TestFromParquetWithRename(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry []byte `parquet:"name=geometry" json:"geometry"`
+	}
+
+	rows := []*Row{
+		{
+			Name:     "test-point",
+			Geometry: toWKB(t, orb.Point{1, 2}),
+		},
+	}
+
+	input := test.ParquetFromStructs(t, rows)
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(input, output, &geoparquet.ConvertOptions{
+		Rename: map[string]string{"geometry": "geom", "name": "label"},
+	})
+	require.NoError(t, convertErr)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	root := reader.MetaData().Schema.Root()
+	assert.Equal(t, 0, root.FieldIndexByName("label"))
+	assert.Equal(t, 1, root.FieldIndexByName("geom"))
+	assert.Equal(t, -1, root.FieldIndexByName("geometry"))
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, "geom", metadata.PrimaryColumn)
+	assert.Len(t, metadata.Columns, 1)
+	assert.Contains(t, metadata.Columns, "geom")
+
+	recordReader, rrErr := geoparquet.NewRecordReader(&geoparquet.ReaderConfig{
+		Reader: bytes.NewReader(output.Bytes()),
+	})
+	require.NoError(t, rrErr)
+	defer recordReader.Close()
+
+	record, readErr := recordReader.Read()
+	require.NoError(t, readErr)
+	assert.Equal(t, "label", record.Schema().Field(0).Name)
+	assert.Equal(t, "test-point", record.Column(0).GetOneForMarshal(0))
+}
+
+func TestFromParquetWithWKTRename(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry string `parquet:"name=geometry, logical=String" json:"geometry"`
+	}
+
+	rows := []*Row{
+		{
+			Name:     "test-point",
+			Geometry: "POINT (1 2)",
+		},
+	}
+
+	input := test.ParquetFromStructs(t, rows)
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(input, output, &geoparquet.ConvertOptions{
+		Rename: map[string]string{"geometry": "geom"},
+	})
+	require.NoError(t, convertErr)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, "geom", metadata.PrimaryColumn)
+	require.Contains(t, metadata.Columns, "geom")
+	assert.Equal(t, []float64{1, 2, 1, 2}, metadata.Columns["geom"].Bounds)
+	assert.Equal(t, []string{"Point"}, metadata.Columns["geom"].GetGeometryTypes())
+}
+
+func TestFromParquetWithInvalidRename(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry []byte `parquet:"name=geometry" json:"geometry"`
+	}
+
+	rows := []*Row{
+		{
+			Name:     "test-point",
+			Geometry: toWKB(t, orb.Point{1, 2}),
+		},
+	}
+
+	missing := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), &bytes.Buffer{}, &geoparquet.ConvertOptions{
+		Rename: map[string]string{"nope": "yes"},
+	})
+	assert.ErrorContains(t, missing, `cannot rename "nope"`)
+
+	duplicate := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), &bytes.Buffer{}, &geoparquet.ConvertOptions{
+		Rename: map[string]string{"name": "geometry"},
+	})
+	assert.ErrorContains(t, duplicate, `more than one column named "geometry"`)
+}
+
+func TestDetectGeometryColumns(t *testing.T) {
+	type Row struct {
+		Name    string `parquet:"name=name, logical=String" json:"name"`
+		Shape   []byte `parquet:"name=shape" json:"shape"`
+		Label   string `parquet:"name=label, logical=String" json:"label"`
+		Outline string `parquet:"name=outline, logical=String" json:"outline"`
+		Data    []byte `parquet:"name=data" json:"data"`
+	}
+
+	rows := []*Row{
+		{
+			Name:    "first",
+			Shape:   toWKB(t, orb.Point{1, 2}),
+			Label:   "POINT (1 2)",
+			Outline: "LINESTRING (0 0, 1 1)",
+			Data:    []byte("not a geometry"),
+		},
+		{
+			Name:    "second",
+			Shape:   toWKB(t, orb.LineString{{0, 0}, {1, 1}}),
+			Label:   "not a geometry",
+			Outline: "POLYGON ((0 0, 1 0, 1 1, 0 0))",
+			Data:    toWKB(t, orb.Point{3, 4}),
+		},
+	}
+
+	fileReader, err := file.NewParquetReader(test.ParquetFromStructs(t, rows))
+	require.NoError(t, err)
+	defer fileReader.Close()
+
+	assert.Equal(t, []string{"shape", "outline"}, geoparquet.DetectGeometryColumns(fileReader))
+}
+
+func TestFromParquetWithCentroid(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry []byte `parquet:"name=geometry" json:"geometry"`
+	}
+
+	rows := []*Row{
+		{
+			Name:     "square",
+			Geometry: toWKB(t, orb.Polygon{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}}),
+		},
+		{
+			Name:     "line",
+			Geometry: toWKB(t, orb.LineString{{10, 10}, {20, 10}}),
+		},
+	}
+
+	input := test.ParquetFromStructs(t, rows)
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(input, output, &geoparquet.ConvertOptions{
+		AddCentroid: "centroid",
+	})
+	require.NoError(t, convertErr)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.Equal(t, 2, reader.MetaData().Schema.Root().FieldIndexByName("centroid"))
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, "geometry", metadata.PrimaryColumn)
+	require.Contains(t, metadata.Columns, "centroid")
+	centroid := metadata.Columns["centroid"]
+	assert.Equal(t, geoparquet.DefaultGeometryEncoding, centroid.Encoding)
+	assert.Equal(t, []string{"Point"}, centroid.GetGeometryTypes())
+	assert.Equal(t, []float64{1, 1, 15, 10}, centroid.Bounds)
+
+	recordReader, rrErr := geoparquet.NewRecordReader(&geoparquet.ReaderConfig{
+		Reader: bytes.NewReader(output.Bytes()),
+	})
+	require.NoError(t, rrErr)
+	defer recordReader.Close()
+
+	record, readErr := recordReader.Read()
+	require.NoError(t, readErr)
+	require.Equal(t, int64(3), record.NumCols())
+
+	expected := []orb.Point{{1, 1}, {15, 10}}
+	for i, point := range expected {
+		geometry, err := geo.DecodeGeometry(record.Column(2).GetOneForMarshal(i), geo.EncodingWKB)
+		require.NoError(t, err)
+		assert.Equal(t, point, geometry.Coordinates)
+	}
+}
+
+func TestFromParquetWithCentroidAllEmpty(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry string `parquet:"name=geometry, logical=String" json:"geometry"`
+	}
+
+	rows := []*Row{
+		{
+			Name:     "empty",
+			Geometry: "MULTIPOINT EMPTY",
+		},
+	}
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), output, &geoparquet.ConvertOptions{
+		AddCentroid: "centroid",
+	})
+	require.NoError(t, convertErr)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	require.Contains(t, metadata.Columns, "centroid")
+	assert.Nil(t, metadata.Columns["centroid"].Bounds)
+	assert.Nil(t, metadata.Columns["geometry"].Bounds)
+}
+
+func TestFromParquetWithCentroidWKT(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry string `parquet:"name=geometry, logical=String" json:"geometry"`
+	}
+
+	rows := []*Row{
+		{
+			Name:     "test-point",
+			Geometry: "POINT (1 2)",
+		},
+	}
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), output, &geoparquet.ConvertOptions{
+		AddCentroid: "centroid",
+	})
+	require.NoError(t, convertErr)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	require.Contains(t, metadata.Columns, "centroid")
+	assert.Equal(t, []float64{1, 2, 1, 2}, metadata.Columns["centroid"].Bounds)
+}
+
+func TestFromParquetDropGeometry(t *testing.T) {
+	input, openErr := os.Open("../testdata/cases/example-v1.0.0.parquet")
+	require.NoError(t, openErr)
+	defer input.Close()
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(input, output, &geoparquet.ConvertOptions{
+		DropGeometry: true,
+		Rename:       map[string]string{"pop_est": "population"},
+	})
+	require.NoError(t, convertErr)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.Equal(t, int64(5), reader.NumRows())
+	assert.Nil(t, reader.MetaData().KeyValueMetadata().FindValue(geoparquet.MetadataKey))
+
+	root := reader.MetaData().Schema.Root()
+	require.Equal(t, 5, root.NumFields())
+	assert.Equal(t, -1, root.FieldIndexByName("geometry"))
+	assert.Equal(t, 0, root.FieldIndexByName("population"))
+	assert.Equal(t, 4, root.FieldIndexByName("name"))
+
+	_, metadataErr := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	assert.ErrorIs(t, metadataErr, geoparquet.ErrNoMetadata)
+}
+
+func TestFromParquetRecomputeMetadata(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry []byte `parquet:"name=geometry" json:"geometry"`
+	}
+
+	rows := []*Row{
+		{
+			Name:     "test-point",
+			Geometry: toWKB(t, orb.Point{1, 2}),
+		},
+		{
+			Name:     "test-line",
+			Geometry: toWKB(t, orb.LineString{{3, 4}, {5, -6}}),
+		},
+	}
+
+	// write the rows with deliberately wrong "bbox" and "geometry_types"
+	stale := `{
+		"version": "1.0.0",
+		"primary_column": "geometry",
+		"columns": {
+			"geometry": {
+				"encoding": "WKB",
+				"geometry_types": ["Polygon", "Point Z"],
+				"bbox": [100, 100, 101, 101]
+			}
+		}
+	}`
+	input := &bytes.Buffer{}
+	require.NoError(t, pqutil.TransformByColumn(&pqutil.TransformConfig{
+		Reader: test.ParquetFromStructs(t, rows),
+		Writer: input,
+		BeforeClose: func(fileReader *file.Reader, fileWriter pqutil.MetadataWriter) error {
+			return fileWriter.AppendKeyValueMetadata(geoparquet.MetadataKey, stale)
+		},
+	}))
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(bytes.NewReader(input.Bytes()), output, &geoparquet.ConvertOptions{
+		RecomputeMetadata: true,
+	})
+	require.NoError(t, convertErr)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+
+	primary := metadata.Columns[metadata.PrimaryColumn]
+	assert.Equal(t, []float64{1, -6, 5, 4}, primary.Bounds)
+	assert.ElementsMatch(t, []string{"Point Z", "LineString"}, primary.GetGeometryTypes())
+	assert.Equal(t, int64(2), reader.NumRows())
+
+	// without the option, the stale metadata is kept
+	unchanged := &bytes.Buffer{}
+	require.NoError(t, geoparquet.FromParquet(bytes.NewReader(input.Bytes()), unchanged, nil))
+
+	unchangedReader, err := file.NewParquetReader(bytes.NewReader(unchanged.Bytes()))
+	require.NoError(t, err)
+	defer unchangedReader.Close()
+
+	unchangedMetadata, err := geoparquet.GetMetadata(unchangedReader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, []float64{100, 100, 101, 101}, unchangedMetadata.Columns["geometry"].Bounds)
+}
+
+func TestFromParquetDropGeometryErrors(t *testing.T) {
+	type Row struct {
+		Name string `parquet:"name=name, logical=String" json:"name"`
+		Geom []byte `parquet:"name=geom" json:"geom"`
+	}
+
+	rows := []*Row{
+		{
+			Name: "test-point",
+			Geom: toWKB(t, orb.Point{1, 2}),
+		},
+	}
+
+	err := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), &bytes.Buffer{}, &geoparquet.ConvertOptions{
+		DropGeometry: true,
+	})
+	assert.ErrorContains(t, err, `expected a primary geometry column named "geometry"`)
+
+	err = geoparquet.FromParquet(test.ParquetFromStructs(t, rows), &bytes.Buffer{}, &geoparquet.ConvertOptions{
+		InputPrimaryColumn: "geom",
+		DropGeometry:       true,
+		AddCentroid:        "centroid",
+	})
+	assert.ErrorContains(t, err, "cannot add a centroid column when dropping the geometry column")
+}
+
+func TestFromParquetWithCentroidNameConflict(t *testing.T) {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry []byte `parquet:"name=geometry" json:"geometry"`
+	}
+
+	rows := []*Row{
+		{
+			Name:     "test-point",
+			Geometry: toWKB(t, orb.Point{1, 2}),
+		},
+	}
+
+	err := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), &bytes.Buffer{}, &geoparquet.ConvertOptions{
+		AddCentroid: "name",
+	})
+	assert.ErrorContains(t, err, `cannot add a centroid column named "name"`)
+}
+
+func TestFromParquetWithNestedPrimaryColumn(t *testing.T) {
+	type Feature struct {
+		Kind string `parquet:"name=kind, logical=String" json:"kind"`
+		Geom []byte `parquet:"name=geom" json:"geom"`
+	}
+
+	type Row struct {
+		Name    string   `parquet:"name=name, logical=String" json:"name"`
+		Feature *Feature `parquet:"name=feature" json:"feature"`
+	}
+
+	rows := []*Row{
+		{
+			Name:    "test-point",
+			Feature: &Feature{Kind: "point", Geom: toWKB(t, orb.Point{1, 2})},
+		},
+		{
+			Name:    "no-feature",
+			Feature: nil,
+		},
+		{
+			Name:    "test-line",
+			Feature: &Feature{Kind: "line", Geom: toWKB(t, orb.LineString{{3, 4}, {5, 6}})},
+		},
+	}
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), output, &geoparquet.ConvertOptions{
+		InputPrimaryColumn: "feature.geom",
+	})
+	require.NoError(t, convertErr)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	root := reader.MetaData().Schema.Root()
+	assert.Equal(t, 1, root.FieldIndexByName("feature"))
+	assert.Equal(t, 2, root.FieldIndexByName("geom"))
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, "geom", metadata.PrimaryColumn)
+	assert.Len(t, metadata.Columns, 1)
+	assert.Equal(t, geo.EncodingWKB, metadata.Columns["geom"].Encoding)
+
+	recordReader, rrErr := geoparquet.NewRecordReader(&geoparquet.ReaderConfig{
+		Reader: bytes.NewReader(output.Bytes()),
+	})
+	require.NoError(t, rrErr)
+	defer recordReader.Close()
+
+	record, readErr := recordReader.Read()
+	require.NoError(t, readErr)
+	require.Equal(t, int64(3), record.NumRows())
+
+	expected := []orb.Geometry{orb.Point{1, 2}, nil, orb.LineString{{3, 4}, {5, 6}}}
+	for i, expectedGeometry := range expected {
+		column := record.Column(2)
+		if expectedGeometry == nil {
+			assert.True(t, column.IsNull(i))
+			continue
+		}
+		geometry, err := geo.DecodeGeometry(column.GetOneForMarshal(i), geo.EncodingWKB)
+		require.NoError(t, err)
+		assert.Equal(t, expectedGeometry, geometry.Coordinates)
+	}
+}
+
+func TestFromParquetWithNestedPrimaryColumnWKT(t *testing.T) {
+	type Feature struct {
+		Geom string `parquet:"name=geom, logical=String" json:"geom"`
+	}
+
+	type Row struct {
+		Name    string  `parquet:"name=name, logical=String" json:"name"`
+		Feature Feature `parquet:"name=feature" json:"feature"`
+	}
+
+	rows := []*Row{
+		{Name: "test-point-1", Feature: Feature{Geom: "POINT (1 2)"}},
+		{Name: "test-point-2", Feature: Feature{Geom: "POINT (3 4)"}},
+	}
+
+	output := &bytes.Buffer{}
+	convertErr := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), output, &geoparquet.ConvertOptions{
+		InputPrimaryColumn: "feature.geom",
+	})
+	require.NoError(t, convertErr)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, "geom", metadata.PrimaryColumn)
+	geomColumn := metadata.Columns["geom"]
+	require.NotNil(t, geomColumn)
+	assert.Equal(t, geo.EncodingWKB, geomColumn.Encoding)
+	assert.Equal(t, []string{"Point"}, geomColumn.GetGeometryTypes())
+	assert.Equal(t, []float64{1, 2, 3, 4}, geomColumn.Bounds)
+}
+
+func TestFromParquetWithNestedPrimaryColumnErrors(t *testing.T) {
+	type Feature struct {
+		Name string `parquet:"name=name, logical=String" json:"name"`
+		Geom []byte `parquet:"name=geom" json:"geom"`
+	}
+
+	type Row struct {
+		Name    string  `parquet:"name=name, logical=String" json:"name"`
+		Feature Feature `parquet:"name=feature" json:"feature"`
+	}
+
+	rows := []*Row{
+		{Name: "test-point", Feature: Feature{Name: "point", Geom: toWKB(t, orb.Point{1, 2})}},
+	}
+
+	cases := []struct {
+		name    string
+		options *geoparquet.ConvertOptions
+		err     string
+	}{
+		{
+			name:    "missing path",
+			options: &geoparquet.ConvertOptions{InputPrimaryColumn: "feature.missing"},
+			err:     `expected a geometry column at the path "feature.missing"`,
+		},
+		{
+			name:    "name conflict",
+			options: &geoparquet.ConvertOptions{InputPrimaryColumn: "feature.name"},
+			err:     `cannot write the nested geometry column "feature.name" as "name"`,
+		},
+		{
+			name:    "centroid",
+			options: &geoparquet.ConvertOptions{InputPrimaryColumn: "feature.geom", AddCentroid: "centroid"},
+			err:     `cannot add a centroid column for the nested geometry column "feature.geom"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := geoparquet.FromParquet(test.ParquetFromStructs(t, rows), &bytes.Buffer{}, c.options)
+			assert.ErrorContains(t, err, c.err)
+		})
+	}
+}
+
+func TestFromParquetWithAltPrimaryColumnWKT(t *testing.T) {
+	type Row struct {
+		Name string `parquet:"name=name, logical=String" json:"name"`
+		Geo  string `parquet:"name=geo, logical=String" json:"geo"`
 	}
 
 	rows := []*Row{
@@ -394,3 +1734,295 @@ func TestRecordReading(t *testing.T) {
 
 	assert.Equal(t, reader.NumRows(), int64(numRows))
 }
+
+func newArrowRecord(t *testing.T, schemaMetadata *arrow.Metadata, geometries ...orb.Geometry) arrow.Record {
+	arrowSchema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "geometry", Type: arrow.BinaryTypes.Binary, Nullable: true},
+	}, schemaMetadata)
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, arrowSchema)
+	defer builder.Release()
+
+	for i, geometry := range geometries {
+		data, err := wkb.Marshal(geometry)
+		require.NoError(t, err)
+		builder.Field(0).(*array.StringBuilder).Append(fmt.Sprintf("feature-%d", i))
+		builder.Field(1).(*array.BinaryBuilder).Append(data)
+	}
+
+	return builder.NewRecord()
+}
+
+func newArrowFile(t *testing.T, record arrow.Record) *os.File {
+	f, err := os.CreateTemp(t.TempDir(), "*.arrow")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+
+	ipcWriter, err := ipc.NewFileWriter(f, ipc.WithSchema(record.Schema()))
+	require.NoError(t, err)
+	require.NoError(t, ipcWriter.Write(record))
+	require.NoError(t, ipcWriter.Close())
+
+	_, err = f.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	return f
+}
+
+func TestFromArrowFile(t *testing.T) {
+	record := newArrowRecord(t, nil, orb.Point{1, 2}, orb.LineString{{3, 4}, {-5, 6}})
+	defer record.Release()
+
+	input := newArrowFile(t, record)
+
+	output := &bytes.Buffer{}
+	require.NoError(t, geoparquet.FromArrow(input, output, nil))
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+
+	col := metadata.Columns[metadata.PrimaryColumn]
+	assert.Equal(t, geo.EncodingWKB, col.Encoding)
+	assert.ElementsMatch(t, []string{"Point", "LineString"}, col.GetGeometryTypes())
+	assert.Equal(t, []float64{-5, 2, 3, 6}, col.Bounds)
+	assert.Equal(t, int64(2), reader.NumRows())
+}
+
+func TestFromArrowStreamWithGeoMetadata(t *testing.T) {
+	schemaMetadata := arrow.NewMetadata(
+		[]string{geoparquet.MetadataKey},
+		[]string{`{"version": "1.0.0", "primary_column": "geometry", "columns": {"geometry": {"encoding": "WKB", "geometry_types": ["Point", "Polygon"]}}}`},
+	)
+	record := newArrowRecord(t, &schemaMetadata, orb.Point{1, 2})
+	defer record.Release()
+
+	input := &bytes.Buffer{}
+	ipcWriter := ipc.NewWriter(input, ipc.WithSchema(record.Schema()))
+	require.NoError(t, ipcWriter.Write(record))
+	require.NoError(t, ipcWriter.Close())
+
+	output := &bytes.Buffer{}
+	require.NoError(t, geoparquet.FromArrow(bytes.NewReader(input.Bytes()), output, nil))
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+
+	col := metadata.Columns[metadata.PrimaryColumn]
+	assert.Equal(t, []string{"Point", "Polygon"}, col.GetGeometryTypes())
+	assert.Equal(t, []float64{1, 2, 1, 2}, col.Bounds)
+}
+
+func TestFromArrowMissingGeometry(t *testing.T) {
+	record := newArrowRecord(t, nil, orb.Point{1, 2})
+	defer record.Release()
+
+	input := newArrowFile(t, record)
+
+	output := &bytes.Buffer{}
+	err := geoparquet.FromArrow(input, output, &geoparquet.ConvertOptions{InputPrimaryColumn: "geom"})
+	assert.ErrorContains(t, err, `expected a geometry column named "geom"`)
+}
+
+func newGeoParquetPart(t *testing.T, geometries ...orb.Geometry) parquet.ReaderAtSeeker {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "geometry", Type: arrow.BinaryTypes.Binary, Nullable: true},
+	}, nil)
+
+	output := &bytes.Buffer{}
+	writer, err := geoparquet.NewFeatureWriter(&geoparquet.WriterConfig{
+		Writer:      output,
+		ArrowSchema: schema,
+	})
+	require.NoError(t, err)
+
+	for i, geometry := range geometries {
+		feature := &geo.Feature{
+			Geometry:   geometry,
+			Properties: map[string]any{"name": fmt.Sprintf("feature-%d", i)},
+		}
+		require.NoError(t, writer.Write(feature))
+	}
+	require.NoError(t, writer.Close())
+	return bytes.NewReader(output.Bytes())
+}
+
+func TestFeatureWriterBoundsWithEmptyGeometries(t *testing.T) {
+	input := newGeoParquetPart(t,
+		orb.Point{10, 20},
+		orb.Point{math.NaN(), math.NaN()},
+		orb.Polygon{},
+		orb.Point{30, 40},
+	)
+
+	reader, err := file.NewParquetReader(input)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	primaryColumn := metadata.Columns[metadata.PrimaryColumn]
+	assert.Equal(t, []float64{10, 20, 30, 40}, primaryColumn.Bounds)
+	assert.Equal(t, []string{"Point", "Polygon"}, primaryColumn.GetGeometryTypes())
+}
+
+func TestFeatureWriterMaxBufferedBytes(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "geometry", Type: arrow.BinaryTypes.Binary, Nullable: true},
+	}, nil)
+
+	write := func(maxBufferedBytes int64) *file.Reader {
+		output := &bytes.Buffer{}
+		writer, err := geoparquet.NewFeatureWriter(&geoparquet.WriterConfig{
+			Writer:           output,
+			ArrowSchema:      schema,
+			MaxBufferedBytes: maxBufferedBytes,
+		})
+		require.NoError(t, err)
+
+		for i := 0; i < 100; i += 1 {
+			feature := &geo.Feature{
+				Geometry:   orb.Point{float64(i), float64(i)},
+				Properties: map[string]any{"name": strings.Repeat("x", 1000)},
+			}
+			require.NoError(t, writer.Write(feature))
+		}
+		require.NoError(t, writer.Close())
+
+		reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+		require.NoError(t, err)
+		return reader
+	}
+
+	unlimited := write(0)
+	defer unlimited.Close()
+	assert.Equal(t, 1, unlimited.NumRowGroups())
+
+	limited := write(10000)
+	defer limited.Close()
+	assert.Equal(t, int64(100), limited.NumRows())
+	assert.Greater(t, limited.NumRowGroups(), 1)
+	for i := 0; i < limited.NumRowGroups(); i += 1 {
+		assert.Greater(t, limited.RowGroup(i).NumRows(), int64(0))
+	}
+
+	_, err := geoparquet.NewFeatureWriter(&geoparquet.WriterConfig{
+		Writer:           &bytes.Buffer{},
+		ArrowSchema:      schema,
+		MaxBufferedBytes: -1,
+	})
+	assert.ErrorContains(t, err, "max buffered bytes must not be negative")
+}
+
+func TestRecordWriterMaxBufferedBytes(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+
+	output := &bytes.Buffer{}
+	writer, err := geoparquet.NewRecordWriter(&geoparquet.WriterConfig{
+		Writer:             output,
+		ArrowSchema:        schema,
+		ParquetWriterProps: parquet.NewWriterProperties(parquet.WithDataPageSize(1024), parquet.WithDictionaryDefault(false)),
+		MaxBufferedBytes:   1024,
+	})
+	require.NoError(t, err)
+
+	builder := array.NewStringBuilder(memory.DefaultAllocator)
+	defer builder.Release()
+	for i := 0; i < 10; i += 1 {
+		for j := 0; j < 10; j += 1 {
+			builder.Append(fmt.Sprintf("%d-%d-%s", i, j, strings.Repeat("x", 100)))
+		}
+		column := builder.NewArray()
+		record := array.NewRecord(schema, []arrow.Array{column}, int64(column.Len()))
+		require.NoError(t, writer.Write(record))
+		record.Release()
+		column.Release()
+	}
+	require.NoError(t, writer.Close())
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.Equal(t, int64(100), reader.NumRows())
+	assert.Greater(t, reader.NumRowGroups(), 1)
+}
+
+func TestDatasetReader(t *testing.T) {
+	parts := []parquet.ReaderAtSeeker{
+		newGeoParquetPart(t, orb.Point{1, 2}, orb.Point{3, 4}),
+		newGeoParquetPart(t, orb.LineString{{-1, 0}, {0, 10}}),
+	}
+
+	reader, err := geoparquet.NewDatasetReader(&geoparquet.DatasetConfig{Readers: parts})
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.Equal(t, int64(3), reader.NumRows())
+	assert.Equal(t, 2, reader.NumRowGroups())
+
+	metadata := reader.Metadata()
+	primaryColumn := metadata.Columns[metadata.PrimaryColumn]
+	assert.Equal(t, []float64{-1, 0, 3, 10}, primaryColumn.Bounds)
+	assert.Equal(t, []string{"Point", "LineString"}, primaryColumn.GetGeometryTypes())
+
+	numRows := int64(0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		numRows += record.NumRows()
+	}
+	assert.Equal(t, int64(3), numRows)
+}
+
+func TestDatasetReaderMismatchedSchema(t *testing.T) {
+	type Row struct {
+		Id       int64  `parquet:"name=id" json:"id"`
+		Geometry []byte `parquet:"name=geometry" json:"geometry"`
+	}
+	rows := []*Row{{Id: 1, Geometry: toWKB(t, orb.Point{1, 2})}}
+	output := &bytes.Buffer{}
+	require.NoError(t, geoparquet.FromParquet(test.ParquetFromStructs(t, rows), output, nil))
+
+	parts := []parquet.ReaderAtSeeker{
+		newGeoParquetPart(t, orb.Point{1, 2}),
+		bytes.NewReader(output.Bytes()),
+	}
+
+	_, err := geoparquet.NewDatasetReader(&geoparquet.DatasetConfig{Readers: parts})
+	assert.ErrorContains(t, err, "the schema of part 1 does not match the schema of the first part")
+}
+
+func TestFromDataset(t *testing.T) {
+	parts := []parquet.ReaderAtSeeker{
+		newGeoParquetPart(t, orb.Point{1, 2}),
+		newGeoParquetPart(t, orb.Point{3, 4}, orb.Point{5, 6}),
+	}
+
+	output := &bytes.Buffer{}
+	require.NoError(t, geoparquet.FromDataset(parts, output, nil))
+
+	reader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.Equal(t, int64(3), reader.NumRows())
+
+	metadata, err := geoparquet.GetMetadata(reader.MetaData().KeyValueMetadata())
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 2, 5, 6}, metadata.Columns[metadata.PrimaryColumn].Bounds)
+}
@@ -3,6 +3,9 @@ package geoparquet
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"slices"
+	"strings"
 
 	"github.com/apache/arrow/go/v16/parquet/metadata"
 	"github.com/planetlabs/gpq/internal/geo"
@@ -18,6 +21,31 @@ const (
 	DefaultGeometryEncoding     = geo.EncodingWKB
 )
 
+// Versions are the GeoParquet versions that can be declared in written metadata.
+var Versions = []string{
+	"1.0.0-beta.1",
+	"1.0.0",
+	"1.1.0",
+}
+
+// ValidateVersion returns an error if the version is not empty and not one of
+// the known Versions.
+func ValidateVersion(version string) error {
+	if version != "" && !slices.Contains(Versions, version) {
+		return fmt.Errorf("unsupported version %q, expected one of %s", version, strings.Join(Versions, ", "))
+	}
+	return nil
+}
+
+// SetVersion sets the declared version.  An empty value leaves the metadata
+// unchanged.
+func (m *Metadata) SetVersion(version string) {
+	if version == "" {
+		return
+	}
+	m.Version = version
+}
+
 var GeometryTypes = []string{
 	"Point",
 	"LineString",
@@ -35,6 +63,60 @@ var GeometryTypes = []string{
 	"GeometryCollection Z",
 }
 
+// ValidateGeometryTypes returns an error if any of the provided types is not
+// one of the supported GeometryTypes.
+func ValidateGeometryTypes(types []string) error {
+	for _, geometryType := range types {
+		if !slices.Contains(GeometryTypes, geometryType) {
+			return fmt.Errorf("unsupported geometry type %q", geometryType)
+		}
+	}
+	return nil
+}
+
+// ValidateEdges returns an error if the edges value is not empty, planar, or
+// spherical.
+func ValidateEdges(edges string) error {
+	if edges != "" && edges != EdgesPlanar && edges != EdgesSpherical {
+		return fmt.Errorf("unsupported edges %q, expected %q or %q", edges, EdgesPlanar, EdgesSpherical)
+	}
+	return nil
+}
+
+// SetEdges sets the edges for all geometry columns.  An empty value leaves the
+// metadata unchanged.
+func (m *Metadata) SetEdges(edges string) {
+	if edges == "" {
+		return
+	}
+	for _, column := range m.Columns {
+		column.Edges = edges
+	}
+}
+
+// RoundBounds rounds the "bbox" of every column to the given number of decimal
+// places.  The minimum values are rounded down and the maximum values are
+// rounded up so that the bounds still contain all of the geometries.  A
+// precision of zero rounds to integers.
+func (m *Metadata) RoundBounds(precision int) {
+	scale := math.Pow10(precision)
+	for _, column := range m.Columns {
+		if column == nil {
+			continue
+		}
+		dims := len(column.Bounds) / 2
+		rounded := make([]float64, len(column.Bounds))
+		for i, value := range column.Bounds {
+			if i < dims {
+				rounded[i] = math.Floor(value*scale) / scale
+			} else {
+				rounded[i] = math.Ceil(value*scale) / scale
+			}
+		}
+		column.Bounds = rounded
+	}
+}
+
 type Metadata struct {
 	Version       string                     `json:"version"`
 	PrimaryColumn string                     `json:"primary_column"`
@@ -51,6 +133,38 @@ func (m *Metadata) Clone() *Metadata {
 	return clone
 }
 
+// MarshalStable encodes the metadata so that the same metadata always
+// produces the same bytes.  Geometry types are sorted in the order of
+// GeometryTypes.  If indent is not empty, the output is indented.
+func (m *Metadata) MarshalStable(indent string) ([]byte, error) {
+	stable := m.Clone()
+	for _, col := range stable.Columns {
+		types := col.GetGeometryTypes()
+		if types == nil {
+			continue
+		}
+		slices.SortStableFunc(types, compareGeometryTypes)
+		col.GeometryTypes = types
+	}
+	if indent != "" {
+		return json.MarshalIndent(stable, "", indent)
+	}
+	return json.Marshal(stable)
+}
+
+func compareGeometryTypes(a string, b string) int {
+	aIndex := slices.Index(GeometryTypes, a)
+	bIndex := slices.Index(GeometryTypes, b)
+	if aIndex < 0 || bIndex < 0 {
+		if aIndex == bIndex {
+			return strings.Compare(a, b)
+		}
+		// unknown types sort last
+		return bIndex - aIndex
+	}
+	return aIndex - bIndex
+}
+
 type ProjId struct {
 	Authority string `json:"authority"`
 	Code      any    `json:"code"`
@@ -59,9 +173,43 @@ type ProjId struct {
 type Proj struct {
 	Name string  `json:"name"`
 	Id   *ProjId `json:"id"`
+
+	// Definition holds a CRS given as a string (e.g. WKT2 or an
+	// "authority:code" identifier) instead of a PROJJSON object.
+	Definition string `json:"-"`
+}
+
+type projObject Proj
+
+func (p *Proj) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*p = Proj{}
+		return json.Unmarshal(data, &p.Definition)
+	}
+	return json.Unmarshal(data, (*projObject)(p))
+}
+
+func (p *Proj) MarshalJSON() ([]byte, error) {
+	if p.Definition != "" {
+		return json.Marshal(p.Definition)
+	}
+	return json.Marshal((*projObject)(p))
 }
 
 func (p *Proj) String() string {
+	if p.Definition != "" {
+		// use the name of a WKT definition (e.g. GEOGCRS["WGS 84", ...])
+		start := strings.Index(p.Definition, "[\"")
+		if start < 0 {
+			return p.Definition
+		}
+		name := p.Definition[start+2:]
+		end := strings.Index(name, "\"")
+		if end < 0 {
+			return p.Definition
+		}
+		return name[:end]
+	}
 	id := ""
 	if p.Id != nil {
 		if code, ok := p.Id.Code.(string); ok {
@@ -90,6 +238,27 @@ type GeometryColumn struct {
 	Epoch         float64   `json:"epoch,omitempty"`
 }
 
+type geometryColumn GeometryColumn
+
+// MarshalJSON encodes the column metadata.  Missing geometry types are written
+// as an empty list instead of null, since "geometry_types" is required.
+func (g GeometryColumn) MarshalJSON() ([]byte, error) {
+	col := geometryColumn(g)
+	switch types := col.GeometryTypes.(type) {
+	case nil:
+		col.GeometryTypes = []string{}
+	case []string:
+		if types == nil {
+			col.GeometryTypes = []string{}
+		}
+	case []any:
+		if types == nil {
+			col.GeometryTypes = []string{}
+		}
+	}
+	return json.Marshal(col)
+}
+
 func (g *GeometryColumn) clone() *GeometryColumn {
 	clone := &GeometryColumn{}
 	*clone = *g
@@ -99,6 +268,10 @@ func (g *GeometryColumn) clone() *GeometryColumn {
 }
 
 func (col *GeometryColumn) GetGeometryTypes() []string {
+	if types, ok := col.GeometryTypes.([]string); ok {
+		return slices.Clone(types)
+	}
+
 	if multiType, ok := col.GeometryTypes.([]any); ok {
 		types := make([]string, len(multiType))
 		for i, value := range multiType {
@@ -157,14 +330,168 @@ func GetMetadata(keyValueMetadata metadata.KeyValueMetadata) (*Metadata, error)
 	if err != nil {
 		return nil, err
 	}
+	return ParseMetadata(value)
+}
+
+// ParseMetadata decodes a "geo" metadata value.  Legacy "geometry_type" column
+// members are replaced with "geometry_types".
+func ParseMetadata(value string) (*Metadata, error) {
 	geoFileMetadata := &Metadata{}
 	jsonErr := json.Unmarshal([]byte(value), geoFileMetadata)
 	if jsonErr != nil {
 		return nil, fmt.Errorf("unable to parse %s metadata: %w", MetadataKey, jsonErr)
 	}
+	for _, col := range geoFileMetadata.Columns {
+		if col == nil || col.GeometryTypes != nil || col.GeometryType == nil {
+			continue
+		}
+		col.GeometryTypes = canonicalGeometryTypes(col.GeometryType)
+		col.GeometryType = nil
+	}
 	return geoFileMetadata, nil
 }
 
+// MetadataFromMap builds the metadata from a "geo" metadata value that has
+// already been decoded into a generic map, so that callers that need both views
+// only decode the JSON once.  Legacy "geometry_type" column members are replaced
+// with "geometry_types" (in the map as well).  Members with the wrong type are
+// an error, as they are for ParseMetadata.
+func MetadataFromMap(value map[string]any) (*Metadata, error) {
+	geoFileMetadata := &Metadata{}
+	var err error
+	if geoFileMetadata.Version, err = stringMember(value, "version"); err != nil {
+		return nil, fmt.Errorf("unable to parse %s metadata: %w", MetadataKey, err)
+	}
+	if geoFileMetadata.PrimaryColumn, err = stringMember(value, "primary_column"); err != nil {
+		return nil, fmt.Errorf("unable to parse %s metadata: %w", MetadataKey, err)
+	}
+
+	columns, ok := value["columns"]
+	if !ok || columns == nil {
+		return geoFileMetadata, nil
+	}
+	columnsMap, ok := columns.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unable to parse %s metadata: %q must be an object", MetadataKey, "columns")
+	}
+	geoFileMetadata.Columns = make(map[string]*GeometryColumn, len(columnsMap))
+	for name, columnValue := range columnsMap {
+		if columnValue == nil {
+			geoFileMetadata.Columns[name] = nil
+			continue
+		}
+		col, ok := columnValue.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unable to parse %s metadata: column %q must be an object", MetadataKey, name)
+		}
+		column, err := geometryColumnFromMap(col)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s metadata for column %q: %w", MetadataKey, name, err)
+		}
+		geoFileMetadata.Columns[name] = column
+	}
+	return geoFileMetadata, nil
+}
+
+func geometryColumnFromMap(col map[string]any) (*GeometryColumn, error) {
+	CanonicalizeColumnMetadata(col)
+	column := &GeometryColumn{GeometryTypes: col["geometry_types"]}
+	var err error
+	if column.Encoding, err = stringMember(col, "encoding"); err != nil {
+		return nil, err
+	}
+	if column.Edges, err = stringMember(col, "edges"); err != nil {
+		return nil, err
+	}
+	if column.Orientation, err = stringMember(col, "orientation"); err != nil {
+		return nil, err
+	}
+
+	if epoch, ok := col["epoch"]; ok && epoch != nil {
+		value, ok := epoch.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%q must be a number", "epoch")
+		}
+		column.Epoch = value
+	}
+
+	if bbox, ok := col["bbox"]; ok && bbox != nil {
+		values, ok := bbox.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%q must be an array of numbers", "bbox")
+		}
+		column.Bounds = make([]float64, len(values))
+		for i, v := range values {
+			value, ok := v.(float64)
+			if !ok {
+				return nil, fmt.Errorf("%q must be an array of numbers", "bbox")
+			}
+			column.Bounds[i] = value
+		}
+	}
+
+	switch crs := col["crs"].(type) {
+	case nil:
+	case string:
+		column.CRS = &Proj{Definition: crs}
+	case map[string]any:
+		proj := &Proj{}
+		if proj.Name, err = stringMember(crs, "name"); err != nil {
+			return nil, fmt.Errorf("crs: %w", err)
+		}
+		switch id := crs["id"].(type) {
+		case nil:
+		case map[string]any:
+			authority, err := stringMember(id, "authority")
+			if err != nil {
+				return nil, fmt.Errorf("crs id: %w", err)
+			}
+			proj.Id = &ProjId{Authority: authority, Code: id["code"]}
+		default:
+			return nil, fmt.Errorf("crs %q must be an object", "id")
+		}
+		column.CRS = proj
+	default:
+		return nil, fmt.Errorf("%q must be an object or a string", "crs")
+	}
+	return column, nil
+}
+
+// stringMember returns the string value of a member of a decoded JSON object.
+// A missing or null member is returned as an empty string.
+func stringMember(object map[string]any, name string) (string, error) {
+	value, ok := object[name]
+	if !ok || value == nil {
+		return "", nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%q must be a string", name)
+	}
+	return str, nil
+}
+
+// CanonicalizeColumnMetadata replaces the legacy "geometry_type" member of
+// decoded column metadata with "geometry_types" if the latter is not present.
+func CanonicalizeColumnMetadata(col map[string]any) {
+	if _, ok := col["geometry_types"]; ok {
+		return
+	}
+	geometryType, ok := col["geometry_type"]
+	if !ok {
+		return
+	}
+	col["geometry_types"] = canonicalGeometryTypes(geometryType)
+	delete(col, "geometry_type")
+}
+
+func canonicalGeometryTypes(geometryType any) any {
+	if singleType, ok := geometryType.(string); ok {
+		return []any{singleType}
+	}
+	return geometryType
+}
+
 func GetMetadataValue(keyValueMetadata metadata.KeyValueMetadata) (string, error) {
 	var value *string
 	for _, kv := range keyValueMetadata {
@@ -0,0 +1,143 @@
+package geoparquet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
+	"github.com/apache/arrow/go/v16/parquet"
+	"github.com/apache/arrow/go/v16/parquet/schema"
+	"github.com/paulmach/orb/encoding/wkb"
+	"github.com/planetlabs/gpq/internal/geo"
+	"github.com/planetlabs/gpq/internal/pqutil"
+)
+
+// nestedColumn is a geometry column inside a struct (e.g. "feature.geom").
+// When converting, the values are copied to a top-level column named after the
+// last part of the path.
+type nestedColumn struct {
+	// Path is the dotted path to the column.
+	Path string
+
+	// Name is the name of the top-level column written to the output.
+	Name string
+
+	// Encoding is the encoding of the values in the input.
+	Encoding string
+
+	fields []string
+}
+
+// getNestedColumn returns the nested column for a dotted path or nil if the
+// name is not a path into a group.  Top-level columns with a dot in their name
+// are not treated as paths.
+func getNestedColumn(inputSchema *schema.Schema, name string) (*nestedColumn, error) {
+	if !strings.Contains(name, ".") || inputSchema.Root().FieldIndexByName(name) >= 0 {
+		return nil, nil
+	}
+	fields := strings.Split(name, ".")
+	node, ok := pqutil.LookupNestedNode(inputSchema, fields)
+	if !ok {
+		return nil, fmt.Errorf("expected a geometry column at the path %q", name)
+	}
+	primitive, ok := node.(*schema.PrimitiveNode)
+	if !ok || primitive.RepetitionType() == parquet.Repetitions.Repeated {
+		return nil, fmt.Errorf("expected the geometry column at %q to be a binary or string column", name)
+	}
+
+	encoding := geo.EncodingWKB
+	switch primitive.PhysicalType() {
+	case parquet.Types.ByteArray:
+		if primitive.LogicalType() == pqutil.ParquetStringType {
+			encoding = geo.EncodingWKT
+		}
+	case parquet.Types.FixedLenByteArray:
+	default:
+		return nil, fmt.Errorf("expected the geometry column at %q to be a binary or string column, got %s", name, primitive.PhysicalType())
+	}
+
+	column := &nestedColumn{
+		Path:     name,
+		Name:     fields[len(fields)-1],
+		Encoding: encoding,
+		fields:   fields,
+	}
+	return column, nil
+}
+
+// source returns the name of the top-level group that contains the column.
+func (c *nestedColumn) source() string {
+	return c.fields[0]
+}
+
+// extract returns WKB encoded geometries from the struct values in the
+// top-level group.  A geometry is null if it or any of its parents are null.
+// Geometry types and bounds are added to the stats for WKT values.
+func (c *nestedColumn) extract(chunked *arrow.Chunked, stats *geo.GeometryStats) (*arrow.Chunked, error) {
+	chunks := chunked.Chunks()
+	extracted := make([]arrow.Array, len(chunks))
+	builder := array.NewBinaryBuilder(memory.DefaultAllocator, arrow.BinaryTypes.Binary)
+	defer builder.Release()
+
+	for i, arr := range chunks {
+		parents := []arrow.Array{arr}
+		for _, name := range c.fields[1:] {
+			structArr, ok := parents[len(parents)-1].(*array.Struct)
+			if !ok {
+				return nil, fmt.Errorf("expected a struct array for %q, got %s", c.Path, parents[len(parents)-1].DataType())
+			}
+			index, ok := structArr.DataType().(*arrow.StructType).FieldIdx(name)
+			if !ok {
+				return nil, fmt.Errorf("missing field %q for %q", name, c.Path)
+			}
+			parents = append(parents, structArr.Field(index))
+		}
+
+		for rowNum := 0; rowNum < arr.Len(); rowNum += 1 {
+			isNull := false
+			for _, parent := range parents {
+				if parent.IsNull(rowNum) {
+					isNull = true
+					break
+				}
+			}
+			if isNull {
+				builder.AppendNull()
+				continue
+			}
+
+			value := parents[len(parents)-1].GetOneForMarshal(rowNum)
+			if c.Encoding == geo.EncodingWKB {
+				data, ok := value.([]byte)
+				if !ok {
+					return nil, fmt.Errorf("expected bytes for %q in row %d, got %T", c.Path, rowNum, value)
+				}
+				builder.Append(data)
+				continue
+			}
+
+			geometry, err := geo.DecodeGeometry(value, c.Encoding)
+			if err != nil {
+				return nil, fmt.Errorf("trouble decoding geometry for %q in row %d: %w", c.Path, rowNum, err)
+			}
+			if geometry == nil {
+				builder.AppendNull()
+				continue
+			}
+			data, err := wkb.Marshal(geometry.Coordinates)
+			if err != nil {
+				return nil, err
+			}
+			stats.AddType(geometry.Coordinates.GeoJSONType())
+			if !geo.IsEmpty(geometry.Coordinates) {
+				bounds := geometry.Coordinates.Bound()
+				stats.AddBounds(&bounds)
+			}
+			builder.Append(data)
+		}
+		extracted[i] = builder.NewArray()
+	}
+	return arrow.NewChunked(builder.Type(), extracted), nil
+}
@@ -2,14 +2,22 @@ package geoparquet
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
 
 	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
 	"github.com/apache/arrow/go/v16/arrow/memory"
 	"github.com/apache/arrow/go/v16/parquet"
 	"github.com/apache/arrow/go/v16/parquet/file"
+	"github.com/apache/arrow/go/v16/parquet/metadata"
 	"github.com/apache/arrow/go/v16/parquet/pqarrow"
 	"github.com/apache/arrow/go/v16/parquet/schema"
+	"github.com/paulmach/orb"
+	"github.com/planetlabs/gpq/internal/geo"
 )
 
 const (
@@ -21,12 +29,40 @@ type ReaderConfig struct {
 	Reader    parquet.ReaderAtSeeker
 	File      *file.Reader
 	Context   context.Context
+
+	// MetadataOnly limits the reader to the file footer.  No Arrow or record
+	// reader is created, so the metadata and schema are available but Read
+	// returns an error.
+	MetadataOnly bool
+
+	// Bbox, if not nil, limits the records returned by Read to rows with a
+	// primary geometry that intersects the box.  If the primary column "bbox"
+	// in the metadata does not intersect the box, no rows are read (a "bbox"
+	// with xmin > xmax crosses the antimeridian).  If the primary column has a
+	// "bbox" covering, row groups with column statistics that do not intersect
+	// the box are skipped.  The geometries in the
+	// remaining row groups are decoded to get their bounds.
+	Bbox *orb.Bound
+
+	// Metadata, if not nil, is used instead of parsing the "geo" metadata from
+	// the file (e.g. when it has already been parsed).
+	Metadata *Metadata
+
+	// RowGroups, if not nil, limits the records returned by Read to the rows
+	// in these row groups.
+	RowGroups []int
 }
 
+// ErrMetadataOnly is returned by Read for readers created with MetadataOnly.
+var ErrMetadataOnly = errors.New("cannot read records from a metadata only reader")
+
 type RecordReader struct {
+	ctx          context.Context
 	fileReader   *file.Reader
 	metadata     *Metadata
 	recordReader pqarrow.RecordReader
+	bbox         *orb.Bound
+	skip         bool
 }
 
 func NewRecordReader(config *ReaderConfig) (*RecordReader, error) {
@@ -52,9 +88,40 @@ func NewRecordReader(config *ReaderConfig) (*RecordReader, error) {
 		fileReader = fr
 	}
 
-	geoMetadata, geoMetadataErr := GetMetadata(fileReader.MetaData().GetKeyValueMetadata())
-	if geoMetadataErr != nil {
-		return nil, geoMetadataErr
+	geoMetadata := config.Metadata
+	if geoMetadata == nil {
+		parsed, err := GetMetadata(fileReader.MetaData().GetKeyValueMetadata())
+		if err != nil {
+			return nil, err
+		}
+		geoMetadata = parsed
+	}
+
+	if config.MetadataOnly {
+		reader := &RecordReader{
+			fileReader: fileReader,
+			metadata:   geoMetadata,
+		}
+		return reader, nil
+	}
+
+	rowGroups := config.RowGroups
+	skip := false
+	if config.Bbox != nil {
+		primary, ok := geoMetadata.Columns[geoMetadata.PrimaryColumn]
+		if !ok {
+			return nil, fmt.Errorf("missing metadata for primary column %q", geoMetadata.PrimaryColumn)
+		}
+		fileBound, hasBound := boundFromBounds(primary.Bounds)
+		if hasBound && !intersectsBbox(fileBound.Min.X(), fileBound.Min.Y(), fileBound.Max.X(), fileBound.Max.Y(), *config.Bbox) {
+			skip = true
+		} else {
+			// rows are only assumed not to cross the antimeridian if the file
+			// bbox does not
+			mayWrap := !hasBound || fileBound.Min.X() > fileBound.Max.X()
+			rowGroups = pruneRowGroups(fileReader, geoMetadata.PrimaryColumn, rowGroups, *config.Bbox, mayWrap)
+			skip = rowGroups != nil && len(rowGroups) == 0
+		}
 	}
 
 	arrowReader, arrowErr := pqarrow.NewFileReader(fileReader, pqarrow.ArrowReadProperties{BatchSize: int64(batchSize)}, memory.DefaultAllocator)
@@ -62,32 +129,347 @@ func NewRecordReader(config *ReaderConfig) (*RecordReader, error) {
 		return nil, arrowErr
 	}
 
-	recordReader, recordErr := arrowReader.GetRecordReader(ctx, nil, nil)
+	recordReader, recordErr := arrowReader.GetRecordReader(ctx, nil, rowGroups)
 	if recordErr != nil {
 		return nil, recordErr
 	}
 
 	reader := &RecordReader{
+		ctx:          ctx,
 		fileReader:   fileReader,
 		metadata:     geoMetadata,
 		recordReader: recordReader,
+		bbox:         config.Bbox,
+		skip:         skip,
 	}
 	return reader, nil
 }
 
+// bboxCovering holds the paths to the columns of a "bbox" covering.
+type bboxCovering struct {
+	Xmin []string `json:"xmin"`
+	Ymin []string `json:"ymin"`
+	Xmax []string `json:"xmax"`
+	Ymax []string `json:"ymax"`
+}
+
+// getBboxCovering returns the "bbox" covering for a geometry column from the
+// raw "geo" metadata.  Nil is returned if the column has no usable covering.
+func getBboxCovering(fileReader *file.Reader, columnName string) *bboxCovering {
+	value, err := GetMetadataValue(fileReader.MetaData().KeyValueMetadata())
+	if err != nil {
+		return nil
+	}
+	raw := &struct {
+		Columns map[string]struct {
+			Covering struct {
+				Bbox *bboxCovering `json:"bbox"`
+			} `json:"covering"`
+		} `json:"columns"`
+	}{}
+	if err := json.Unmarshal([]byte(value), raw); err != nil {
+		return nil
+	}
+	covering := raw.Columns[columnName].Covering.Bbox
+	if covering == nil || len(covering.Xmin) == 0 || len(covering.Ymin) == 0 || len(covering.Xmax) == 0 || len(covering.Ymax) == 0 {
+		return nil
+	}
+	return covering
+}
+
+// pruneRowGroups returns the row groups that may have geometries intersecting
+// the bbox based on the statistics of the primary column "bbox" covering.  If
+// rowGroups is nil, all row groups are considered.  Without a covering, the
+// row groups are returned as is.  If mayWrap is true, the covering may have
+// rows with xmin > xmax (crossing the antimeridian), so a row group is only
+// skipped based on x if both its xmin and xmax statistics are outside the bbox.
+func pruneRowGroups(fileReader *file.Reader, columnName string, rowGroups []int, bbox orb.Bound, mayWrap bool) []int {
+	covering := getBboxCovering(fileReader, columnName)
+	if covering == nil {
+		return rowGroups
+	}
+
+	if rowGroups == nil {
+		rowGroups = make([]int, fileReader.NumRowGroups())
+		for i := range rowGroups {
+			rowGroups[i] = i
+		}
+	}
+
+	fileSchema := fileReader.MetaData().Schema
+	indices := make([]int, 4)
+	for i, path := range [][]string{covering.Xmin, covering.Ymin, covering.Xmax, covering.Ymax} {
+		indices[i] = fileSchema.ColumnIndexByName(strings.Join(path, "."))
+		if indices[i] < 0 {
+			return rowGroups
+		}
+	}
+
+	pruned := []int{}
+	for _, rowGroup := range rowGroups {
+		rowGroupMetadata := fileReader.MetaData().RowGroup(rowGroup)
+		xmin, xminOk := columnStatistic(rowGroupMetadata, indices[0], true)
+		ymin, yminOk := columnStatistic(rowGroupMetadata, indices[1], true)
+		xmax, xmaxOk := columnStatistic(rowGroupMetadata, indices[2], false)
+		ymax, ymaxOk := columnStatistic(rowGroupMetadata, indices[3], false)
+		if yminOk && ymin > bbox.Max.Y() || ymaxOk && ymax < bbox.Min.Y() {
+			continue
+		}
+		east := xminOk && xmin > bbox.Max.X()
+		west := xmaxOk && xmax < bbox.Min.X()
+		if mayWrap && east && west || !mayWrap && (east || west) {
+			continue
+		}
+		pruned = append(pruned, rowGroup)
+	}
+	return pruned
+}
+
+// columnStatistic returns the min (or max) value of a floating point column
+// in a row group.  False is returned if the statistics are not available.
+func columnStatistic(rowGroup *metadata.RowGroupMetaData, columnIndex int, min bool) (float64, bool) {
+	chunk, err := rowGroup.ColumnChunk(columnIndex)
+	if err != nil {
+		return 0, false
+	}
+	if set, err := chunk.StatsSet(); err != nil || !set {
+		return 0, false
+	}
+	statistics, err := chunk.Statistics()
+	if err != nil || statistics == nil || !statistics.HasMinMax() {
+		return 0, false
+	}
+	switch stats := statistics.(type) {
+	case *metadata.Float64Statistics:
+		if min {
+			return stats.Min(), true
+		}
+		return stats.Max(), true
+	case *metadata.Float32Statistics:
+		if min {
+			return float64(stats.Min()), true
+		}
+		return float64(stats.Max()), true
+	}
+	return 0, false
+}
+
+// intersectsBbox returns true if a box intersects the bbox.  A box with xmin >
+// xmax crosses the antimeridian.
+func intersectsBbox(xmin float64, ymin float64, xmax float64, ymax float64, bbox orb.Bound) bool {
+	if ymin > bbox.Max.Y() || ymax < bbox.Min.Y() {
+		return false
+	}
+	if xmin > xmax {
+		return xmin <= bbox.Max.X() || xmax >= bbox.Min.X()
+	}
+	return xmin <= bbox.Max.X() && xmax >= bbox.Min.X()
+}
+
+// boundFromBounds returns the 2D bound for a metadata "bbox" value.  For a
+// bbox that crosses the antimeridian, Min.X is greater than Max.X.
+func boundFromBounds(bounds []float64) (orb.Bound, bool) {
+	if len(bounds) != 4 && len(bounds) != 6 {
+		return orb.Bound{}, false
+	}
+	dims := len(bounds) / 2
+	return orb.Bound{
+		Min: orb.Point{bounds[0], bounds[1]},
+		Max: orb.Point{bounds[dims], bounds[dims+1]},
+	}, true
+}
+
+// Read returns the next record.  If the reader context is done, the context
+// error is returned.
 func (r *RecordReader) Read() (arrow.Record, error) {
-	return r.recordReader.Read()
+	if r.recordReader == nil {
+		return nil, ErrMetadataOnly
+	}
+	if err := r.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r.bbox == nil {
+		return r.recordReader.Read()
+	}
+	if r.skip {
+		return nil, io.EOF
+	}
+	for {
+		record, err := r.recordReader.Read()
+		if err != nil {
+			return nil, err
+		}
+		filtered, err := r.filter(record)
+		if err != nil {
+			return nil, err
+		}
+		if filtered.NumRows() > 0 {
+			return filtered, nil
+		}
+		filtered.Release()
+	}
+}
+
+// filter returns a record with the rows that have a primary geometry that
+// intersects the bbox.
+func (r *RecordReader) filter(record arrow.Record) (arrow.Record, error) {
+	name := r.metadata.PrimaryColumn
+	indices := record.Schema().FieldIndices(name)
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("missing primary geometry column %q", name)
+	}
+	column := record.Column(indices[0])
+	encoding := r.metadata.Columns[name].Encoding
+
+	parts := []arrow.Record{}
+	defer func() {
+		for _, part := range parts {
+			part.Release()
+		}
+	}()
+
+	start := -1
+	numRows := int(record.NumRows())
+	for i := 0; i <= numRows; i += 1 {
+		keep := false
+		if i < numRows {
+			geometry, err := geo.DecodeGeometry(column.GetOneForMarshal(i), encoding)
+			if err != nil {
+				return nil, fmt.Errorf("trouble decoding geometry in row %d: %w", i, err)
+			}
+			keep = geometry != nil && geometry.Geometry().Bound().Intersects(*r.bbox)
+		}
+		if keep && start < 0 {
+			start = i
+		}
+		if !keep && start >= 0 {
+			parts = append(parts, record.NewSlice(int64(start), int64(i)))
+			start = -1
+		}
+	}
+
+	switch len(parts) {
+	case 0:
+		return record.NewSlice(0, 0), nil
+	case 1:
+		parts[0].Retain()
+		return parts[0], nil
+	}
+
+	columns := make([]arrow.Array, record.NumCols())
+	total := int64(0)
+	for _, part := range parts {
+		total += part.NumRows()
+	}
+	for i := range columns {
+		arrays := make([]arrow.Array, len(parts))
+		for j, part := range parts {
+			arrays[j] = part.Column(i)
+		}
+		concatenated, err := array.Concatenate(arrays, memory.DefaultAllocator)
+		if err != nil {
+			return nil, err
+		}
+		defer concatenated.Release()
+		columns[i] = concatenated
+	}
+	return array.NewRecord(record.Schema(), columns, total), nil
+}
+
+// ReadFeatureAt returns the feature for the row with the given zero-based index
+// in the file.  Only the row group containing the row is read.  The primary
+// geometry is decoded as the feature geometry, other geometry columns are
+// decoded as geometry properties, and the remaining columns are properties.
+// The file reader is not closed.
+func ReadFeatureAt(fileReader *file.Reader, index int64) (*geo.Feature, error) {
+	numRows := fileReader.NumRows()
+	if index < 0 || index >= numRows {
+		return nil, fmt.Errorf("feature index %d is out of range, the file has %d rows", index, numRows)
+	}
+
+	rowGroup := 0
+	offset := index
+	for ; rowGroup < fileReader.NumRowGroups(); rowGroup += 1 {
+		rowGroupRows := fileReader.MetaData().RowGroup(rowGroup).NumRows()
+		if offset < rowGroupRows {
+			break
+		}
+		offset -= rowGroupRows
+	}
+
+	recordReader, err := NewRecordReader(&ReaderConfig{
+		File:      fileReader,
+		RowGroups: []int{rowGroup},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer recordReader.release()
+
+	for {
+		record, err := recordReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("expected %d more rows in row group %d", offset+1, rowGroup)
+			}
+			return nil, err
+		}
+		if offset >= record.NumRows() {
+			offset -= record.NumRows()
+			continue
+		}
+		return featureFromRecord(record, int(offset), recordReader.Metadata())
+	}
+}
+
+// featureFromRecord decodes a single row of a record as a feature.
+func featureFromRecord(record arrow.Record, rowNum int, geoMetadata *Metadata) (*geo.Feature, error) {
+	feature := &geo.Feature{Type: "Feature", Properties: map[string]any{}}
+	for fieldNum, field := range record.Schema().Fields() {
+		value := record.Column(fieldNum).GetOneForMarshal(rowNum)
+		geometryCol, ok := geoMetadata.Columns[field.Name]
+		if !ok {
+			feature.Properties[field.Name] = value
+			continue
+		}
+		decoded, err := geo.DecodeGeometry(value, geometryCol.Encoding)
+		if err != nil {
+			return nil, fmt.Errorf("trouble decoding geometry for %q: %w", field.Name, err)
+		}
+		var geometry orb.Geometry
+		if decoded != nil {
+			geometry = decoded.Geometry()
+		}
+		if field.Name == geoMetadata.PrimaryColumn {
+			feature.Geometry = geometry
+			continue
+		}
+		feature.Properties[field.Name] = geometry
+	}
+	return feature, nil
 }
 
 func (r *RecordReader) Metadata() *Metadata {
 	return r.metadata
 }
 
+// KeyValueMetadata returns all of the key-value metadata from the file.
+func (r *RecordReader) KeyValueMetadata() metadata.KeyValueMetadata {
+	return r.fileReader.MetaData().KeyValueMetadata()
+}
+
 func (r *RecordReader) Schema() *schema.Schema {
 	return r.fileReader.MetaData().Schema
 }
 
 func (r *RecordReader) Close() error {
-	r.recordReader.Release()
+	r.release()
 	return r.fileReader.Close()
 }
+
+// release releases the record reader without closing the file reader.
+func (r *RecordReader) release() {
+	if r.recordReader != nil {
+		r.recordReader.Release()
+	}
+}
@@ -1,19 +1,23 @@
 package geoparquet
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 
 	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/apache/arrow/go/v16/parquet"
 	"github.com/apache/arrow/go/v16/parquet/pqarrow"
+	"github.com/planetlabs/gpq/internal/geo"
 )
 
 type RecordWriter struct {
 	fileWriter       *pqarrow.FileWriter
 	metadata         *Metadata
+	boundsPrecision  *int
+	maxBufferedBytes int64
+	newRowGroup      bool
 	wroteGeoMetadata bool
+	omitMetadata     bool
 }
 
 func NewRecordWriter(config *WriterConfig) (*RecordWriter, error) {
@@ -35,14 +39,26 @@ func NewRecordWriter(config *WriterConfig) (*RecordWriter, error) {
 	if config.Writer == nil {
 		return nil, errors.New("writer is required")
 	}
+
+	if err := geo.ValidatePrecision(config.BoundsPrecision); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateMaxBufferedBytes(config.MaxBufferedBytes); err != nil {
+		return nil, err
+	}
+
 	fileWriter, fileErr := pqarrow.NewFileWriter(config.ArrowSchema, config.Writer, parquetProps, *arrowProps)
 	if fileErr != nil {
 		return nil, fileErr
 	}
 
 	writer := &RecordWriter{
-		fileWriter: fileWriter,
-		metadata:   config.Metadata,
+		fileWriter:       fileWriter,
+		metadata:         config.Metadata,
+		boundsPrecision:  config.BoundsPrecision,
+		maxBufferedBytes: config.MaxBufferedBytes,
+		omitMetadata:     config.OmitMetadata,
 	}
 
 	return writer, nil
@@ -59,16 +75,30 @@ func (w *RecordWriter) AppendKeyValueMetadata(key string, value string) error {
 }
 
 func (w *RecordWriter) Write(record arrow.Record) error {
-	return w.fileWriter.WriteBuffered(record)
+	if w.newRowGroup {
+		w.fileWriter.NewBufferedRowGroup()
+		w.newRowGroup = false
+	}
+	if err := w.fileWriter.WriteBuffered(record); err != nil {
+		return err
+	}
+	if w.maxBufferedBytes > 0 && w.fileWriter.RowGroupTotalCompressedBytes() >= w.maxBufferedBytes {
+		w.newRowGroup = true
+	}
+	return nil
 }
 
 func (w *RecordWriter) Close() error {
-	if !w.wroteGeoMetadata {
+	if !w.wroteGeoMetadata && !w.omitMetadata {
 		metadata := w.metadata
 		if metadata == nil {
 			metadata = DefaultMetadata()
 		}
-		data, err := json.Marshal(metadata)
+		if w.boundsPrecision != nil {
+			metadata = metadata.Clone()
+			metadata.RoundBounds(*w.boundsPrecision)
+		}
+		data, err := metadata.MarshalStable("")
 		if err != nil {
 			return fmt.Errorf("failed to encode %s file metadata", MetadataKey)
 		}
@@ -1,9 +1,12 @@
 package geoparquet
 
 import (
+	"fmt"
 	"io"
+	"sync/atomic"
 
 	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/memory"
 	"github.com/apache/arrow/go/v16/parquet"
 	"github.com/apache/arrow/go/v16/parquet/pqarrow"
 )
@@ -14,4 +17,76 @@ type WriterConfig struct {
 	ParquetWriterProps *parquet.WriterProperties
 	ArrowWriterProps   *pqarrow.ArrowWriterProperties
 	ArrowSchema        *arrow.Schema
+
+	// GeometryTypes, if not nil, is written as the "geometry_types" for the
+	// primary column instead of the types found in the data.
+	GeometryTypes []string
+
+	// PartitionCellSize, if positive, groups features into row groups by the
+	// grid cell (of this size in coordinate units) that contains the center of
+	// their bounds.  Features are buffered until the writer is closed.
+	PartitionCellSize float64
+
+	// GeometryPrecision, if not nil, is the number of decimal places that
+	// coordinates are rounded to (zero rounds to integers).  Coordinates
+	// are rounded before they are encoded, and the bounds in the metadata are
+	// computed from the rounded coordinates.
+	GeometryPrecision *int
+
+	// BoundsPrecision, if not nil, is the number of decimal places that the
+	// "bbox" values in the metadata are rounded to.  Bounds are rounded outward
+	// so that they still contain all of the geometries.
+	BoundsPrecision *int
+
+	// MaxBufferedBytes, if positive, limits the memory used to buffer a row
+	// group.  The feature writer writes the buffered features when the Arrow
+	// builders use about this many bytes, and the record writer checks the
+	// encoded size of the row group after each record.  In both cases, the next
+	// write starts a new row group, even if the row group length has not been
+	// reached.  Partitioned features are not limited.
+	MaxBufferedBytes int64
+
+	// OmitMetadata skips writing the "geo" metadata, so the output is plain
+	// Parquet with WKB geometry columns (it will not validate as GeoParquet).
+	// The metadata is still used to encode the geometries.
+	OmitMetadata bool
+}
+
+// countingAllocator keeps track of the bytes currently allocated.
+type countingAllocator struct {
+	memory.Allocator
+	allocated atomic.Int64
+}
+
+func newCountingAllocator(allocator memory.Allocator) *countingAllocator {
+	return &countingAllocator{Allocator: allocator}
+}
+
+func (a *countingAllocator) Allocate(size int) []byte {
+	a.allocated.Add(int64(size))
+	return a.Allocator.Allocate(size)
+}
+
+func (a *countingAllocator) Reallocate(size int, b []byte) []byte {
+	a.allocated.Add(int64(size - len(b)))
+	return a.Allocator.Reallocate(size, b)
+}
+
+func (a *countingAllocator) Free(b []byte) {
+	a.allocated.Add(-int64(len(b)))
+	a.Allocator.Free(b)
+}
+
+// Allocated returns the number of bytes currently allocated.
+func (a *countingAllocator) Allocated() int64 {
+	return a.allocated.Load()
+}
+
+// ValidateMaxBufferedBytes returns an error if the buffered bytes limit is
+// negative.  Zero means no limit.
+func ValidateMaxBufferedBytes(size int64) error {
+	if size < 0 {
+		return fmt.Errorf("max buffered bytes must not be negative, got %d", size)
+	}
+	return nil
 }
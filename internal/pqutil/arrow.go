@@ -4,14 +4,39 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"sort"
 
 	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/planetlabs/gpq/internal/geo"
 )
 
+// JSONEncodingKey is the field metadata key used to mark string columns that
+// hold JSON-encoded values.
+const JSONEncodingKey = "gpq:encoding"
+
+const jsonEncoding = "json"
+
+// NewJSONField returns a string field marked as holding JSON-encoded values.
+func NewJSONField(name string, nullable bool) *arrow.Field {
+	return &arrow.Field{
+		Name:     name,
+		Type:     arrow.BinaryTypes.String,
+		Nullable: nullable,
+		Metadata: arrow.NewMetadata([]string{JSONEncodingKey}, []string{jsonEncoding}),
+	}
+}
+
+// IsJSONField reports whether the field holds JSON-encoded values.
+func IsJSONField(field arrow.Field) bool {
+	value, ok := field.Metadata.GetValue(JSONEncodingKey)
+	return ok && value == jsonEncoding && field.Type.ID() == arrow.STRING
+}
+
 type ArrowSchemaBuilder struct {
-	fields map[string]*arrow.Field
+	fields      map[string]*arrow.Field
+	jsonObjects bool
+	first       string
 }
 
 func NewArrowSchemaBuilder() *ArrowSchemaBuilder {
@@ -20,6 +45,18 @@ func NewArrowSchemaBuilder() *ArrowSchemaBuilder {
 	}
 }
 
+// EncodeObjectsAsJSON makes the builder use JSON-encoded string fields for
+// object values instead of structs.
+func (b *ArrowSchemaBuilder) EncodeObjectsAsJSON() {
+	b.jsonObjects = true
+}
+
+// PlaceFirst makes the named field the first field in the schema.  The other
+// fields are sorted by name.
+func (b *ArrowSchemaBuilder) PlaceFirst(name string) {
+	b.first = name
+}
+
 func (b *ArrowSchemaBuilder) Has(name string) bool {
 	_, has := b.fields[name]
 	return has
@@ -39,6 +76,11 @@ func (b *ArrowSchemaBuilder) AddGeometry(name string, encoding string) error {
 	return nil
 }
 
+// AddJSON adds a JSON-encoded string field.
+func (b *ArrowSchemaBuilder) AddJSON(name string) {
+	b.fields[name] = NewJSONField(name, true)
+}
+
 func (b *ArrowSchemaBuilder) Add(record map[string]any) error {
 	for name, value := range record {
 		if b.fields[name] != nil {
@@ -48,6 +90,10 @@ func (b *ArrowSchemaBuilder) Add(record map[string]any) error {
 			b.fields[name] = nil
 			continue
 		}
+		if _, ok := value.(map[string]any); ok && b.jsonObjects {
+			b.fields[name] = NewJSONField(name, true)
+			continue
+		}
 		if values, ok := value.([]any); ok {
 			if len(values) == 0 {
 				b.fields[name] = nil
@@ -164,9 +210,32 @@ func (b *ArrowSchemaBuilder) Ready() bool {
 	return true
 }
 
+// UseJSONForUnknown adds a JSON-encoded string field for each name that only had
+// values that the type could not be derived from (e.g. null or an empty list).
+// Any value can be written to these fields.  The names of the fields are
+// returned in sorted order.
+func (b *ArrowSchemaBuilder) UseJSONForUnknown() []string {
+	names := []string{}
+	for _, name := range sortedKeys(b.fields) {
+		if b.fields[name] == nil {
+			b.fields[name] = NewJSONField(name, true)
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Schema returns a schema with the fields sorted by name (except for a field
+// placed first with PlaceFirst).  Struct fields are also sorted by name.  This
+// makes the column order the same regardless of the order of the properties in
+// the input.
 func (b *ArrowSchemaBuilder) Schema() (*arrow.Schema, error) {
+	names := sortedKeys(b.fields)
+	if index := slices.Index(names, b.first); index > 0 {
+		names = slices.Insert(slices.Delete(names, index, index+1), 0, b.first)
+	}
 	fields := make([]arrow.Field, len(b.fields))
-	for i, name := range sortedKeys(b.fields) {
+	for i, name := range names {
 		field := b.fields[name]
 		if field == nil {
 			return nil, fmt.Errorf("could not derive type for field: %s", name)
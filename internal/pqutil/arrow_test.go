@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/planetlabs/gpq/internal/geo"
 	"github.com/planetlabs/gpq/internal/pqutil"
 	"github.com/planetlabs/gpq/internal/test"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -138,3 +140,48 @@ func TestBuilder(t *testing.T) {
 		})
 	}
 }
+
+func TestBuilderJSONObjects(t *testing.T) {
+	b := pqutil.NewArrowSchemaBuilder()
+	b.EncodeObjectsAsJSON()
+	require.NoError(t, b.Add(map[string]any{
+		"name":  "test",
+		"extra": map[string]any{"nested": []any{map[string]any{"deep": true}}},
+		"empty": map[string]any{},
+	}))
+	require.True(t, b.Ready())
+
+	s, err := b.Schema()
+	require.NoError(t, err)
+	test.AssertArrowSchemaMatches(t, `
+		message {
+			optional binary empty (STRING);
+			optional binary extra (STRING);
+			optional binary name (STRING);
+		}
+	`, s)
+
+	assert.True(t, pqutil.IsJSONField(s.Field(0)))
+	assert.True(t, pqutil.IsJSONField(s.Field(1)))
+	assert.False(t, pqutil.IsJSONField(s.Field(2)))
+}
+
+func TestBuilderPlaceFirst(t *testing.T) {
+	b := pqutil.NewArrowSchemaBuilder()
+	b.PlaceFirst("geometry")
+	require.NoError(t, b.Add(map[string]any{
+		"name":  "test",
+		"count": int64(3),
+	}))
+	require.NoError(t, b.AddGeometry("geometry", geo.EncodingWKB))
+
+	s, err := b.Schema()
+	require.NoError(t, err)
+	test.AssertArrowSchemaMatches(t, `
+		message {
+			optional binary geometry;
+			optional int64 count (INT (64, true));
+			optional binary name (STRING);
+		}
+	`, s)
+}
@@ -2,8 +2,14 @@ package pqutil
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/parquet"
 	"github.com/apache/arrow/go/v16/parquet/compress"
+	"github.com/apache/arrow/go/v16/parquet/pqarrow"
+	"github.com/apache/arrow/go/v16/parquet/schema"
 )
 
 func GetCompression(codec string) (compress.Compression, error) {
@@ -24,3 +30,60 @@ func GetCompression(codec string) (compress.Compression, error) {
 		return compress.Codecs.Uncompressed, fmt.Errorf("invalid compression codec %s", codec)
 	}
 }
+
+// GetColumnCompression parses the codec for each column in a map of column names
+// to codec names.
+func GetColumnCompression(codecs map[string]string) (map[string]compress.Compression, error) {
+	columnCompression := map[string]compress.Compression{}
+	for name, codec := range codecs {
+		compression, err := GetCompression(codec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid compression for column %q: %w", name, err)
+		}
+		columnCompression[name] = compression
+	}
+	return columnCompression, nil
+}
+
+// ColumnCompressionProperties returns writer properties that set the compression
+// for all leaf columns under each of the named columns.  Nested columns can be
+// named with a dotted path (e.g. "address.city").  An error is returned if the
+// schema has no column with one of the names.
+func ColumnCompressionProperties(sc *schema.Schema, codecs map[string]compress.Compression) ([]parquet.WriterProperty, error) {
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	properties := []parquet.WriterProperty{}
+	for _, name := range names {
+		found := false
+		for i := 0; i < sc.NumColumns(); i += 1 {
+			path := sc.Column(i).ColumnPath()
+			pathString := path.String()
+			if pathString != name && !strings.HasPrefix(pathString, name+".") {
+				continue
+			}
+			found = true
+			properties = append(properties, parquet.WithCompressionPath(path, codecs[name]))
+		}
+		if !found {
+			return nil, fmt.Errorf("cannot set the compression for %q, the schema has no column with that name", name)
+		}
+	}
+	return properties, nil
+}
+
+// ArrowColumnCompressionProperties is like ColumnCompressionProperties for the
+// Parquet schema that is written for an Arrow schema.
+func ArrowColumnCompressionProperties(arrowSchema *arrow.Schema, codecs map[string]compress.Compression) ([]parquet.WriterProperty, error) {
+	if len(codecs) == 0 {
+		return nil, nil
+	}
+	sc, err := pqarrow.ToParquet(arrowSchema, parquet.NewWriterProperties(), pqarrow.DefaultWriterProps())
+	if err != nil {
+		return nil, err
+	}
+	return ColumnCompressionProperties(sc, codecs)
+}
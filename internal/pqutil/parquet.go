@@ -40,6 +40,41 @@ func LookupGroupNode(schema *pqschema.Schema, name string) (*pqschema.GroupNode,
 	return group, ok
 }
 
+// LookupNestedNode follows a path of field names through (non-repeated) groups
+// and returns the node at the end of the path.
+func LookupNestedNode(schema *pqschema.Schema, path []string) (pqschema.Node, bool) {
+	var node pqschema.Node = schema.Root()
+	for i, name := range path {
+		group, ok := node.(*pqschema.GroupNode)
+		if !ok || (i > 0 && group.RepetitionType() == parquet.Repetitions.Repeated) {
+			return nil, false
+		}
+		index := group.FieldIndexByName(name)
+		if index < 0 {
+			return nil, false
+		}
+		node = group.Field(index)
+	}
+	return node, true
+}
+
+// RenameNode returns a copy of the node with a new name.  The children of a
+// group node are reused.
+func RenameNode(node pqschema.Node, name string) (pqschema.Node, error) {
+	switch n := node.(type) {
+	case *pqschema.PrimitiveNode:
+		return pqschema.NewPrimitiveNodeLogical(name, n.RepetitionType(), n.LogicalType(), n.PhysicalType(), n.TypeLength(), n.FieldID())
+	case *pqschema.GroupNode:
+		fields := make([]pqschema.Node, n.NumFields())
+		for i := range fields {
+			fields[i] = n.Field(i)
+		}
+		return pqschema.NewGroupNodeLogical(name, n.RepetitionType(), fields, n.LogicalType(), n.FieldID())
+	default:
+		return nil, fmt.Errorf("unsupported node type %T", node)
+	}
+}
+
 func LookupListElementNode(sc *pqschema.Schema, name string) (*pqschema.PrimitiveNode, bool) {
 	node, ok := LookupGroupNode(sc, name)
 	if !ok {
@@ -146,17 +181,7 @@ func LogicalOrConvertedAnnotation(node pqschema.Node) string {
 	case *pqschema.DecimalLogicalType:
 		return fmt.Sprintf(" (DECIMAL (%d, %d))", t.Precision(), t.Scale())
 	case *pqschema.TimestampLogicalType:
-		var unit string
-		switch t.TimeUnit() {
-		case pqschema.TimeUnitMillis:
-			unit = "MILLIS"
-		case pqschema.TimeUnitMicros:
-			unit = "MICROS"
-		case pqschema.TimeUnitNanos:
-			unit = "NANOS"
-		default:
-			unit = "UNKNOWN"
-		}
+		unit := strings.ToUpper(timeUnitName(t.TimeUnit()))
 		return fmt.Sprintf(" (TIMESTAMP (%s, %t))", unit, t.IsAdjustedToUTC())
 	}
 
@@ -173,6 +198,48 @@ func LogicalOrConvertedAnnotation(node pqschema.Node) string {
 	return annotation
 }
 
+// Annotation returns a compact lowercase name for the logical type of a node
+// (or its converted type for files without logical types).  Parameterized
+// types include their parameters (e.g. "decimal(10,2)" or
+// "timestamp(millis,true)").  An empty string is returned for nodes without an
+// annotation.
+func Annotation(node pqschema.Node) string {
+	logicalType := node.LogicalType()
+
+	switch t := logicalType.(type) {
+	case *pqschema.IntLogicalType:
+		return fmt.Sprintf("int(%d,%t)", t.BitWidth(), t.IsSigned())
+	case *pqschema.DecimalLogicalType:
+		return fmt.Sprintf("decimal(%d,%d)", t.Precision(), t.Scale())
+	case *pqschema.TimestampLogicalType:
+		return fmt.Sprintf("timestamp(%s,%t)", timeUnitName(t.TimeUnit()), t.IsAdjustedToUTC())
+	case *pqschema.TimeLogicalType:
+		return fmt.Sprintf("time(%s,%t)", timeUnitName(t.TimeUnit()), t.IsAdjustedToUTC())
+	}
+
+	_, invalid := logicalType.(pqschema.UnknownLogicalType)
+	if logicalType != nil && !invalid && !logicalType.IsNone() {
+		return strings.ToLower(logicalType.String())
+	}
+	if convertedType := node.ConvertedType(); convertedType != pqschema.ConvertedTypes.None {
+		return strings.ToLower(convertedType.String())
+	}
+	return ""
+}
+
+func timeUnitName(unit pqschema.TimeUnitType) string {
+	switch unit {
+	case pqschema.TimeUnitMillis:
+		return "millis"
+	case pqschema.TimeUnitMicros:
+		return "micros"
+	case pqschema.TimeUnitNanos:
+		return "nanos"
+	default:
+		return "unknown"
+	}
+}
+
 var physicalTypeLookup = map[string]string{
 	"byte_array": "binary",
 }
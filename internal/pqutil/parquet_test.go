@@ -5,7 +5,9 @@ import (
 	"testing"
 
 	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/parquet"
 	"github.com/apache/arrow/go/v16/parquet/pqarrow"
+	"github.com/apache/arrow/go/v16/parquet/schema"
 	"github.com/planetlabs/gpq/internal/pqutil"
 	"github.com/planetlabs/gpq/internal/test"
 	"github.com/stretchr/testify/assert"
@@ -130,3 +132,62 @@ func TestArrowToParquetString(t *testing.T) {
 		})
 	}
 }
+
+func TestLookupNestedNode(t *testing.T) {
+	arrowSchema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "feature", Type: arrow.StructOf(
+			arrow.Field{Name: "geom", Type: arrow.BinaryTypes.Binary, Nullable: true},
+			arrow.Field{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String), Nullable: true},
+		), Nullable: true},
+	}, nil)
+
+	parquetSchema, err := pqarrow.ToParquet(arrowSchema, nil, pqarrow.DefaultWriterProps())
+	require.NoError(t, err)
+
+	node, ok := pqutil.LookupNestedNode(parquetSchema, []string{"feature", "geom"})
+	require.True(t, ok)
+	assert.Equal(t, "geom", node.Name())
+
+	node, ok = pqutil.LookupNestedNode(parquetSchema, []string{"name"})
+	require.True(t, ok)
+	assert.Equal(t, "name", node.Name())
+
+	_, ok = pqutil.LookupNestedNode(parquetSchema, []string{"feature", "missing"})
+	assert.False(t, ok)
+
+	_, ok = pqutil.LookupNestedNode(parquetSchema, []string{"name", "geom"})
+	assert.False(t, ok)
+
+	_, ok = pqutil.LookupNestedNode(parquetSchema, []string{"feature", "tags", "list", "element"})
+	assert.False(t, ok)
+}
+
+func TestAnnotation(t *testing.T) {
+	newNode := func(logicalType schema.LogicalType, physicalType parquet.Type, length int32) schema.Node {
+		node, err := schema.NewPrimitiveNodeLogical("value", parquet.Repetitions.Optional, logicalType, physicalType, int(length), -1)
+		require.NoError(t, err)
+		return node
+	}
+
+	cases := []struct {
+		name     string
+		node     schema.Node
+		expected string
+	}{
+		{name: "decimal", node: newNode(schema.NewDecimalLogicalType(10, 2), parquet.Types.Int64, -1), expected: "decimal(10,2)"},
+		{name: "uuid", node: newNode(schema.UUIDLogicalType{}, parquet.Types.FixedLenByteArray, 16), expected: "uuid"},
+		{name: "enum", node: newNode(schema.EnumLogicalType{}, parquet.Types.ByteArray, -1), expected: "enum"},
+		{name: "string", node: newNode(schema.StringLogicalType{}, parquet.Types.ByteArray, -1), expected: "string"},
+		{name: "int", node: newNode(schema.NewIntLogicalType(8, false), parquet.Types.Int32, -1), expected: "int(8,false)"},
+		{name: "timestamp", node: newNode(schema.NewTimestampLogicalType(true, schema.TimeUnitMillis), parquet.Types.Int64, -1), expected: "timestamp(millis,true)"},
+		{name: "time", node: newNode(schema.NewTimeLogicalType(false, schema.TimeUnitMicros), parquet.Types.Int64, -1), expected: "time(micros,false)"},
+		{name: "none", node: newNode(schema.NoLogicalType{}, parquet.Types.Double, -1), expected: ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, pqutil.Annotation(c.node))
+		})
+	}
+}
@@ -1,16 +1,20 @@
 package pqutil
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/apache/arrow/go/v16/arrow/memory"
 	"github.com/apache/arrow/go/v16/parquet"
 	"github.com/apache/arrow/go/v16/parquet/compress"
 	"github.com/apache/arrow/go/v16/parquet/file"
+	"github.com/apache/arrow/go/v16/parquet/metadata"
 	"github.com/apache/arrow/go/v16/parquet/pqarrow"
 	"github.com/apache/arrow/go/v16/parquet/schema"
 )
@@ -19,6 +23,24 @@ type ColumnTransformer func(*arrow.Field, *arrow.Field, *arrow.Chunked) (*arrow.
 
 type SchemaTransformer func(*file.Reader) (*schema.Schema, error)
 
+// ComputedColumn is an output column that is not read from the input.  Its
+// values are computed from the output values of another column (after any
+// column transform).
+type ComputedColumn struct {
+	// Source is the index of the output column used to compute the values
+	// (not counting dropped columns).
+	Source int
+
+	// Compute is called with the output field for the computed column and the
+	// values of the source column for each row group.
+	Compute func(*arrow.Field, *arrow.Chunked) (*arrow.Chunked, error)
+}
+
+// MetadataWriter is used to append key/value metadata before the output is closed.
+type MetadataWriter interface {
+	AppendKeyValueMetadata(key string, value string) error
+}
+
 type TransformConfig struct {
 	Reader          parquet.ReaderAtSeeker
 	Writer          io.Writer
@@ -26,10 +48,60 @@ type TransformConfig struct {
 	RowGroupLength  int
 	TransformSchema SchemaTransformer
 	TransformColumn ColumnTransformer
-	BeforeClose     func(*file.Reader, *pqarrow.FileWriter) error
+	BeforeClose     func(*file.Reader, MetadataWriter) error
+
+	// DisableDictionary turns off dictionary encoding for all columns.
+	DisableDictionary bool
+
+	// DataPageSize, if positive, is the target size in bytes for data pages.
+	DataPageSize int64
+
+	// ColumnCompression overrides the compression for the named output columns.
+	ColumnCompression map[string]compress.Compression
+
+	// DropColumns lists the names of top-level input columns that are not read
+	// or written.  The schema from TransformSchema must not include them.
+	DropColumns []string
+
+	// ComputedColumns are written after the columns read from the input.  The
+	// schema from TransformSchema must end with a field for each.
+	ComputedColumns []*ComputedColumn
+
+	// Context, if not nil, stops the transform with the context error when it
+	// is done.  It is checked before each row group is written.
+	Context context.Context
+
+	// ReadAhead reads and transforms the next row group in a separate goroutine
+	// while the current one is written.  This overlaps IO and encoding at the
+	// cost of holding an extra row group in memory.  The output is the same
+	// either way.  It has no effect when RowGroupLength is set.  Column
+	// transforms are called from the read goroutine, so they must not share
+	// unsynchronized state with the computed columns.
+	ReadAhead bool
+
+	// CopyColumnChunks allows the column chunks to be copied from the input
+	// without decoding when no column, compression, or row group length changes
+	// are configured and TransformSchema (if set) returns an unchanged schema.
+	// In this case, only the footer is rewritten.
+	CopyColumnChunks bool
+}
+
+// canCopy returns true if the column chunks can be copied to the output
+// without decoding them.
+func (config *TransformConfig) canCopy(inputSchema *schema.Schema, outputSchema *schema.Schema) bool {
+	return config.CopyColumnChunks &&
+		outputSchema.Equals(inputSchema) &&
+		config.TransformColumn == nil &&
+		config.Compression == nil &&
+		config.RowGroupLength == 0 &&
+		!config.DisableDictionary &&
+		config.DataPageSize <= 0 &&
+		len(config.ColumnCompression) == 0 &&
+		len(config.DropColumns) == 0 &&
+		len(config.ComputedColumns) == 0
 }
 
-func getWriterProperties(config *TransformConfig, fileReader *file.Reader) (*parquet.WriterProperties, error) {
+func getWriterProperties(config *TransformConfig, fileReader *file.Reader, outputSchema *schema.Schema) (*parquet.WriterProperties, error) {
 	var writerProperties []parquet.WriterProperty
 	if config.Compression != nil {
 		writerProperties = append(writerProperties, parquet.WithCompression(*config.Compression))
@@ -55,6 +127,22 @@ func getWriterProperties(config *TransformConfig, fileReader *file.Reader) (*par
 		writerProperties = append(writerProperties, parquet.WithMaxRowGroupLength(int64(config.RowGroupLength)))
 	}
 
+	if config.DisableDictionary {
+		writerProperties = append(writerProperties, parquet.WithDictionaryDefault(false))
+	}
+
+	if config.DataPageSize > 0 {
+		writerProperties = append(writerProperties, parquet.WithDataPageSize(config.DataPageSize))
+	}
+
+	if len(config.ColumnCompression) > 0 {
+		columnProperties, err := ColumnCompressionProperties(outputSchema, config.ColumnCompression)
+		if err != nil {
+			return nil, err
+		}
+		writerProperties = append(writerProperties, columnProperties...)
+	}
+
 	return parquet.NewWriterProperties(writerProperties...), nil
 }
 
@@ -81,6 +169,10 @@ func TransformByColumn(config *TransformConfig) error {
 		outputSchema = schema
 	}
 
+	if config.canCopy(fileReader.MetaData().Schema, outputSchema) {
+		return copyColumnChunks(config, fileReader)
+	}
+
 	arrowReadProperties := pqarrow.ArrowReadProperties{}
 
 	arrowReader, arrowError := pqarrow.NewFileReader(fileReader, arrowReadProperties, memory.DefaultAllocator)
@@ -94,12 +186,20 @@ func TransformByColumn(config *TransformConfig) error {
 		return manifestErr
 	}
 
-	numFields := len(outputManifest.Fields)
-	if numFields != len(inputManifest.Fields) {
-		return fmt.Errorf("unexpected number of fields in the output schema, got %d, expected %d", numFields, len(inputManifest.Fields))
+	fieldNums, dropErr := keptFields(fileReader.MetaData().Schema.Root(), config.DropColumns)
+	if dropErr != nil {
+		return dropErr
 	}
-
-	writerProperties, propErr := getWriterProperties(config, fileReader)
+	numFields := len(fieldNums)
+	if len(outputManifest.Fields) != numFields+len(config.ComputedColumns) {
+		return fmt.Errorf("unexpected number of fields in the output schema, got %d, expected %d", len(outputManifest.Fields), numFields+len(config.ComputedColumns))
+	}
+	for _, computed := range config.ComputedColumns {
+		if computed.Source < 0 || computed.Source >= numFields {
+			return fmt.Errorf("computed column source %d is out of range", computed.Source)
+		}
+	}
+	writerProperties, propErr := getWriterProperties(config, fileReader, outputSchema)
 	if propErr != nil {
 		return propErr
 	}
@@ -109,37 +209,52 @@ func TransformByColumn(config *TransformConfig) error {
 		return arrowSchemaErr
 	}
 
-	fileWriter, fileWriterErr := pqarrow.NewFileWriter(arrowSchema, config.Writer, writerProperties, pqarrow.DefaultWriterProps())
+	arrowWriterProperties := pqarrow.DefaultWriterProps()
+	if schemaWithMetadata, ok := withFieldMetadata(arrowSchema, inputManifest, fieldNums); ok {
+		// the arrow schema must be stored for field metadata to be written
+		arrowSchema = schemaWithMetadata
+		arrowWriterProperties = pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema())
+	}
+
+	fileWriter, fileWriterErr := pqarrow.NewFileWriter(arrowSchema, config.Writer, writerProperties, arrowWriterProperties)
 	if fileWriterErr != nil {
 		return fileWriterErr
 	}
 
-	ctx := pqarrow.NewArrowWriteContext(context.Background(), nil)
+	baseCtx := config.Context
+	if baseCtx == nil {
+		baseCtx = context.Background()
+	}
+	ctx := pqarrow.NewArrowWriteContext(baseCtx, nil)
 
 	if config.RowGroupLength > 0 {
 		columnReaders := make([]*pqarrow.ColumnReader, numFields)
-		for fieldNum := 0; fieldNum < numFields; fieldNum += 1 {
+		for outputNum, fieldNum := range fieldNums {
 			colReader, err := arrowReader.GetColumn(ctx, fieldNum)
 			if err != nil {
 				return err
 			}
-			columnReaders[fieldNum] = colReader
+			columnReaders[outputNum] = colReader
 		}
 
 		numRows := fileReader.NumRows()
 		numRowsWritten := int64(0)
 		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			fileWriter.NewRowGroup()
 			numRowsInGroup := 0
-			for fieldNum := 0; fieldNum < numFields; fieldNum += 1 {
-				colReader := columnReaders[fieldNum]
+			written := make([]*arrow.Chunked, numFields)
+			for outputNum, fieldNum := range fieldNums {
+				colReader := columnReaders[outputNum]
 				arr, readErr := colReader.NextBatch(int64(config.RowGroupLength))
 				if readErr != nil {
 					return readErr
 				}
 				if config.TransformColumn != nil {
 					inputField := inputManifest.Fields[fieldNum].Field
-					outputField := outputManifest.Fields[fieldNum].Field
+					outputField := outputManifest.Fields[outputNum].Field
 					transformed, err := config.TransformColumn(inputField, outputField, arr)
 					if err != nil {
 						return err
@@ -156,6 +271,10 @@ func TransformByColumn(config *TransformConfig) error {
 				if err := fileWriter.WriteColumnChunked(arr, 0, int64(arr.Len())); err != nil {
 					return err
 				}
+				written[outputNum] = arr
+			}
+			if err := writeComputedColumns(config, fileWriter, outputManifest, written); err != nil {
+				return err
 			}
 			numRowsWritten += int64(numRowsInGroup)
 			if numRowsWritten >= numRows {
@@ -163,28 +282,61 @@ func TransformByColumn(config *TransformConfig) error {
 			}
 		}
 	} else {
-		numRowGroups := fileReader.NumRowGroups()
-		for rowGroupIndex := 0; rowGroupIndex < numRowGroups; rowGroupIndex += 1 {
+		readRowGroup := func(rowGroupIndex int) ([]*arrow.Chunked, error) {
 			rowGroupReader := arrowReader.RowGroup(rowGroupIndex)
-			fileWriter.NewRowGroup()
-			for fieldNum := 0; fieldNum < numFields; fieldNum += 1 {
+			columns := make([]*arrow.Chunked, numFields)
+			for outputNum, fieldNum := range fieldNums {
 				arr, readErr := rowGroupReader.Column(fieldNum).Read(ctx)
 				if readErr != nil {
-					return readErr
+					return nil, readErr
 				}
 				if config.TransformColumn != nil {
 					inputField := inputManifest.Fields[fieldNum].Field
-					outputField := outputManifest.Fields[fieldNum].Field
+					outputField := outputManifest.Fields[outputNum].Field
 					transformed, err := config.TransformColumn(inputField, outputField, arr)
 					if err != nil {
-						return err
+						return nil, err
 					}
 					arr = transformed
 				}
+				columns[outputNum] = arr
+			}
+			return columns, nil
+		}
+
+		numRowGroups := fileReader.NumRowGroups()
+		var next func() ([]*arrow.Chunked, error)
+		if config.ReadAhead {
+			// stop is deferred after closing the file reader, so it runs first
+			readAheadNext, stop := readAhead(ctx, numRowGroups, readRowGroup)
+			defer stop()
+			next = readAheadNext
+		} else {
+			rowGroupIndex := 0
+			next = func() ([]*arrow.Chunked, error) {
+				columns, err := readRowGroup(rowGroupIndex)
+				rowGroupIndex += 1
+				return columns, err
+			}
+		}
+
+		for rowGroupIndex := 0; rowGroupIndex < numRowGroups; rowGroupIndex += 1 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			written, readErr := next()
+			if readErr != nil {
+				return readErr
+			}
+			fileWriter.NewRowGroup()
+			for _, arr := range written {
 				if err := fileWriter.WriteColumnChunked(arr, 0, int64(arr.Len())); err != nil {
 					return err
 				}
 			}
+			if err := writeComputedColumns(config, fileWriter, outputManifest, written); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -195,3 +347,202 @@ func TransformByColumn(config *TransformConfig) error {
 	}
 	return fileWriter.Close()
 }
+
+// keptFields returns the indexes of the top-level input fields that are not
+// dropped.
+func keptFields(root *schema.GroupNode, drop []string) ([]int, error) {
+	dropped := map[int]bool{}
+	for _, name := range drop {
+		index := root.FieldIndexByName(name)
+		if index < 0 {
+			return nil, fmt.Errorf("cannot drop %q, the input has no column with that name", name)
+		}
+		dropped[index] = true
+	}
+	fieldNums := make([]int, 0, root.NumFields()-len(dropped))
+	for fieldNum := 0; fieldNum < root.NumFields(); fieldNum += 1 {
+		if !dropped[fieldNum] {
+			fieldNums = append(fieldNums, fieldNum)
+		}
+	}
+	return fieldNums, nil
+}
+
+type rowGroupResult struct {
+	columns []*arrow.Chunked
+	err     error
+}
+
+// readAhead calls read for each row group in a separate goroutine so that the
+// next row group is read while the current one is written.  The first returned
+// function yields the results in order.  At most one row group is buffered
+// ahead of the writer.  The second returned function stops the goroutine and
+// waits for it to return, so it must be called before the input is closed.
+func readAhead(ctx context.Context, numRowGroups int, read func(int) ([]*arrow.Chunked, error)) (func() ([]*arrow.Chunked, error), func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	results := make(chan *rowGroupResult, 1)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(results)
+		for rowGroupIndex := 0; rowGroupIndex < numRowGroups; rowGroupIndex += 1 {
+			columns, err := read(rowGroupIndex)
+			select {
+			case results <- &rowGroupResult{columns: columns, err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	next := func() ([]*arrow.Chunked, error) {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				return nil, errors.New("no more row groups to read")
+			}
+			return result.columns, result.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	stop := func() {
+		cancel()
+		wg.Wait()
+	}
+	return next, stop
+}
+
+// writeComputedColumns writes the computed columns for a row group given the
+// values written for the other columns.
+func writeComputedColumns(config *TransformConfig, fileWriter *pqarrow.FileWriter, outputManifest *pqarrow.SchemaManifest, written []*arrow.Chunked) error {
+	for i, computed := range config.ComputedColumns {
+		outputField := outputManifest.Fields[len(written)+i].Field
+		arr, err := computed.Compute(outputField, written[computed.Source])
+		if err != nil {
+			return err
+		}
+		if arr.DataType() != outputField.Type {
+			return fmt.Errorf("computed column generated an unexpected type, got %s, expected %s", arr.DataType().Name(), outputField.Type.Name())
+		}
+		if err := fileWriter.WriteColumnChunked(arr, 0, int64(arr.Len())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const fieldIdKey = "PARQUET:field_id"
+
+// withFieldMetadata copies metadata (e.g. units or descriptions) from the input
+// fields to the output schema fields.  The fieldNums are the indexes of the input
+// fields for each output field read from the input.  It returns false if there
+// is no field metadata to copy.
+func withFieldMetadata(arrowSchema *arrow.Schema, inputManifest *pqarrow.SchemaManifest, fieldNums []int) (*arrow.Schema, bool) {
+	fields := arrowSchema.Fields()
+	copied := false
+	for i, field := range fields {
+		if i >= len(fieldNums) {
+			// computed columns have no input metadata
+			continue
+		}
+		inputMetadata := inputManifest.Fields[fieldNums[i]].Field.Metadata
+		keys := []string{}
+		values := []string{}
+		for j, key := range field.Metadata.Keys() {
+			if inputMetadata.FindKey(key) < 0 {
+				keys = append(keys, key)
+				values = append(values, field.Metadata.Values()[j])
+			}
+		}
+		for j, key := range inputMetadata.Keys() {
+			if key != fieldIdKey {
+				copied = true
+			}
+			keys = append(keys, key)
+			values = append(values, inputMetadata.Values()[j])
+		}
+		fields[i].Metadata = arrow.NewMetadata(keys, values)
+	}
+	if !copied {
+		return arrowSchema, false
+	}
+	schemaMetadata := arrowSchema.Metadata()
+	return arrow.NewSchema(fields, &schemaMetadata), true
+}
+
+var parquetMagic = []byte("PAR1")
+
+// arrowSchemaKey is the key/value metadata key for the stored Arrow schema.
+const arrowSchemaKey = "ARROW:schema"
+
+type keyValueCollector struct {
+	kv metadata.KeyValueMetadata
+}
+
+func (c *keyValueCollector) AppendKeyValueMetadata(key string, value string) error {
+	return c.kv.Append(key, value)
+}
+
+// copyColumnChunks writes all of the bytes from the input up to the footer and then
+// writes a new footer with the same row groups and any appended key/value metadata.
+func copyColumnChunks(config *TransformConfig, fileReader *file.Reader) error {
+	fileMetadata := fileReader.MetaData()
+	if fileMetadata.IsSetEncryptionAlgorithm() || fileMetadata.FileDecryptor != nil {
+		return errors.New("cannot copy column chunks from an encrypted file")
+	}
+
+	size, seekErr := config.Reader.Seek(0, io.SeekEnd)
+	if seekErr != nil {
+		return fmt.Errorf("failed to determine input size: %w", seekErr)
+	}
+
+	tail := make([]byte, 8)
+	if _, err := config.Reader.ReadAt(tail, size-int64(len(tail))); err != nil {
+		return fmt.Errorf("failed to read footer length: %w", err)
+	}
+	if !bytes.Equal(tail[4:], parquetMagic) {
+		return errors.New("unexpected parquet footer")
+	}
+	footerOffset := size - int64(len(tail)) - int64(binary.LittleEndian.Uint32(tail))
+	if footerOffset < int64(len(parquetMagic)) {
+		return errors.New("invalid parquet footer length")
+	}
+
+	if _, err := io.Copy(config.Writer, io.NewSectionReader(config.Reader, 0, footerOffset)); err != nil {
+		return fmt.Errorf("failed to copy column chunks: %w", err)
+	}
+
+	// the stored Arrow schema is kept since the columns are unchanged
+	collector := &keyValueCollector{kv: metadata.NewKeyValueMetadata()}
+	if arrowSchema := fileMetadata.KeyValueMetadata().FindValue(arrowSchemaKey); arrowSchema != nil {
+		if err := collector.kv.Append(arrowSchemaKey, *arrowSchema); err != nil {
+			return err
+		}
+	}
+	if config.BeforeClose != nil {
+		if err := config.BeforeClose(fileReader, collector); err != nil {
+			return err
+		}
+	}
+
+	thriftMetadata := *fileMetadata.FileMetaData
+	thriftMetadata.KeyValueMetadata = collector.kv
+	footer, serializeErr := (&metadata.FileMetaData{FileMetaData: &thriftMetadata}).Serialize(context.Background())
+	if serializeErr != nil {
+		return fmt.Errorf("failed to serialize footer: %w", serializeErr)
+	}
+
+	binary.LittleEndian.PutUint32(tail, uint32(len(footer)))
+	copy(tail[4:], parquetMagic)
+	for _, data := range [][]byte{footer, tail} {
+		if _, err := config.Writer.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
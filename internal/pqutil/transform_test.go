@@ -3,11 +3,15 @@ package pqutil_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/apache/arrow/go/v16/arrow"
 	"github.com/apache/arrow/go/v16/arrow/array"
@@ -372,3 +376,471 @@ func TestTransformColumn(t *testing.T) {
 	outputAsJSON := test.ParquetToJSON(t, bytes.NewReader(output.Bytes()))
 	assert.JSONEq(t, expected, outputAsJSON)
 }
+
+func TestTransformCopyColumnChunks(t *testing.T) {
+	data := `[
+		{
+			"name": "Taylor",
+			"grades": ["A", "B", "C"]
+		},
+		{
+			"name": "Kai",
+			"grades": ["C", "B", "A"]
+		}
+	]`
+
+	inputData := test.ParquetFromJSON(t, data, parquet.NewWriterProperties(parquet.WithMaxRowGroupLength(1)))
+	output := &bytes.Buffer{}
+	config := &pqutil.TransformConfig{
+		Reader:           bytes.NewReader(inputData),
+		Writer:           output,
+		CopyColumnChunks: true,
+		BeforeClose: func(fileReader *file.Reader, writer pqutil.MetadataWriter) error {
+			return writer.AppendKeyValueMetadata("key", "value")
+		},
+	}
+	require.NoError(t, pqutil.TransformByColumn(config))
+
+	outputAsJSON := test.ParquetToJSON(t, bytes.NewReader(output.Bytes()))
+	assert.JSONEq(t, data, outputAsJSON)
+
+	inputReader, err := file.NewParquetReader(bytes.NewReader(inputData))
+	require.NoError(t, err)
+	defer inputReader.Close()
+
+	outputReader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer outputReader.Close()
+
+	assert.Equal(t, 2, outputReader.NumRowGroups())
+	assert.Equal(t, inputReader.MetaData().NumRows, outputReader.MetaData().NumRows)
+
+	// the column chunks are copied byte for byte
+	footerOffset := len(inputData) - inputReader.MetaData().Size() - 8
+	assert.Equal(t, inputData[:footerOffset], output.Bytes()[:footerOffset])
+
+	value := outputReader.MetaData().KeyValueMetadata().FindValue("key")
+	require.NotNil(t, value)
+	assert.Equal(t, "value", *value)
+}
+
+func TestTransformCopyColumnChunksIgnoredWithCompression(t *testing.T) {
+	data := `[
+		{
+			"number": 42
+		},
+		{
+			"number": 3.14
+		}
+	]`
+
+	output := &bytes.Buffer{}
+	config := &pqutil.TransformConfig{
+		Reader:           bytes.NewReader(test.ParquetFromJSON(t, data, nil)),
+		Writer:           output,
+		Compression:      &compress.Codecs.Snappy,
+		CopyColumnChunks: true,
+	}
+	require.NoError(t, pqutil.TransformByColumn(config))
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer fileReader.Close()
+
+	columnChunk, err := fileReader.RowGroup(0).MetaData().ColumnChunk(0)
+	require.NoError(t, err)
+	assert.Equal(t, compress.Codecs.Snappy, columnChunk.Compression())
+}
+
+func TestTransformDisableDictionary(t *testing.T) {
+	data := `[
+		{
+			"name": "Taylor"
+		},
+		{
+			"name": "Taylor"
+		}
+	]`
+
+	hasDictionary := func(t *testing.T, config *pqutil.TransformConfig) bool {
+		output := &bytes.Buffer{}
+		config.Reader = bytes.NewReader(test.ParquetFromJSON(t, data, nil))
+		config.Writer = output
+		require.NoError(t, pqutil.TransformByColumn(config))
+
+		fileReader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+		require.NoError(t, err)
+		defer fileReader.Close()
+
+		columnChunk, err := fileReader.RowGroup(0).MetaData().ColumnChunk(0)
+		require.NoError(t, err)
+		return columnChunk.HasDictionaryPage()
+	}
+
+	assert.True(t, hasDictionary(t, &pqutil.TransformConfig{DataPageSize: 1024}))
+	assert.False(t, hasDictionary(t, &pqutil.TransformConfig{DisableDictionary: true, CopyColumnChunks: true}))
+}
+
+func TestTransformColumnCompression(t *testing.T) {
+	data := `[
+		{
+			"name": "Taylor",
+			"address": {"city": "Boulder", "zip": "80301"},
+			"age": 42
+		}
+	]`
+
+	output := &bytes.Buffer{}
+	config := &pqutil.TransformConfig{
+		Reader:      bytes.NewReader(test.ParquetFromJSON(t, data, nil)),
+		Writer:      output,
+		Compression: &compress.Codecs.Snappy,
+		ColumnCompression: map[string]compress.Compression{
+			"address": compress.Codecs.Zstd,
+			"age":     compress.Codecs.Gzip,
+		},
+		CopyColumnChunks: true,
+	}
+	require.NoError(t, pqutil.TransformByColumn(config))
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer fileReader.Close()
+
+	codecs := map[string]compress.Compression{}
+	rowGroup := fileReader.RowGroup(0).MetaData()
+	for i := 0; i < rowGroup.NumColumns(); i += 1 {
+		columnChunk, err := rowGroup.ColumnChunk(i)
+		require.NoError(t, err)
+		codecs[columnChunk.PathInSchema().String()] = columnChunk.Compression()
+	}
+
+	assert.Equal(t, map[string]compress.Compression{
+		"name":         compress.Codecs.Snappy,
+		"address.city": compress.Codecs.Zstd,
+		"address.zip":  compress.Codecs.Zstd,
+		"age":          compress.Codecs.Gzip,
+	}, codecs)
+}
+
+func TestTransformColumnCompressionMissingColumn(t *testing.T) {
+	data := `[{"name": "Taylor"}]`
+
+	config := &pqutil.TransformConfig{
+		Reader: bytes.NewReader(test.ParquetFromJSON(t, data, nil)),
+		Writer: &bytes.Buffer{},
+		ColumnCompression: map[string]compress.Compression{
+			"nam": compress.Codecs.Zstd,
+		},
+	}
+	err := pqutil.TransformByColumn(config)
+	assert.EqualError(t, err, `cannot set the compression for "nam", the schema has no column with that name`)
+}
+
+func TestTransformPreservesFieldMetadata(t *testing.T) {
+	arrowSchema := arrow.NewSchema([]arrow.Field{
+		{
+			Name:     "height",
+			Type:     arrow.PrimitiveTypes.Float64,
+			Nullable: true,
+			Metadata: arrow.NewMetadata([]string{"units", "description"}, []string{"meters", "height above ground"}),
+		},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, arrowSchema)
+	defer builder.Release()
+	builder.Field(0).(*array.Float64Builder).Append(1.5)
+	builder.Field(1).(*array.StringBuilder).Append("tree")
+	record := builder.NewRecord()
+	defer record.Release()
+
+	input := &bytes.Buffer{}
+	arrowProps := pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema())
+	writer, err := pqarrow.NewFileWriter(arrowSchema, input, parquet.NewWriterProperties(), arrowProps)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(record))
+	require.NoError(t, writer.Close())
+
+	output := &bytes.Buffer{}
+	config := &pqutil.TransformConfig{
+		Reader: bytes.NewReader(input.Bytes()),
+		Writer: output,
+	}
+	require.NoError(t, pqutil.TransformByColumn(config))
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+	require.NoError(t, err)
+	defer fileReader.Close()
+
+	arrowReader, err := pqarrow.NewFileReader(fileReader, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	require.NoError(t, err)
+	outputSchema, err := arrowReader.Schema()
+	require.NoError(t, err)
+
+	height := outputSchema.Field(0)
+	units, ok := height.Metadata.GetValue("units")
+	assert.True(t, ok)
+	assert.Equal(t, "meters", units)
+	description, ok := height.Metadata.GetValue("description")
+	assert.True(t, ok)
+	assert.Equal(t, "height above ground", description)
+
+	name := outputSchema.Field(1)
+	_, ok = name.Metadata.GetValue("units")
+	assert.False(t, ok)
+}
+
+func TestTransformComputedColumns(t *testing.T) {
+	data := `[
+		{
+			"product": "soup"
+		},
+		{
+			"product": "airplane"
+		}
+	]`
+
+	expected := `[
+		{
+			"product": "soup",
+			"length": 4
+		},
+		{
+			"product": "airplane",
+			"length": 8
+		}
+	]`
+
+	transformSchema := func(fileReader *file.Reader) (*schema.Schema, error) {
+		inputRoot := fileReader.MetaData().Schema.Root()
+		fields := []schema.Node{inputRoot.Field(0)}
+		length, err := schema.NewPrimitiveNode("length", parquet.Repetitions.Optional, parquet.Types.Int64, -1, -1)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, length)
+		outputRoot, err := schema.NewGroupNode(inputRoot.Name(), inputRoot.RepetitionType(), fields, -1)
+		if err != nil {
+			return nil, err
+		}
+		return schema.NewSchema(outputRoot), nil
+	}
+
+	computeLength := func(outputField *arrow.Field, chunked *arrow.Chunked) (*arrow.Chunked, error) {
+		chunks := chunked.Chunks()
+		computed := make([]arrow.Array, len(chunks))
+		builder := array.NewInt64Builder(memory.DefaultAllocator)
+		defer builder.Release()
+		for i, arr := range chunks {
+			stringArray, ok := arr.(*array.String)
+			if !ok {
+				return nil, fmt.Errorf("expected a string array, got %v", arr)
+			}
+			for rowNum := 0; rowNum < stringArray.Len(); rowNum += 1 {
+				builder.Append(int64(len(stringArray.Value(rowNum))))
+			}
+			computed[i] = builder.NewArray()
+		}
+		return arrow.NewChunked(builder.Type(), computed), nil
+	}
+
+	for _, rowGroupLength := range []int{0, 1} {
+		t.Run(fmt.Sprintf("row group length %d", rowGroupLength), func(t *testing.T) {
+			input := bytes.NewReader(test.ParquetFromJSON(t, data, nil))
+			output := &bytes.Buffer{}
+			config := &pqutil.TransformConfig{
+				Reader:          input,
+				Writer:          output,
+				RowGroupLength:  rowGroupLength,
+				TransformSchema: transformSchema,
+				ComputedColumns: []*pqutil.ComputedColumn{{Source: 0, Compute: computeLength}},
+			}
+			require.NoError(t, pqutil.TransformByColumn(config))
+
+			outputAsJSON := test.ParquetToJSON(t, bytes.NewReader(output.Bytes()))
+			assert.JSONEq(t, expected, outputAsJSON)
+		})
+	}
+}
+
+func TestTransformComputedColumnsMissingField(t *testing.T) {
+	input := bytes.NewReader(test.ParquetFromJSON(t, `[{"product": "soup"}]`, nil))
+	config := &pqutil.TransformConfig{
+		Reader: input,
+		Writer: &bytes.Buffer{},
+		ComputedColumns: []*pqutil.ComputedColumn{{
+			Compute: func(*arrow.Field, *arrow.Chunked) (*arrow.Chunked, error) { return nil, nil },
+		}},
+	}
+	assert.ErrorContains(t, pqutil.TransformByColumn(config), "unexpected number of fields in the output schema")
+}
+
+func TestTransformDropColumns(t *testing.T) {
+	data := `[
+		{"product": "soup", "cost": 1.29, "secret": "a"},
+		{"product": "747", "cost": 100000000, "secret": "b"},
+		{"product": "tea", "cost": 2.5, "secret": "c"}
+	]`
+
+	expected := `[
+		{"product": "soup", "cost": 1.29},
+		{"product": "747", "cost": 100000000},
+		{"product": "tea", "cost": 2.5}
+	]`
+
+	transformSchema := func(fileReader *file.Reader) (*schema.Schema, error) {
+		inputRoot := fileReader.MetaData().Schema.Root()
+		fields := []schema.Node{}
+		for fieldNum := 0; fieldNum < inputRoot.NumFields(); fieldNum += 1 {
+			if inputRoot.Field(fieldNum).Name() != "secret" {
+				fields = append(fields, inputRoot.Field(fieldNum))
+			}
+		}
+		outputRoot, err := schema.NewGroupNode(inputRoot.Name(), inputRoot.RepetitionType(), fields, -1)
+		if err != nil {
+			return nil, err
+		}
+		return schema.NewSchema(outputRoot), nil
+	}
+
+	for _, rowGroupLength := range []int{0, 2} {
+		t.Run(fmt.Sprintf("row group length %d", rowGroupLength), func(t *testing.T) {
+			input := bytes.NewReader(test.ParquetFromJSON(t, data, nil))
+			output := &bytes.Buffer{}
+			config := &pqutil.TransformConfig{
+				Reader:          input,
+				Writer:          output,
+				TransformSchema: transformSchema,
+				DropColumns:     []string{"secret"},
+				RowGroupLength:  rowGroupLength,
+			}
+			require.NoError(t, pqutil.TransformByColumn(config))
+
+			outputAsJSON := test.ParquetToJSON(t, bytes.NewReader(output.Bytes()))
+			assert.JSONEq(t, expected, outputAsJSON)
+		})
+	}
+}
+
+func TestTransformDropColumnsMissingColumn(t *testing.T) {
+	input := bytes.NewReader(test.ParquetFromJSON(t, `[{"product": "soup"}]`, nil))
+	config := &pqutil.TransformConfig{
+		Reader:      input,
+		Writer:      &bytes.Buffer{},
+		DropColumns: []string{"secret"},
+	}
+	assert.ErrorContains(t, pqutil.TransformByColumn(config), `cannot drop "secret"`)
+}
+
+func makeRowGroups(t testing.TB, numRows int, rowGroupLength int) []byte {
+	rows := make([]map[string]any, numRows)
+	for i := 0; i < numRows; i += 1 {
+		rows[i] = map[string]any{"num": i, "name": fmt.Sprintf("row %d", i)}
+	}
+	data, err := json.Marshal(rows)
+	require.NoError(t, err)
+
+	writerProperties := parquet.NewWriterProperties(parquet.WithMaxRowGroupLength(int64(rowGroupLength)))
+	return test.ParquetFromJSON(t, string(data), writerProperties)
+}
+
+func TestTransformReadAhead(t *testing.T) {
+	inputData := makeRowGroups(t, 100, 7)
+
+	transform := func(readAhead bool) []byte {
+		output := &bytes.Buffer{}
+		config := &pqutil.TransformConfig{
+			Reader:      bytes.NewReader(inputData),
+			Writer:      output,
+			Compression: &compress.Codecs.Snappy,
+			ReadAhead:   readAhead,
+		}
+		require.NoError(t, pqutil.TransformByColumn(config))
+		return output.Bytes()
+	}
+
+	expected := transform(false)
+	actual := transform(true)
+	assert.Equal(t, expected, actual)
+
+	fileReader, err := file.NewParquetReader(bytes.NewReader(actual))
+	require.NoError(t, err)
+	defer fileReader.Close()
+	assert.Equal(t, 15, fileReader.NumRowGroups())
+}
+
+func TestTransformReadAheadError(t *testing.T) {
+	inputData := makeRowGroups(t, 100, 7)
+
+	calls := 0
+	config := &pqutil.TransformConfig{
+		Reader:    bytes.NewReader(inputData),
+		Writer:    &bytes.Buffer{},
+		ReadAhead: true,
+		TransformColumn: func(inputField *arrow.Field, outputField *arrow.Field, chunked *arrow.Chunked) (*arrow.Chunked, error) {
+			calls += 1
+			if calls > 5 {
+				return nil, fmt.Errorf("failed on call %d", calls)
+			}
+			return chunked, nil
+		},
+	}
+	assert.EqualError(t, pqutil.TransformByColumn(config), "failed on call 6")
+}
+
+type failingWriter struct {
+	remaining int
+}
+
+func (w *failingWriter) Write(data []byte) (int, error) {
+	if len(data) > w.remaining {
+		return 0, errors.New("write failed")
+	}
+	w.remaining -= len(data)
+	return len(data), nil
+}
+
+func TestTransformReadAheadWriteError(t *testing.T) {
+	inputData := makeRowGroups(t, 100, 7)
+
+	var returned atomic.Bool
+	var readAfterReturn atomic.Bool
+	config := &pqutil.TransformConfig{
+		Reader:    bytes.NewReader(inputData),
+		Writer:    &failingWriter{remaining: 500},
+		ReadAhead: true,
+		TransformColumn: func(inputField *arrow.Field, outputField *arrow.Field, chunked *arrow.Chunked) (*arrow.Chunked, error) {
+			time.Sleep(time.Millisecond)
+			if returned.Load() {
+				readAfterReturn.Store(true)
+			}
+			return chunked, nil
+		},
+	}
+	assert.ErrorContains(t, pqutil.TransformByColumn(config), "write failed")
+	returned.Store(true)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.False(t, readAfterReturn.Load(), "the read ahead goroutine should be stopped before returning")
+}
+
+func BenchmarkTransformByColumn(b *testing.B) {
+	inputData := makeRowGroups(b, 200000, 10000)
+
+	for _, readAhead := range []bool{false, true} {
+		b.Run(fmt.Sprintf("read ahead %t", readAhead), func(b *testing.B) {
+			for i := 0; i < b.N; i += 1 {
+				config := &pqutil.TransformConfig{
+					Reader:      bytes.NewReader(inputData),
+					Writer:      io.Discard,
+					Compression: &compress.Codecs.Zstd,
+					ReadAhead:   readAhead,
+				}
+				if err := pqutil.TransformByColumn(config); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
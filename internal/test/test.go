@@ -21,7 +21,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func ParquetFromJSON(t *testing.T, data string, writerProperties *parquet.WriterProperties) []byte {
+func ParquetFromJSON(t testing.TB, data string, writerProperties *parquet.WriterProperties) []byte {
 	if writerProperties == nil {
 		writerProperties = parquet.NewWriterProperties()
 	}
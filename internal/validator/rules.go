@@ -18,6 +18,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/apache/arrow/go/v16/parquet"
 	"github.com/apache/arrow/go/v16/parquet/file"
@@ -35,6 +39,10 @@ type ColumnMetdataMap map[string]map[string]any
 type FileInfo struct {
 	File     *file.Reader
 	Metadata *geoparquet.Metadata
+
+	// Row is the index of the row with the value being checked by column value
+	// rules.
+	Row int64
 }
 
 type RuleData interface {
@@ -42,6 +50,8 @@ type RuleData interface {
 }
 
 type Rule interface {
+	// ID is a stable identifier for the rule (e.g. "GeometryBounds").
+	ID() string
 	Title() string
 	Validate() error
 }
@@ -63,7 +73,26 @@ func fatal(format string, a ...any) errFatal {
 	return errFatal(fmt.Sprintf(format, a...))
 }
 
+type errNotice string
+
+// ErrNotice is matched by errors from rules that pass with a message.
+var ErrNotice = errNotice("notice")
+
+func (e errNotice) Error() string {
+	return string(e)
+}
+
+func (e errNotice) Is(target error) bool {
+	_, ok := target.(errNotice)
+	return ok
+}
+
+func notice(format string, a ...any) errNotice {
+	return errNotice(fmt.Sprintf(format, a...))
+}
+
 type GenericRule[T RuleData] struct {
+	id       string
 	title    string
 	value    T
 	validate func(T) error
@@ -71,6 +100,10 @@ type GenericRule[T RuleData] struct {
 
 var _ Rule = (*GenericRule[*file.Reader])(nil)
 
+func (r *GenericRule[T]) ID() string {
+	return r.id
+}
+
 func (r *GenericRule[T]) Title() string {
 	return r.title
 }
@@ -84,6 +117,7 @@ func (r *GenericRule[T]) Validate() error {
 }
 
 type ColumnValueRule[T any] struct {
+	id    string
 	title string
 	value func(*FileInfo, string, T) error
 	info  *FileInfo
@@ -92,6 +126,10 @@ type ColumnValueRule[T any] struct {
 
 var _ Rule = (*ColumnValueRule[*string])(nil)
 
+func (r *ColumnValueRule[T]) ID() string {
+	return r.id
+}
+
 func (r *ColumnValueRule[T]) Title() string {
 	return r.title
 }
@@ -103,10 +141,25 @@ func (r *ColumnValueRule[T]) Init(info *FileInfo) {
 func (r *ColumnValueRule[T]) Value(name string, data T) error {
 	if r.err == nil {
 		r.err = r.value(r.info, name, data)
+	} else if errors.Is(r.err, ErrNotice) {
+		// keep checking so that a later failure is not hidden by a notice
+		if err := r.value(r.info, name, data); err != nil && !errors.Is(err, ErrNotice) {
+			r.err = err
+		}
 	}
 	return r.err
 }
 
+// Collect checks a value even if an earlier value failed.  The first error is
+// still returned by Validate, except that a failure replaces a notice.
+func (r *ColumnValueRule[T]) Collect(name string, data T) error {
+	err := r.value(r.info, name, data)
+	if r.err == nil || (err != nil && errors.Is(r.err, ErrNotice) && !errors.Is(err, ErrNotice)) {
+		r.err = err
+	}
+	return err
+}
+
 func (r *ColumnValueRule[T]) Validate() error {
 	return r.err
 }
@@ -137,12 +190,25 @@ func jsonType(value any) string {
 
 func RequiredGeoKey() Rule {
 	return &GenericRule[*file.Reader]{
+		id:    "RequiredGeoKey",
 		title: fmt.Sprintf("file must include a %q metadata key", geoparquet.MetadataKey),
 		validate: func(file *file.Reader) error {
 			kv := file.MetaData().KeyValueMetadata()
 			if kv.FindValue(geoparquet.MetadataKey) == nil {
 				return fatal("missing %q metadata key", geoparquet.MetadataKey)
 			}
+			count := 0
+			for _, key := range kv.Keys() {
+				if key == geoparquet.MetadataKey {
+					count += 1
+				}
+			}
+			if count > 1 {
+				return fatal(
+					"found %d %q metadata keys, expected exactly one (a tool may have appended new metadata without replacing the existing key); rewrite the file with a single %q key",
+					count, geoparquet.MetadataKey, geoparquet.MetadataKey,
+				)
+			}
 			return nil
 		},
 	}
@@ -150,6 +216,7 @@ func RequiredGeoKey() Rule {
 
 func RequiredMetadataType() Rule {
 	return &GenericRule[*file.Reader]{
+		id:    "RequiredMetadataType",
 		title: "metadata must be a JSON object",
 		validate: func(file *file.Reader) error {
 			value, geoErr := geoparquet.GetMetadataValue(file.MetaData().KeyValueMetadata())
@@ -169,6 +236,7 @@ func RequiredMetadataType() Rule {
 
 func RequiredVersion() Rule {
 	return &GenericRule[MetadataMap]{
+		id:    "RequiredVersion",
 		title: `metadata must include a "version" string`,
 		validate: func(metadata MetadataMap) error {
 			value, ok := metadata["version"]
@@ -189,6 +257,7 @@ func RequiredVersion() Rule {
 
 func RequiredPrimaryColumn() Rule {
 	return &GenericRule[MetadataMap]{
+		id:    "RequiredPrimaryColumn",
 		title: `metadata must include a "primary_column" string`,
 		validate: func(metadata MetadataMap) error {
 			name, ok := metadata["primary_column"]
@@ -206,6 +275,7 @@ func RequiredPrimaryColumn() Rule {
 
 func RequiredColumns() Rule {
 	return &GenericRule[MetadataMap]{
+		id:    "RequiredColumns",
 		title: `metadata must include a "columns" object`,
 		validate: func(metadata MetadataMap) error {
 			columnsAny, ok := metadata["columns"]
@@ -229,6 +299,7 @@ func RequiredColumns() Rule {
 
 func RequiredColumnEncoding() Rule {
 	return &GenericRule[ColumnMetdataMap]{
+		id:    "RequiredColumnEncoding",
 		title: `column metadata must include a valid "encoding" string`,
 		validate: func(columnMetadata ColumnMetdataMap) error {
 			for name, meta := range columnMetadata {
@@ -260,6 +331,7 @@ func isValidGeometryType(geometryType string) bool {
 
 func RequiredGeometryTypes() Rule {
 	return &GenericRule[ColumnMetdataMap]{
+		id:    "RequiredGeometryTypes",
 		title: `column metadata must include a "geometry_types" list`,
 		validate: func(columnMetadata ColumnMetdataMap) error {
 			for name, meta := range columnMetadata {
@@ -290,6 +362,38 @@ func projJSONSchemaUrl(version string) string {
 	return fmt.Sprintf("https://proj.org/schemas/v%s/projjson.schema.json", version)
 }
 
+type schemaCache struct {
+	mutex   sync.Mutex
+	schemas map[string]*jsonschema.Schema
+}
+
+// projJSONSchemas caches compiled PROJJSON schemas by URL so they are only
+// fetched and compiled once per process.
+var projJSONSchemas = &schemaCache{schemas: map[string]*jsonschema.Schema{}}
+
+// reset removes all of the cached schemas.
+func (c *schemaCache) reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.schemas = map[string]*jsonschema.Schema{}
+}
+
+func (c *schemaCache) get(schemaUrl string) (*jsonschema.Schema, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if schema, ok := c.schemas[schemaUrl]; ok {
+		return schema, nil
+	}
+	schema, err := jsonschema.NewCompiler().Compile(schemaUrl)
+	if err != nil {
+		return nil, err
+	}
+	c.schemas[schemaUrl] = schema
+	return schema, nil
+}
+
 func simplifiedValidationMessage(err *jsonschema.ValidationError) string {
 	leaf := err
 	for len(leaf.Causes) > 0 {
@@ -302,24 +406,60 @@ func simplifiedValidationMessage(err *jsonschema.ValidationError) string {
 	return fmt.Sprintf("%s is invalid: %s", location, leaf.Message)
 }
 
-func OptionalCRS() Rule {
+var (
+	crsAuthorityCode = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*:[A-Za-z0-9_.-]+$`)
+	crsWKT           = regexp.MustCompile(`(?s)^[A-Z][A-Z0-9_]*\[.*\]$`)
+)
+
+// isCRSString checks that a CRS given as a string looks like a WKT
+// definition or an "authority:code" identifier (e.g. "EPSG:4326").
+func isCRSString(crs string) bool {
+	crs = strings.TrimSpace(crs)
+	return crsAuthorityCode.MatchString(crs) || crsWKT.MatchString(crs)
+}
+
+// OptionalCRS validates any "crs" metadata against the PROJJSON schema.  If
+// schemaOverride is not empty, it is used as the schema path or URL instead
+// of the one referenced by the CRS.  If skipSchema is true, the CRS is only
+// checked for an object with a "type" and the schema is not fetched.  A CRS
+// given as a WKT or "authority:code" string passes with a notice.
+func OptionalCRS(schemaOverride string, skipSchema bool) Rule {
 	return &GenericRule[ColumnMetdataMap]{
+		id:    "OptionalCRS",
 		title: `optional "crs" must be null or a PROJJSON object`,
 		validate: func(columnMetadata ColumnMetdataMap) error {
+			skipped := false
+			stringColumns := []string{}
 			for name, meta := range columnMetadata {
 				if meta["crs"] == nil {
-					return nil
+					continue
+				}
+				if value, ok := meta["crs"].(string); ok {
+					if !isCRSString(value) {
+						return fmt.Errorf(`expected "crs" for column %q to be a PROJJSON object, a WKT string, or an "authority:code" string, got %s`, name, asJSON(value))
+					}
+					stringColumns = append(stringColumns, name)
+					continue
 				}
 				crs, ok := meta["crs"].(map[string]any)
 				if !ok {
 					return fatal(`expected "crs" for column %q to be an object, got a %s: %s`, name, jsonType(meta["crs"]), asJSON(meta["crs"]))
 				}
+				if skipSchema {
+					if _, ok := crs["type"].(string); !ok {
+						return fmt.Errorf(`expected "crs" for column %q to have a "type" string, got %s`, name, asJSON(crs["type"]))
+					}
+					skipped = true
+					continue
+				}
 				schemaUrl, ok := crs["$schema"].(string)
 				if !ok {
 					schemaUrl = projJSONSchemaUrl("0.6")
 				}
-				compiler := jsonschema.NewCompiler()
-				schema, schemaErr := compiler.Compile(schemaUrl)
+				if schemaOverride != "" {
+					schemaUrl = schemaOverride
+				}
+				schema, schemaErr := projJSONSchemas.get(schemaUrl)
 				if schemaErr != nil {
 					return fmt.Errorf("failed to compile PROJJSON schema: %w", schemaErr)
 				}
@@ -333,6 +473,17 @@ func OptionalCRS() Rule {
 				}
 				return fmt.Errorf("validation failed against %s: %s", schemaUrl, simplifiedValidationMessage(validationErr))
 			}
+			if len(stringColumns) > 0 {
+				slices.Sort(stringColumns)
+				quoted := make([]string, len(stringColumns))
+				for i, name := range stringColumns {
+					quoted[i] = fmt.Sprintf("%q", name)
+				}
+				return notice(`"crs" for column %s is a string, PROJJSON is preferred`, strings.Join(quoted, ", "))
+			}
+			if skipped {
+				return notice("PROJJSON schema validation skipped (no network)")
+			}
 			return nil
 		},
 	}
@@ -340,6 +491,7 @@ func OptionalCRS() Rule {
 
 func OptionalOrientation() Rule {
 	return &GenericRule[ColumnMetdataMap]{
+		id:    "OptionalOrientation",
 		title: `optional "orientation" must be a valid string`,
 		validate: func(columnMetadata ColumnMetdataMap) error {
 			for name, meta := range columnMetadata {
@@ -362,6 +514,7 @@ func OptionalOrientation() Rule {
 
 func OptionalEdges() Rule {
 	return &GenericRule[ColumnMetdataMap]{
+		id:    "OptionalEdges",
 		title: `optional "edges" must be a valid string`,
 		validate: func(columnMetadata ColumnMetdataMap) error {
 			for name, meta := range columnMetadata {
@@ -384,6 +537,7 @@ func OptionalEdges() Rule {
 
 func OptionalBbox() Rule {
 	return &GenericRule[ColumnMetdataMap]{
+		id:    "OptionalBbox",
 		title: `optional "bbox" must be an array of 4 or 6 numbers`,
 		validate: func(columnMetadata ColumnMetdataMap) error {
 			for name, meta := range columnMetadata {
@@ -412,6 +566,7 @@ func OptionalBbox() Rule {
 
 func OptionalEpoch() Rule {
 	return &GenericRule[ColumnMetdataMap]{
+		id:    "OptionalEpoch",
 		title: `optional "epoch" must be a number`,
 		validate: func(columnMetadata ColumnMetdataMap) error {
 			for name, meta := range columnMetadata {
@@ -431,6 +586,7 @@ func OptionalEpoch() Rule {
 
 func PrimaryColumnInLookup() Rule {
 	return &GenericRule[*FileInfo]{
+		id:    "PrimaryColumnInLookup",
 		title: `column metadata must include the "primary_column" name`,
 		validate: func(info *FileInfo) error {
 			name := info.Metadata.PrimaryColumn
@@ -445,6 +601,7 @@ func PrimaryColumnInLookup() Rule {
 
 func GeometryUngrouped() Rule {
 	return &GenericRule[*FileInfo]{
+		id:    "GeometryUngrouped",
 		title: "geometry columns must not be grouped",
 		validate: func(info *FileInfo) error {
 			metadata := info.Metadata
@@ -467,11 +624,12 @@ func GeometryUngrouped() Rule {
 
 func GeometryDataType() Rule {
 	return &GenericRule[*FileInfo]{
-		title: "geometry columns must be stored using the BYTE_ARRAY parquet type",
+		id:    "GeometryDataType",
+		title: "geometry columns must be stored using the BYTE_ARRAY parquet type (or FIXED_LEN_BYTE_ARRAY for WKB)",
 		validate: func(info *FileInfo) error {
 			metadata := info.Metadata
 			root := info.File.MetaData().Schema.Root()
-			for name := range metadata.Columns {
+			for name, geomColumn := range metadata.Columns {
 				index := root.FieldIndexByName(name)
 				if index < 0 {
 					return fatal("missing geometry column %q", name)
@@ -481,7 +639,14 @@ func GeometryDataType() Rule {
 				if !ok {
 					return fatal("expected primitive column for %q", name)
 				}
-				if field.PhysicalType() != parquet.Types.ByteArray {
+				switch field.PhysicalType() {
+				case parquet.Types.ByteArray:
+				case parquet.Types.FixedLenByteArray:
+					// some writers store WKB (e.g. all points) using a fixed length byte array
+					if geomColumn.Encoding != geo.EncodingWKB {
+						return fatal("unexpected type for column %q with %q encoding, got %s", name, geomColumn.Encoding, field.PhysicalType())
+					}
+				default:
 					return fatal("unexpected type for column %q, got %s", name, field.PhysicalType())
 				}
 			}
@@ -493,6 +658,7 @@ func GeometryDataType() Rule {
 
 func GeometryRepetition() Rule {
 	return &GenericRule[*FileInfo]{
+		id:    "GeometryRepetition",
 		title: "geometry columns must be required or optional, not repeated",
 		validate: func(info *FileInfo) error {
 			metadata := info.Metadata
@@ -519,6 +685,7 @@ func GeometryRepetition() Rule {
 
 func GeometryEncoding() Rule {
 	return &ColumnValueRule[any]{
+		id:    "GeometryEncoding",
 		title: `all geometry values match the "encoding" metadata`,
 		value: func(info *FileInfo, name string, data any) error {
 			geomColumn := info.Metadata.Columns[name]
@@ -527,7 +694,10 @@ func GeometryEncoding() Rule {
 			}
 			_, err := geo.DecodeGeometry(data, geomColumn.Encoding)
 			if err != nil {
-				return fatal("invalid geometry in column %q: %s", name, err)
+				return fatal("invalid geometry in column %q at row %d: %s", name, info.Row, err)
+			}
+			if wkbData, ok := data.([]byte); ok && geo.IsEWKB(wkbData) {
+				return notice("geometry in column %q at row %d is extended WKB (EWKB) with SRID or Z/M flags, which is not standard WKB", name, info.Row)
 			}
 
 			return nil
@@ -537,6 +707,7 @@ func GeometryEncoding() Rule {
 
 func GeometryTypes() Rule {
 	return &ColumnValueRule[orb.Geometry]{
+		id:    "GeometryTypes",
 		title: `all geometry types must be included in the "geometry_types" metadata (if not empty)`,
 		value: func(info *FileInfo, name string, geometry orb.Geometry) error {
 			geomColumn := info.Metadata.Columns[name]
@@ -557,7 +728,7 @@ func GeometryTypes() Rule {
 				}
 			}
 			if !included {
-				return fmt.Errorf("unexpected geometry type %q for column %q", actualType, name)
+				return fmt.Errorf("unexpected geometry type %q for column %q at row %d", actualType, name, info.Row)
 			}
 
 			return nil
@@ -567,6 +738,7 @@ func GeometryTypes() Rule {
 
 func GeometryOrientation() Rule {
 	return &ColumnValueRule[orb.Geometry]{
+		id:    "GeometryOrientation",
 		title: `all polygon geometries must follow the "orientation" metadata (if present)`,
 		value: func(info *FileInfo, name string, geometry orb.Geometry) error {
 			geomColumn := info.Metadata.Columns[name]
@@ -585,20 +757,14 @@ func GeometryOrientation() Rule {
 				return nil
 			}
 
-			expectedExterior := orb.CCW
-			expectedInterior := orb.CW
-
 			for i, ring := range polygon {
-				orientation := ring.Orientation()
-				if i == 0 {
-					if orientation != expectedExterior {
-						return fmt.Errorf("invalid orientation for exterior ring in column %q", name)
-					}
+				if ring.Orientation() == geo.RingOrientation(i) {
 					continue
 				}
-				if orientation != expectedInterior {
-					return fmt.Errorf("invalid orientation for interior ring in column %q", name)
+				if i == 0 {
+					return fmt.Errorf("invalid orientation for exterior ring in column %q at row %d", name, info.Row)
 				}
+				return fmt.Errorf("invalid orientation for interior ring in column %q at row %d", name, info.Row)
 			}
 
 			return nil
@@ -608,6 +774,7 @@ func GeometryOrientation() Rule {
 
 func GeometryBounds() Rule {
 	return &ColumnValueRule[orb.Geometry]{
+		id:    "GeometryBounds",
 		title: `all geometries must fall within the "bbox" metadata (if present)`,
 		value: func(info *FileInfo, name string, geometry orb.Geometry) error {
 			geomColumn := info.Metadata.Columns[name]
@@ -617,7 +784,7 @@ func GeometryBounds() Rule {
 
 			bbox := geomColumn.Bounds
 			length := len(bbox)
-			if length == 0 {
+			if length == 0 || geo.IsEmpty(geometry) {
 				return nil
 			}
 			var x0 float64
@@ -642,28 +809,199 @@ func GeometryBounds() Rule {
 			if x0 <= x1 {
 				// bbox does not cross the antimeridian
 				if bound.Min.X() < x0 {
-					return fmt.Errorf("geometry in column %q extends to %f, west of the bbox", name, bound.Min.X())
+					return fmt.Errorf("geometry in column %q at row %d extends to %f, west of the bbox", name, info.Row, bound.Min.X())
 				}
 				if bound.Max.X() > x1 {
-					return fmt.Errorf("geometry in column %q extends to %f, east of the bbox", name, bound.Max.X())
+					return fmt.Errorf("geometry in column %q at row %d extends to %f, east of the bbox", name, info.Row, bound.Max.X())
 				}
 			} else {
 				// bbox crosses the antimeridian
 				if bound.Max.X() > x1 && bound.Max.X() < x0 {
-					return fmt.Errorf("geometry in column %q extends to %f, outside of the bbox", name, bound.Max.X())
+					return fmt.Errorf("geometry in column %q at row %d extends to %f, outside of the bbox", name, info.Row, bound.Max.X())
 				}
 				if bound.Min.X() < x0 && bound.Min.X() > x1 {
-					return fmt.Errorf("geometry in column %q extends to %f, outside of the bbox", name, bound.Min.X())
+					return fmt.Errorf("geometry in column %q at row %d extends to %f, outside of the bbox", name, info.Row, bound.Min.X())
 				}
 			}
 			if bound.Min.Y() < y0 {
-				return fmt.Errorf("geometry in column %q extends to %f, south of the bbox", name, bound.Min.Y())
+				return fmt.Errorf("geometry in column %q at row %d extends to %f, south of the bbox", name, info.Row, bound.Min.Y())
 			}
 			if bound.Max.Y() > y1 {
-				return fmt.Errorf("geometry in column %q extends to %f, north of the bbox", name, bound.Max.Y())
+				return fmt.Errorf("geometry in column %q at row %d extends to %f, north of the bbox", name, info.Row, bound.Max.Y())
+			}
+
+			return nil
+		},
+	}
+}
+
+// isLonLatCRS returns true if a CRS is known to have longitude and latitude
+// coordinates in degrees (OGC:CRS84 or EPSG:4326).  A missing CRS means
+// OGC:CRS84.
+func isLonLatCRS(crs *geoparquet.Proj) bool {
+	if crs == nil {
+		return true
+	}
+	id := ""
+	if crs.Definition != "" {
+		id = strings.ToUpper(strings.TrimSpace(crs.Definition))
+	} else if crs.Id != nil {
+		switch code := crs.Id.Code.(type) {
+		case string:
+			id = strings.ToUpper(crs.Id.Authority + ":" + code)
+		case float64:
+			id = fmt.Sprintf("%s:%g", strings.ToUpper(crs.Id.Authority), code)
+		}
+	}
+	return id == "OGC:CRS84" || id == "EPSG:4326"
+}
+
+// GeometryGeographicRange passes with a notice if a geometry in a column with a
+// longitude/latitude CRS has coordinates outside of [-180, 180] and [-90, 90].
+// This usually means that projected coordinates are labeled with the wrong
+// CRS.
+func GeometryGeographicRange() Rule {
+	return &ColumnValueRule[orb.Geometry]{
+		id:    "GeometryGeographicRange",
+		title: "geometries with a longitude/latitude CRS (OGC:CRS84 or EPSG:4326) should be within [-180, 180] and [-90, 90]",
+		value: func(info *FileInfo, name string, geometry orb.Geometry) error {
+			geomColumn := info.Metadata.Columns[name]
+			if geomColumn == nil {
+				return fatal("missing geometry column %q", name)
+			}
+			if !isLonLatCRS(geomColumn.CRS) || geo.IsEmpty(geometry) {
+				return nil
 			}
 
+			bound := geometry.Bound()
+			if bound.Min.X() < -180 || bound.Max.X() > 180 || bound.Min.Y() < -90 || bound.Max.Y() > 90 {
+				return notice(
+					"geometry in column %q at row %d has coordinates outside of the longitude/latitude range (bounds [%f, %f, %f, %f]), the CRS may be mislabeled",
+					name, info.Row, bound.Min.X(), bound.Min.Y(), bound.Max.X(), bound.Max.Y(),
+				)
+			}
 			return nil
 		},
 	}
 }
+
+func GeometryValidity() Rule {
+	return &ColumnValueRule[orb.Geometry]{
+		id:    "GeometryValidity",
+		title: "all polygon geometries must have closed, non-degenerate rings that do not self-intersect",
+		value: func(info *FileInfo, name string, geometry orb.Geometry) error {
+			err := checkValidity(geometry)
+			if err == nil {
+				return nil
+			}
+			if errors.Is(err, ErrNotice) {
+				// self-intersection is only a best-effort check, so it is not a failure
+				return notice("geometry in column %q at row %d may be invalid: %s", name, info.Row, err)
+			}
+			return fmt.Errorf("invalid geometry in column %q at row %d: %w", name, info.Row, err)
+		},
+	}
+}
+
+// checkValidity returns an error for an invalid polygon ring or a notice for a
+// self-intersecting ring.  A notice is only returned if no ring is invalid.
+func checkValidity(geometry orb.Geometry) error {
+	var selfIntersection error
+	switch g := geometry.(type) {
+	case orb.Polygon:
+		return checkPolygonValidity(g)
+	case orb.MultiPolygon:
+		for i, polygon := range g {
+			if err := checkPolygonValidity(polygon); err != nil {
+				if !errors.Is(err, ErrNotice) {
+					return fmt.Errorf("polygon %d: %w", i, err)
+				}
+				if selfIntersection == nil {
+					selfIntersection = notice("polygon %d: %s", i, err)
+				}
+			}
+		}
+	case orb.Collection:
+		for i, member := range g {
+			if err := checkValidity(member); err != nil {
+				if !errors.Is(err, ErrNotice) {
+					return fmt.Errorf("geometry %d: %w", i, err)
+				}
+				if selfIntersection == nil {
+					selfIntersection = notice("geometry %d: %s", i, err)
+				}
+			}
+		}
+	}
+	return selfIntersection
+}
+
+func checkPolygonValidity(polygon orb.Polygon) error {
+	var selfIntersection error
+	for i, ring := range polygon {
+		if len(ring) < 4 {
+			return fmt.Errorf("ring %d has %d points, expected at least 4", i, len(ring))
+		}
+		if !ring.Closed() {
+			return fmt.Errorf("ring %d is not closed", i)
+		}
+		if ringArea(ring) == 0 {
+			return fmt.Errorf("ring %d has zero area", i)
+		}
+		if selfIntersection == nil && ringSelfIntersects(ring) {
+			selfIntersection = notice("ring %d self-intersects", i)
+		}
+	}
+	return selfIntersection
+}
+
+func ringArea(ring orb.Ring) float64 {
+	area := 0.0
+	for i := 0; i < len(ring)-1; i += 1 {
+		area += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+	return area / 2
+}
+
+// ringSelfIntersects compares every pair of non-adjacent segments in a closed ring.
+// This is quadratic in the number of points, so it is only a best-effort check.
+func ringSelfIntersects(ring orb.Ring) bool {
+	numSegments := len(ring) - 1
+	for i := 0; i < numSegments; i += 1 {
+		for j := i + 2; j < numSegments; j += 1 {
+			if i == 0 && j == numSegments-1 {
+				// the first and last segments share the closing point
+				continue
+			}
+			if segmentsIntersect(ring[i], ring[i+1], ring[j], ring[j+1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func segmentsIntersect(p1, p2, q1, q2 orb.Point) bool {
+	d1 := cross(q1, q2, p1)
+	d2 := cross(q1, q2, p2)
+	d3 := cross(p1, p2, q1)
+	d4 := cross(p1, p2, q2)
+
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+
+	return (d1 == 0 && onSegment(q1, q2, p1)) ||
+		(d2 == 0 && onSegment(q1, q2, p2)) ||
+		(d3 == 0 && onSegment(p1, p2, q1)) ||
+		(d4 == 0 && onSegment(p1, p2, q2))
+}
+
+func cross(a, b, c orb.Point) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+func onSegment(a, b, p orb.Point) bool {
+	return min(a[0], b[0]) <= p[0] && p[0] <= max(a[0], b[0]) &&
+		min(a[1], b[1]) <= p[1] && p[1] <= max(a[1], b[1])
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 
 	"github.com/apache/arrow/go/v16/arrow/array"
 	"github.com/apache/arrow/go/v16/parquet"
@@ -33,9 +34,16 @@ import (
 type Validator struct {
 	rules        []Rule
 	metadataOnly bool
+	skip         map[string]bool
+	collectAll   bool
+	maxFailures  int
 }
 
 func MetadataOnlyRules() []Rule {
+	return metadataOnlyRules(&Config{})
+}
+
+func metadataOnlyRules(config *Config) []Rule {
 	return []Rule{
 		RequiredGeoKey(),
 		RequiredMetadataType(),
@@ -45,7 +53,7 @@ func MetadataOnlyRules() []Rule {
 		PrimaryColumnInLookup(),
 		RequiredColumnEncoding(),
 		RequiredGeometryTypes(),
-		OptionalCRS(),
+		OptionalCRS(config.ProjJSONSchema, config.NoNetwork),
 		OptionalOrientation(),
 		OptionalEdges(),
 		OptionalBbox(),
@@ -62,19 +70,146 @@ func DataScanningRules() []Rule {
 		GeometryTypes(),
 		GeometryOrientation(),
 		GeometryBounds(),
+		GeometryGeographicRange(),
+	}
+}
+
+// ExtendedRules are data scanning rules that are only run when requested
+// because they are more expensive than the default rules.
+func ExtendedRules() []Rule {
+	return []Rule{
+		GeometryValidity(),
+	}
+}
+
+// Config includes options for creating a Validator.
+type Config struct {
+	// MetadataOnly limits validation to the rules that apply to file metadata and schema.
+	MetadataOnly bool
+
+	// Extended includes the more expensive data scanning rules in validation.
+	Extended bool
+
+	// ProjJSONSchema is a path or URL for the schema used to validate PROJJSON
+	// "crs" metadata.  By default, the schema is determined by the "$schema"
+	// member of the CRS or the latest supported PROJJSON version.
+	ProjJSONSchema string
+
+	// NoNetwork skips PROJJSON schema validation so that no schema is fetched.
+	// Any "crs" metadata is only checked for an object with a "type".
+	NoNetwork bool
+
+	// Skip lists the IDs of rules that should not be run.  Skipped rules are
+	// included in the report as skipped and do not cause validation to fail.
+	Skip []string
+
+	// Only lists the IDs of the rules to run.  Other rules are left out of the
+	// report.  Data scanning rules are not run if MetadataOnly is set, but
+	// extended rules are run when selected.
+	Only []string
+
+	// CollectAll keeps scanning data after a data scanning rule fails.  The
+	// failures for each rule are collected in the check (up to MaxFailures)
+	// instead of stopping at the first one.
+	CollectAll bool
+
+	// MaxFailures limits the number of failures collected for each check with
+	// CollectAll.  If zero, DefaultMaxFailures is used.
+	MaxFailures int
+}
+
+const DefaultMaxFailures = 100
+
+const (
+	CategoryMetadata     = "metadata"
+	CategoryDataScanning = "data-scanning"
+	CategoryExtended     = "extended"
+)
+
+// RuleInfo describes an available rule.
+type RuleInfo struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Category string `json:"category"`
+}
+
+// ListRules returns information about all of the available rules.  Rules in
+// the metadata category are run with MetadataOnly.  Rules in the extended
+// category are data scanning rules that are only run with Extended.
+func ListRules() []*RuleInfo {
+	infos := []*RuleInfo{}
+	categories := []struct {
+		name  string
+		rules []Rule
+	}{
+		{name: CategoryMetadata, rules: MetadataOnlyRules()},
+		{name: CategoryDataScanning, rules: DataScanningRules()},
+		{name: CategoryExtended, rules: ExtendedRules()},
+	}
+	for _, category := range categories {
+		for _, rule := range category.rules {
+			infos = append(infos, &RuleInfo{ID: rule.ID(), Title: rule.Title(), Category: category.name})
+		}
+	}
+	return infos
+}
+
+// ValidateRuleIDs returns an error if any of the provided IDs does not match a
+// rule.
+func ValidateRuleIDs(ids []string) error {
+	known := map[string]bool{}
+	for _, info := range ListRules() {
+		known[info.ID] = true
+	}
+	for _, id := range ids {
+		if !known[id] {
+			return fmt.Errorf("unknown rule %q", id)
+		}
 	}
+	return nil
 }
 
 // New creates a new Validator.
 func New(metadataOnly bool) *Validator {
-	rules := MetadataOnlyRules()
-	if !metadataOnly {
+	return NewFromConfig(&Config{MetadataOnly: metadataOnly})
+}
+
+// NewFromConfig creates a new Validator with the provided config.
+func NewFromConfig(config *Config) *Validator {
+	rules := metadataOnlyRules(config)
+	if !config.MetadataOnly {
 		rules = append(rules, DataScanningRules()...)
+		if config.Extended || len(config.Only) > 0 {
+			rules = append(rules, ExtendedRules()...)
+		}
+	}
+
+	if len(config.Only) > 0 {
+		selected := []Rule{}
+		for _, rule := range rules {
+			if slices.Contains(config.Only, rule.ID()) {
+				selected = append(selected, rule)
+			}
+		}
+		rules = selected
+	}
+
+	skip := map[string]bool{}
+	for _, id := range config.Skip {
+		skip[id] = true
+	}
+
+	maxFailures := config.MaxFailures
+	if maxFailures <= 0 {
+		maxFailures = DefaultMaxFailures
 	}
 
 	v := &Validator{
 		rules:        rules,
-		metadataOnly: metadataOnly,
+		metadataOnly: config.MetadataOnly,
+		skip:         skip,
+		collectAll:   config.CollectAll,
+		maxFailures:  maxFailures,
 	}
 
 	return v
@@ -86,10 +221,26 @@ type Report struct {
 }
 
 type Check struct {
+	ID      string `json:"id"`
 	Title   string `json:"title"`
 	Run     bool   `json:"run"`
 	Passed  bool   `json:"passed"`
+	Skipped bool   `json:"skipped,omitempty"`
 	Message string `json:"message,omitempty"`
+
+	// Failures lists the failing rows when validating with CollectAll.  At most
+	// MaxFailures are included.  NumFailures is the total number found.
+	Failures    []string `json:"failures,omitempty"`
+	NumFailures int      `json:"numFailures,omitempty"`
+}
+
+// addFailure records a failure for a data scanning check.  The error message
+// includes the row index.
+func (v *Validator) addFailure(check *Check, err error) {
+	check.NumFailures += 1
+	if len(check.Failures) < v.maxFailures {
+		check.Failures = append(check.Failures, err.Error())
+	}
 }
 
 // Validate opens and validates a GeoParquet file.
@@ -108,7 +259,9 @@ func (v *Validator) Report(ctx context.Context, file *file.Reader) (*Report, err
 	checks := make([]*Check, len(v.rules))
 	for i, rule := range v.rules {
 		checks[i] = &Check{
-			Title: rule.Title(),
+			ID:      rule.ID(),
+			Title:   rule.Title(),
+			Skipped: v.skip[rule.ID()],
 		}
 	}
 
@@ -119,15 +272,18 @@ func (v *Validator) Report(ctx context.Context, file *file.Reader) (*Report, err
 		return report, nil
 	}
 
-	// run all metadata rules
+	// run all metadata rules (the value is decoded once and shared by the map
+	// and struct based rules)
 	metadataValue, metadataErr := geoparquet.GetMetadataValue(file.MetaData().KeyValueMetadata())
 	if metadataErr != nil {
-		return nil, metadataErr
+		notRun(checks, metadataErr)
+		return report, nil
 	}
 
 	metadataMap := MetadataMap{}
 	if err := json.Unmarshal([]byte(metadataValue), &metadataMap); err != nil {
-		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+		notRun(checks, fmt.Errorf("failed to decode metadata: %w", err))
+		return report, nil
 	}
 
 	if err := run(v, checks, metadataMap); err != nil {
@@ -138,14 +294,17 @@ func (v *Validator) Report(ctx context.Context, file *file.Reader) (*Report, err
 	columnMetadataMap := ColumnMetdataMap{}
 	columnMetadataAny, ok := metadataMap["columns"].(map[string]any)
 	if !ok {
-		return nil, errors.New("columns metadata is not an object")
+		notRun(checks, errors.New("columns metadata is not an object"))
+		return report, nil
 	}
 
 	for k, v := range columnMetadataAny {
 		col, ok := v.(map[string]any)
 		if !ok {
-			return nil, errors.New("column metadata is not an object")
+			notRun(checks, errors.New("column metadata is not an object"))
+			return report, nil
 		}
+		geoparquet.CanonicalizeColumnMetadata(col)
 		columnMetadataMap[k] = col
 	}
 
@@ -153,10 +312,12 @@ func (v *Validator) Report(ctx context.Context, file *file.Reader) (*Report, err
 		return report, nil
 	}
 
-	// run all rules that need the file and parsed metadata
-	metadata, err := geoparquet.GetMetadata(file.MetaData().KeyValueMetadata())
+	// run all rules that need the file and parsed metadata (built from the
+	// decoded map instead of decoding the value again)
+	metadata, err := geoparquet.MetadataFromMap(metadataMap)
 	if err != nil {
-		return nil, err
+		notRun(checks, err)
+		return report, nil
 	}
 
 	info := &FileInfo{Metadata: metadata, File: file}
@@ -170,8 +331,9 @@ func (v *Validator) Report(ctx context.Context, file *file.Reader) (*Report, err
 
 	// run all the data scanning rules
 	recordReader, rrErr := geoparquet.NewRecordReader(&geoparquet.ReaderConfig{
-		File:    file,
-		Context: ctx,
+		File:     file,
+		Context:  ctx,
+		Metadata: metadata,
 	})
 	if rrErr != nil {
 		return nil, rrErr
@@ -182,7 +344,7 @@ func (v *Validator) Report(ctx context.Context, file *file.Reader) (*Report, err
 	encodedGeometryChecks := []*Check{}
 	for i, r := range v.rules {
 		rule, ok := r.(*ColumnValueRule[any])
-		if ok {
+		if ok && !v.skip[r.ID()] {
 			rule.Init(info)
 			encodedGeometryRules = append(encodedGeometryRules, rule)
 			encodedGeometryChecks = append(encodedGeometryChecks, checks[i])
@@ -193,13 +355,18 @@ func (v *Validator) Report(ctx context.Context, file *file.Reader) (*Report, err
 	decodedGeometryChecks := []*Check{}
 	for i, r := range v.rules {
 		rule, ok := r.(*ColumnValueRule[orb.Geometry])
-		if ok {
+		if ok && !v.skip[r.ID()] {
 			rule.Init(info)
 			decodedGeometryRules = append(decodedGeometryRules, rule)
 			decodedGeometryChecks = append(decodedGeometryChecks, checks[i])
 		}
 	}
 
+	if len(encodedGeometryRules) == 0 && len(decodedGeometryRules) == 0 {
+		return report, nil
+	}
+
+	var rowOffset int64
 	for {
 		record, recordErr := recordReader.Read()
 		if recordErr == io.EOF {
@@ -223,15 +390,28 @@ func (v *Validator) Report(ctx context.Context, file *file.Reader) (*Report, err
 			}
 			values := arr.Field(colNum)
 			for rowNum := 0; rowNum < arr.Len(); rowNum += 1 {
+				info.Row = rowOffset + int64(rowNum)
 				value := values.GetOneForMarshal(rowNum)
+				invalid := false
 				for i, rule := range encodedGeometryRules {
 					check := encodedGeometryChecks[i]
+					if v.collectAll {
+						if err := rule.Collect(field.Name, value); err != nil && !errors.Is(err, ErrNotice) {
+							v.addFailure(check, err)
+							invalid = invalid || errors.Is(err, ErrFatal)
+						}
+						continue
+					}
 					if err := rule.Value(field.Name, value); errors.Is(err, ErrFatal) {
 						check.Message = err.Error()
 						check.Run = true
 						return report, nil
 					}
 				}
+				if invalid {
+					// the geometry cannot be decoded for the remaining rules
+					continue
+				}
 
 				geometry, err := geo.DecodeGeometry(value, geomColumn.Encoding)
 				if err != nil {
@@ -242,6 +422,12 @@ func (v *Validator) Report(ctx context.Context, file *file.Reader) (*Report, err
 				}
 				for i, rule := range decodedGeometryRules {
 					check := decodedGeometryChecks[i]
+					if v.collectAll {
+						if err := rule.Collect(field.Name, geometry.Geometry()); err != nil && !errors.Is(err, ErrNotice) {
+							v.addFailure(check, err)
+						}
+						continue
+					}
 					if err := rule.Value(field.Name, geometry.Geometry()); errors.Is(err, ErrFatal) {
 						check.Message = err.Error()
 						check.Run = true
@@ -250,7 +436,7 @@ func (v *Validator) Report(ctx context.Context, file *file.Reader) (*Report, err
 				}
 			}
 		}
-
+		rowOffset += int64(arr.Len())
 	}
 
 	for i, rule := range encodedGeometryRules {
@@ -258,7 +444,11 @@ func (v *Validator) Report(ctx context.Context, file *file.Reader) (*Report, err
 		check.Run = true
 		if err := rule.Validate(); err != nil {
 			check.Message = err.Error()
-			if errors.Is(err, ErrFatal) {
+			if errors.Is(err, ErrNotice) {
+				check.Passed = true
+				continue
+			}
+			if errors.Is(err, ErrFatal) && !v.collectAll {
 				return report, nil
 			}
 			continue
@@ -271,7 +461,11 @@ func (v *Validator) Report(ctx context.Context, file *file.Reader) (*Report, err
 		check.Run = true
 		if err := rule.Validate(); err != nil {
 			check.Message = err.Error()
-			if errors.Is(err, ErrFatal) {
+			if errors.Is(err, ErrNotice) {
+				check.Passed = true
+				continue
+			}
+			if errors.Is(err, ErrFatal) && !v.collectAll {
 				return report, nil
 			}
 			continue
@@ -282,17 +476,32 @@ func (v *Validator) Report(ctx context.Context, file *file.Reader) (*Report, err
 	return report, nil
 }
 
+// notRun adds a message to the checks that have not been run because the
+// metadata they need is missing or invalid.  This happens when the rule that
+// would have failed for the metadata was skipped or not selected.
+func notRun(checks []*Check, err error) {
+	for _, check := range checks {
+		if !check.Run && !check.Skipped {
+			check.Message = fmt.Sprintf("not run: %s", err)
+		}
+	}
+}
+
 func run[T RuleData](v *Validator, checks []*Check, data T) error {
 	for i, r := range v.rules {
 		check := checks[i]
 		rule, ok := r.(*GenericRule[T])
-		if !ok {
+		if !ok || v.skip[r.ID()] {
 			continue
 		}
 		rule.Init(data)
 		check.Run = true
 		if err := rule.Validate(); err != nil {
 			check.Message = err.Error()
+			if errors.Is(err, ErrNotice) {
+				check.Passed = true
+				continue
+			}
 			if errors.Is(err, ErrFatal) {
 				return err
 			}
@@ -26,10 +26,13 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/apache/arrow/go/v16/arrow"
+	"github.com/apache/arrow/go/v16/arrow/array"
+	"github.com/apache/arrow/go/v16/arrow/memory"
 	"github.com/apache/arrow/go/v16/parquet"
 	"github.com/apache/arrow/go/v16/parquet/file"
-	"github.com/apache/arrow/go/v16/parquet/pqarrow"
 	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/ewkb"
 	"github.com/paulmach/orb/encoding/wkb"
 	"github.com/planetlabs/gpq/internal/geojson"
 	"github.com/planetlabs/gpq/internal/geoparquet"
@@ -86,9 +89,10 @@ func (s *Suite) readSpec(name string) *Spec {
 
 func (s *Suite) copyWithMetadata(input parquet.ReaderAtSeeker, output io.Writer, metadata string) {
 	config := &pqutil.TransformConfig{
-		Reader: input,
-		Writer: output,
-		BeforeClose: func(fileReader *file.Reader, fileWriter *pqarrow.FileWriter) error {
+		Reader:           input,
+		Writer:           output,
+		CopyColumnChunks: true,
+		BeforeClose: func(fileReader *file.Reader, fileWriter pqutil.MetadataWriter) error {
 			return fileWriter.AppendKeyValueMetadata(geoparquet.MetadataKey, metadata)
 		},
 	}
@@ -163,6 +167,66 @@ func (s *Suite) TestValidCases() {
 	}
 }
 
+func (s *Suite) TestHexWKB() {
+	filePath := "../testdata/cases/example-hex-wkb.parquet"
+	data, err := os.ReadFile(filePath)
+	s.Require().NoError(err)
+
+	report, err := validator.New(false).Validate(context.Background(), bytes.NewReader(data), filePath)
+	s.Require().NoError(err)
+	s.assertExpectedReport("all-pass", report)
+}
+
+func (s *Suite) TestDuplicateGeoMetadata() {
+	filePath := "../testdata/cases/example-duplicate-geo.parquet"
+	data, err := os.ReadFile(filePath)
+	s.Require().NoError(err)
+
+	report, err := validator.New(false).Validate(context.Background(), bytes.NewReader(data), filePath)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(report.Checks)
+
+	check := report.Checks[0]
+	s.False(check.Passed)
+	s.True(check.Run)
+	s.Equal(`found 2 "geo" metadata keys, expected exactly one (a tool may have appended new metadata without replacing the existing key); rewrite the file with a single "geo" key`, check.Message)
+	for _, c := range report.Checks[1:] {
+		s.False(c.Run, c.Title)
+	}
+}
+
+func (s *Suite) TestAllNullGeometryRoundTrip() {
+	geojsonFile, err := os.Open("../geojson/testdata/all-null-geom.geojson")
+	s.Require().NoError(err)
+	defer geojsonFile.Close()
+
+	initialOutput := &bytes.Buffer{}
+	s.Require().NoError(geojson.ToParquet(geojsonFile, initialOutput, nil))
+
+	// legacy metadata without any geometry types
+	legacyOutput := &bytes.Buffer{}
+	s.copyWithMetadata(bytes.NewReader(initialOutput.Bytes()), legacyOutput, `{"version": "0.4.0", "primary_column": "geometry", "columns": {"geometry": {"encoding": "WKB"}}}`)
+
+	for name, data := range map[string][]byte{"current": initialOutput.Bytes(), "legacy": legacyOutput.Bytes()} {
+		s.Run(name, func() {
+			output := &bytes.Buffer{}
+			s.Require().NoError(geoparquet.FromParquet(bytes.NewReader(data), output, &geoparquet.ConvertOptions{Version: geoparquet.Version}))
+
+			fileReader, err := file.NewParquetReader(bytes.NewReader(output.Bytes()))
+			s.Require().NoError(err)
+			defer fileReader.Close()
+
+			value, err := geoparquet.GetMetadataValue(fileReader.MetaData().KeyValueMetadata())
+			s.Require().NoError(err)
+			s.Contains(value, `"geometry_types":[]`)
+
+			report, err := validator.New(false).Validate(context.Background(), bytes.NewReader(output.Bytes()), "all-null.parquet")
+			s.Require().NoError(err)
+			s.assertExpectedReport("all-pass", report)
+		})
+	}
+}
+
 func (s *Suite) TestConvertedWKT() {
 	type Row struct {
 		Name     string `parquet:"name=name, logical=String" json:"name"`
@@ -280,6 +344,52 @@ func (s *Suite) TestConvertedWKB() {
 	s.assertExpectedReport("all-pass-meta", metaReport)
 }
 
+func (s *Suite) TestConvertedEWKB() {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry []byte `parquet:"name=geometry" json:"geometry"`
+	}
+
+	toEWKB := func(geometry orb.Geometry) []byte {
+		data, err := ewkb.Marshal(geometry, 4326)
+		s.Require().NoError(err)
+		return data
+	}
+
+	rows := []*Row{
+		{
+			Name:     "test-point-1",
+			Geometry: toEWKB(orb.Point{1, 2}),
+		},
+		{
+			Name:     "test-point-2",
+			Geometry: toEWKB(orb.Point{3, 4}),
+		},
+	}
+
+	input := test.ParquetFromStructs(s.T(), rows)
+
+	geoparquetBytes := &bytes.Buffer{}
+	s.Require().NoError(geoparquet.FromParquet(input, geoparquetBytes, nil))
+
+	filePath := "test-ewkb.parquet"
+	ctx := context.Background()
+	validatorAll := validator.New(false)
+
+	allReport, allErr := validatorAll.Validate(ctx, bytes.NewReader(geoparquetBytes.Bytes()), filePath)
+	s.Require().NoError(allErr)
+
+	// the EWKB values are decoded, but they are noted as not standard WKB
+	for _, check := range allReport.Checks {
+		s.True(check.Passed, check.ID)
+		if check.ID == "GeometryEncoding" {
+			s.Equal(`geometry in column "geometry" at row 0 is extended WKB (EWKB) with SRID or Z/M flags, which is not standard WKB`, check.Message)
+		} else {
+			s.Empty(check.Message, check.ID)
+		}
+	}
+}
+
 func (s *Suite) TestWKBWithNoData() {
 	type Row struct {
 		Name     string `parquet:"name=name, logical=String" json:"name"`
@@ -352,6 +462,47 @@ func (s *Suite) TestWKBWithEmptyPoint() {
 	s.assertExpectedReport("all-pass-meta", metaReport)
 }
 
+func (s *Suite) TestFixedLenByteArrayWKB() {
+	points := []orb.Point{{1, 2}, {3, 4}}
+	pointSize := len(toWKB(s.T(), points[0]))
+
+	arrowSchema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "geometry", Type: &arrow.FixedSizeBinaryType{ByteWidth: pointSize}, Nullable: true},
+	}, nil)
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, arrowSchema)
+	defer builder.Release()
+	for i, point := range points {
+		builder.Field(0).(*array.StringBuilder).Append(fmt.Sprintf("test-point-%d", i+1))
+		builder.Field(1).(*array.FixedSizeBinaryBuilder).Append(toWKB(s.T(), point))
+	}
+	record := builder.NewRecord()
+	defer record.Release()
+
+	output := &bytes.Buffer{}
+	writer, err := geoparquet.NewRecordWriter(&geoparquet.WriterConfig{
+		Writer:      output,
+		ArrowSchema: arrowSchema,
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(writer.Write(record))
+	s.Require().NoError(writer.Close())
+
+	filePath := "test-fixed-len-wkb.parquet"
+	ctx := context.Background()
+	validatorAll := validator.New(false)
+	validatorMeta := validator.New(true)
+
+	allReport, allErr := validatorAll.Validate(ctx, bytes.NewReader(output.Bytes()), filePath)
+	s.Require().NoError(allErr)
+	s.assertExpectedReport("all-pass", allReport)
+
+	metaReport, metaErr := validatorMeta.Validate(ctx, bytes.NewReader(output.Bytes()), filePath)
+	s.Require().NoError(metaErr)
+	s.assertExpectedReport("all-pass-meta", metaReport)
+}
+
 func (s *Suite) TestConvertedAltPrimaryColumnWKB() {
 	type Row struct {
 		Name        string `parquet:"name=name, logical=String" json:"name"`
@@ -406,8 +557,11 @@ func (s *Suite) TestReport() {
 		"bad-encoding",
 		"missing-geometry-types",
 		"bad-geometry-types",
+		"legacy-geometry-type",
 		"bad-crs",
 		"bad-crs-type",
+		"crs-string-authority",
+		"crs-string-wkt",
 		"bad-orientation",
 		"bad-edges",
 		"bad-bbox-type",
@@ -420,15 +574,23 @@ func (s *Suite) TestReport() {
 		"geometry-outside-bbox",
 		"geometry-inside-antimeridian-spanning-bbox",
 		"geometry-outside-antimeridian-spanning-bbox",
+		"geometry-outside-geographic-range",
 		"with-empty-geometry",
 		"with-null-geometry",
+		"geometry-valid-extended",
+		"geometry-unclosed-ring-extended",
+		"geometry-zero-area-extended",
+		"geometry-self-intersecting-extended",
+		"geometry-self-intersecting-then-unclosed-extended",
 	}
 
 	ctx := context.Background()
 	for _, c := range cases {
 		s.Run(c, func() {
-			metadataOnly := strings.HasSuffix(c, "-meta")
-			v := validator.New(metadataOnly)
+			v := validator.NewFromConfig(&validator.Config{
+				MetadataOnly: strings.HasSuffix(c, "-meta"),
+				Extended:     strings.HasSuffix(c, "-extended"),
+			})
 
 			report, err := v.Report(ctx, s.generateGeoParquet(c))
 			s.Require().NoError(err)
@@ -438,6 +600,398 @@ func (s *Suite) TestReport() {
 	}
 }
 
+func (s *Suite) TestProjJSONSchemaOverride() {
+	jsonschema.Loaders["https"] = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("unexpected request for %s", url)
+	}
+	defer func() {
+		jsonschema.Loaders["https"] = loadSchema
+	}()
+
+	schemaPath := "../testdata/schema/proj.org/schemas/v0.6/projjson.schema.json"
+	v := validator.NewFromConfig(&validator.Config{
+		MetadataOnly:   true,
+		ProjJSONSchema: schemaPath,
+	})
+
+	report, err := v.Report(context.Background(), s.generateGeoParquet("bad-crs"))
+	s.Require().NoError(err)
+
+	var crsCheck *validator.Check
+	for _, check := range report.Checks {
+		if strings.Contains(check.Title, `"crs"`) {
+			crsCheck = check
+		}
+	}
+	s.Require().NotNil(crsCheck)
+	s.True(crsCheck.Run)
+	s.False(crsCheck.Passed)
+	s.Equal("validation failed against "+schemaPath+": input is invalid: missing properties: 'source_crs', 'target_crs', 'transformation'", crsCheck.Message)
+}
+
+func (s *Suite) TestNoNetworkCRS() {
+	jsonschema.Loaders["https"] = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("unexpected request for %s", url)
+	}
+	defer func() {
+		jsonschema.Loaders["https"] = loadSchema
+	}()
+
+	v := validator.NewFromConfig(&validator.Config{
+		MetadataOnly: true,
+		NoNetwork:    true,
+	})
+
+	report, err := v.Report(context.Background(), s.generateGeoParquet("bad-crs"))
+	s.Require().NoError(err)
+
+	var crsCheck *validator.Check
+	for _, check := range report.Checks {
+		if strings.Contains(check.Title, `"crs"`) {
+			crsCheck = check
+		}
+	}
+	s.Require().NotNil(crsCheck)
+	s.True(crsCheck.Run)
+	s.True(crsCheck.Passed)
+	s.Equal("PROJJSON schema validation skipped (no network)", crsCheck.Message)
+}
+
+func (s *Suite) TestSkipRules() {
+	v := validator.NewFromConfig(&validator.Config{
+		Skip: []string{"GeometryBounds", "OptionalCRS"},
+	})
+
+	report, err := v.Report(context.Background(), s.generateGeoParquet("geometry-outside-bbox"))
+	s.Require().NoError(err)
+
+	skipped := []string{}
+	for _, check := range report.Checks {
+		if check.Skipped {
+			skipped = append(skipped, check.ID)
+			s.False(check.Run)
+			s.False(check.Passed)
+			continue
+		}
+		s.True(check.Run, check.ID)
+		s.True(check.Passed, check.ID)
+	}
+	s.Equal([]string{"OptionalCRS", "GeometryBounds"}, skipped)
+}
+
+func (s *Suite) TestFailFast() {
+	v := validator.NewFromConfig(&validator.Config{})
+
+	report, err := v.Report(context.Background(), s.generateGeoParquet("multiple-failures"))
+	s.Require().NoError(err)
+
+	checks := map[string]*validator.Check{}
+	for _, check := range report.Checks {
+		checks[check.ID] = check
+	}
+
+	s.Require().Contains(checks, "GeometryTypes")
+	s.False(checks["GeometryTypes"].Passed)
+	s.Equal(`unexpected geometry type "Point" for column "geometry" at row 0`, checks["GeometryTypes"].Message)
+	s.Empty(checks["GeometryTypes"].Failures)
+}
+
+func (s *Suite) TestReportCanceled() {
+	v := validator.NewFromConfig(&validator.Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// the metadata rules do not read data, so the scan is the first to stop
+	_, err := v.Report(ctx, s.generateGeoParquet("multiple-failures"))
+	s.ErrorIs(err, context.Canceled)
+	s.ErrorContains(err, "failed to read record")
+}
+
+func (s *Suite) TestCollectAll() {
+	v := validator.NewFromConfig(&validator.Config{CollectAll: true})
+
+	report, err := v.Report(context.Background(), s.generateGeoParquet("multiple-failures"))
+	s.Require().NoError(err)
+
+	checks := map[string]*validator.Check{}
+	for _, check := range report.Checks {
+		s.True(check.Run, check.ID)
+		checks[check.ID] = check
+	}
+
+	types := checks["GeometryTypes"]
+	s.False(types.Passed)
+	s.Contains(types.Message, `unexpected geometry type "Point"`)
+	s.Equal(2, types.NumFailures)
+	s.Require().Len(types.Failures, 2)
+	s.Contains(types.Failures[0], "at row 0")
+	s.Contains(types.Failures[1], "at row 2")
+
+	bounds := checks["GeometryBounds"]
+	s.False(bounds.Passed)
+	s.Equal(2, bounds.NumFailures)
+	s.Require().Len(bounds.Failures, 2)
+	s.Equal(`geometry in column "geometry" at row 0 extends to 20.000000, east of the bbox`, bounds.Failures[0])
+	s.Equal(`geometry in column "geometry" at row 2 extends to 30.000000, north of the bbox`, bounds.Failures[1])
+
+	s.True(checks["GeometryEncoding"].Passed)
+	s.Empty(checks["GeometryEncoding"].Failures)
+}
+
+func (s *Suite) TestCollectAllNoticeThenFailure() {
+	v := validator.NewFromConfig(&validator.Config{CollectAll: true, Extended: true})
+
+	report, err := v.Report(context.Background(), s.generateGeoParquet("geometry-self-intersecting-then-unclosed-extended"))
+	s.Require().NoError(err)
+
+	for _, check := range report.Checks {
+		if check.ID != "GeometryValidity" {
+			continue
+		}
+		s.True(check.Run)
+		s.False(check.Passed)
+		s.Contains(check.Message, "invalid geometry in column \"geometry\" at row 1")
+		s.Equal(1, check.NumFailures)
+		s.Require().Len(check.Failures, 1)
+		s.Contains(check.Failures[0], "at row 1")
+		return
+	}
+	s.Fail("missing GeometryValidity check")
+}
+
+func (s *Suite) TestCollectAllMaxFailures() {
+	v := validator.NewFromConfig(&validator.Config{CollectAll: true, MaxFailures: 1})
+
+	report, err := v.Report(context.Background(), s.generateGeoParquet("multiple-failures"))
+	s.Require().NoError(err)
+
+	for _, check := range report.Checks {
+		if check.ID != "GeometryTypes" {
+			continue
+		}
+		s.Equal(2, check.NumFailures)
+		s.Len(check.Failures, 1)
+		return
+	}
+	s.Fail("missing GeometryTypes check")
+}
+
+func (s *Suite) TestOnlyRules() {
+	v := validator.NewFromConfig(&validator.Config{
+		Only: []string{"GeometryOrientation"},
+	})
+
+	report, err := v.Report(context.Background(), s.generateGeoParquet("geometry-incorrectly-oriented"))
+	s.Require().NoError(err)
+
+	s.Require().Len(report.Checks, 1)
+	check := report.Checks[0]
+	s.Equal("GeometryOrientation", check.ID)
+	s.True(check.Run)
+	s.False(check.Passed)
+}
+
+func (s *Suite) TestOnlyRulesMissingMetadata() {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry []byte `parquet:"name=geometry" json:"geometry"`
+	}
+
+	input := test.ParquetFromStructs(s.T(), []*Row{{Name: "test-point", Geometry: toWKB(s.T(), orb.Point{1, 2})}})
+
+	v := validator.NewFromConfig(&validator.Config{
+		Only: []string{"GeometryBounds"},
+	})
+
+	report, err := v.Validate(context.Background(), input, "plain.parquet")
+	s.Require().NoError(err)
+
+	s.Require().Len(report.Checks, 1)
+	check := report.Checks[0]
+	s.Equal("GeometryBounds", check.ID)
+	s.False(check.Run)
+	s.False(check.Passed)
+	s.Equal("not run: missing geo metadata key", check.Message)
+}
+
+func (s *Suite) TestSkipRulesInvalidColumns() {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry []byte `parquet:"name=geometry" json:"geometry"`
+	}
+
+	output := &bytes.Buffer{}
+	metadata := `{"version": "1.0.0", "primary_column": "geometry", "columns": []}`
+	s.copyWithMetadata(test.ParquetFromStructs(s.T(), []*Row{{Name: "test-point", Geometry: toWKB(s.T(), orb.Point{1, 2})}}), output, metadata)
+
+	v := validator.NewFromConfig(&validator.Config{
+		Skip: []string{"RequiredColumns"},
+	})
+
+	report, err := v.Validate(context.Background(), bytes.NewReader(output.Bytes()), "invalid-columns.parquet")
+	s.Require().NoError(err)
+
+	notRun := 0
+	for _, check := range report.Checks {
+		if check.Skipped || check.Run {
+			continue
+		}
+		notRun += 1
+		s.Equal("not run: columns metadata is not an object", check.Message, check.ID)
+	}
+	s.Positive(notRun)
+}
+
+func (s *Suite) TestOnlyExtendedRules() {
+	v := validator.NewFromConfig(&validator.Config{
+		Only: []string{"RequiredVersion", "GeometryValidity"},
+	})
+
+	report, err := v.Report(context.Background(), s.generateGeoParquet("geometry-valid-extended"))
+	s.Require().NoError(err)
+
+	s.Require().Len(report.Checks, 2)
+	s.Equal("RequiredVersion", report.Checks[0].ID)
+	s.Equal("GeometryValidity", report.Checks[1].ID)
+	for _, check := range report.Checks {
+		s.True(check.Run, check.ID)
+		s.True(check.Passed, check.ID)
+	}
+}
+
+func (s *Suite) TestOnlyRulesMetadataOnly() {
+	v := validator.NewFromConfig(&validator.Config{
+		MetadataOnly: true,
+		Only:         []string{"RequiredVersion", "GeometryBounds"},
+	})
+
+	report, err := v.Report(context.Background(), s.generateGeoParquet("geometry-outside-bbox"))
+	s.Require().NoError(err)
+
+	s.Require().Len(report.Checks, 1)
+	s.Equal("RequiredVersion", report.Checks[0].ID)
+	s.True(report.Checks[0].Passed)
+}
+
+func (s *Suite) TestListRules() {
+	rules := validator.ListRules()
+	s.Len(rules, len(validator.MetadataOnlyRules())+len(validator.DataScanningRules())+len(validator.ExtendedRules()))
+
+	categories := map[string]string{}
+	for _, rule := range rules {
+		s.NotEmpty(rule.ID)
+		s.NotEmpty(rule.Title)
+		s.NotContains(categories, rule.ID, "duplicate rule id")
+		categories[rule.ID] = rule.Category
+	}
+	s.Equal(validator.CategoryMetadata, categories["RequiredGeoKey"])
+	s.Equal(validator.CategoryDataScanning, categories["GeometryBounds"])
+	s.Equal(validator.CategoryExtended, categories["GeometryValidity"])
+}
+
+func (s *Suite) TestValidateRuleIDs() {
+	s.NoError(validator.ValidateRuleIDs([]string{"GeometryBounds", "GeometryValidity"}))
+	s.EqualError(validator.ValidateRuleIDs([]string{"GeometryBounds", "NotARule"}), `unknown rule "NotARule"`)
+}
+
+func (s *Suite) TestNoNetworkCRSMissingType() {
+	type Row struct {
+		Name     string `parquet:"name=name, logical=String" json:"name"`
+		Geometry []byte `parquet:"name=geometry" json:"geometry"`
+	}
+
+	rows := []*Row{
+		{
+			Name:     "test-point-1",
+			Geometry: toWKB(s.T(), orb.Point{1, 2}),
+		},
+	}
+
+	metadata := `{
+		"version": "1.0.0",
+		"primary_column": "geometry",
+		"columns": {
+			"geometry": {"encoding": "WKB", "geometry_types": [], "crs": {"name": "WGS 84"}}
+		}
+	}`
+
+	output := &bytes.Buffer{}
+	s.copyWithMetadata(test.ParquetFromStructs(s.T(), rows), output, metadata)
+
+	v := validator.NewFromConfig(&validator.Config{
+		MetadataOnly: true,
+		NoNetwork:    true,
+	})
+
+	report, err := v.Validate(context.Background(), bytes.NewReader(output.Bytes()), "test-crs.parquet")
+	s.Require().NoError(err)
+
+	var crsCheck *validator.Check
+	for _, check := range report.Checks {
+		if strings.Contains(check.Title, `"crs"`) {
+			crsCheck = check
+		}
+	}
+	s.Require().NotNil(crsCheck)
+	s.True(crsCheck.Run)
+	s.False(crsCheck.Passed)
+	s.Equal(`expected "crs" for column "geometry" to have a "type" string, got null`, crsCheck.Message)
+}
+
+func (s *Suite) TestProjJSONSchemaCompiledOnce() {
+	validator.ResetProjJSONSchemas()
+	defer validator.ResetProjJSONSchemas()
+
+	schemaURL := "https://proj.org/schemas/v0.5/projjson.schema.json"
+	loads := 0
+	jsonschema.Loaders["https"] = func(url string) (io.ReadCloser, error) {
+		if url == schemaURL {
+			loads += 1
+		}
+		return loadSchema(url)
+	}
+	defer func() {
+		jsonschema.Loaders["https"] = loadSchema
+	}()
+
+	type Row struct {
+		Name      string `parquet:"name=name, logical=String" json:"name"`
+		Geometry  []byte `parquet:"name=geometry" json:"geometry"`
+		Geometry2 []byte `parquet:"name=geometry2" json:"geometry2"`
+	}
+
+	rows := []*Row{
+		{
+			Name:      "test-point-1",
+			Geometry:  toWKB(s.T(), orb.Point{1, 2}),
+			Geometry2: toWKB(s.T(), orb.Point{3, 4}),
+		},
+	}
+
+	crs := `{"$schema": "` + schemaURL + `", "type": "GeographicCRS", "name": "WGS 84 (CRS84)", "datum": {"type": "GeodeticReferenceFrame", "name": "World Geodetic System 1984", "ellipsoid": {"name": "WGS 84", "semi_major_axis": 6378137, "inverse_flattening": 298.257223563}}, "coordinate_system": {"subtype": "ellipsoidal", "axis": [{"name": "Geodetic longitude", "abbreviation": "Lon", "direction": "east", "unit": "degree"}, {"name": "Geodetic latitude", "abbreviation": "Lat", "direction": "north", "unit": "degree"}]}}`
+	metadata := `{
+		"version": "1.0.0",
+		"primary_column": "geometry",
+		"columns": {
+			"geometry": {"encoding": "WKB", "geometry_types": [], "crs": ` + crs + `},
+			"geometry2": {"encoding": "WKB", "geometry_types": [], "crs": ` + crs + `}
+		}
+	}`
+
+	output := &bytes.Buffer{}
+	s.copyWithMetadata(test.ParquetFromStructs(s.T(), rows), output, metadata)
+
+	v := validator.New(true)
+	for i := 0; i < 2; i += 1 {
+		report, err := v.Validate(context.Background(), bytes.NewReader(output.Bytes()), "test-crs.parquet")
+		s.Require().NoError(err)
+		s.assertExpectedReport("all-pass-meta", report)
+	}
+
+	s.Equal(1, loads)
+}
+
 func TestSuite(t *testing.T) {
 	suite.Run(t, &Suite{})
 }
@@ -23,6 +23,7 @@ import {
  * @typedef {object} GPQ
  * @property {function(string):GeoParquetOutput} toParquet Transform GeoJSON to GeoParquet.
  * @property {function(string):GeoJSONOutput} fromParquet Transform GeoParquet to GeoJSON.
+ * @property {function(Uint8Array):string} metadata Get the geo key metadata value from GeoParquet.
  */
 
 /**